@@ -0,0 +1,1348 @@
+//go:build !nogui
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/app"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/driver/desktop"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/devmarvs/frago/internal/daemon"
+	"github.com/devmarvs/frago/internal/ipc"
+	"github.com/devmarvs/frago/internal/runner"
+	"github.com/devmarvs/frago/internal/service"
+	"github.com/devmarvs/frago/internal/store"
+	"github.com/devmarvs/frago/internal/updater"
+)
+
+const beboVersion = "v0.1.0"
+const fyneVersion = "v2.7.2"
+const defaultVersionLabel = service.DefaultVersionLabel
+const appID = "com.devmarvs.frago"
+
+// prefsStateKey is where releases before the project file kept the project
+// list. It is imported once and then removed.
+const prefsStateKey = "project_state_v1"
+const defaultLogTailLines = 200
+
+// prefsWorkspaceKey remembers the workspace shown in the project list.
+const prefsWorkspaceKey = "active_workspace"
+const allProjectsLabel = "All Projects"
+const trayRecentLimit = 5
+
+// openProjectStore loads the shared project file, importing the project list
+// from Fyne preferences the first time a release with the file runs.
+func openProjectStore(prefs fyne.Preferences) *store.Store {
+	path, err := store.DefaultPath()
+	if err != nil {
+		fmt.Printf("Failed to locate project file: %v\n", err)
+		path = "projects.json"
+	}
+	projectStore := store.New(store.FileBackend{Path: path})
+	if err := projectStore.Load(); err != nil {
+		fmt.Printf("Failed to load project state: %v\n", err)
+		return projectStore
+	}
+
+	if legacy := prefs.String(prefsStateKey); legacy != "" && len(projectStore.List()) == 0 {
+		if err := projectStore.Replace([]byte(legacy)); err != nil {
+			fmt.Printf("Failed to import saved projects: %v\n", err)
+			return projectStore
+		}
+	}
+	prefs.RemoveValue(prefsStateKey)
+	return projectStore
+}
+
+// showAlreadyRunning tells the user that another Frago instance owns the
+// local socket and quits once the window is closed.
+func showAlreadyRunning(a fyne.App) {
+	w := a.NewWindow("Frago")
+	message := widget.NewLabel("Frago is already running, either in another window or as \"frago daemon\".\nUse that instance, or stop it and open Frago again.")
+	message.Wrapping = fyne.TextWrapWord
+	quit := widget.NewButton("Quit", a.Quit)
+	quit.Importance = widget.HighImportance
+	w.SetContent(container.NewVBox(message, container.NewHBox(layout.NewSpacer(), quit)))
+	w.Resize(fyne.NewSize(420, 140))
+	w.SetOnClosed(a.Quit)
+	w.ShowAndRun()
+}
+
+func runGUI() {
+	a := app.NewWithID(appID)
+
+	// Saved projects are shared between the GUI, the API and the CLI.
+	projectStore := openProjectStore(a.Preferences())
+
+	// Start with the binaries from the last scan; a fresh scan runs once the
+	// window is up.
+	versions := runner.DefaultDetector().Cached()
+
+	// The GUI embeds the same daemon that "frago daemon" runs headless.
+	d, err := daemon.New(projectStore, versions)
+	if err != nil {
+		fmt.Printf("Failed to initialize daemon: %v\n", err)
+		os.Exit(1)
+	}
+	if err := d.Start(); errors.Is(err, ipc.ErrAlreadyListening) {
+		// Another window or "frago daemon" already manages the projects; a
+		// second manager would start them twice and fight over their ports.
+		showAlreadyRunning(a)
+		return
+	} else if err != nil {
+		fmt.Printf("Local API disabled: %v\n", err)
+	}
+	svc := d.Service()
+	mgr := svc.Manager()
+	apiPort := d.Port()
+	apiToken := d.Token()
+
+	windowTitle := "Frago - FrankenPHP Launcher (Powered by Bebo)"
+	if runtime.GOOS == "darwin" {
+		windowTitle = "Frago · FrankenPHP Launcher"
+	} else if runtime.GOOS == "windows" {
+		windowTitle = "Frago – FrankenPHP Launcher"
+	}
+
+	w := a.NewWindow(windowTitle)
+	w.Resize(fyne.NewSize(800, 600))
+
+	// UI Elements
+	pathEntry := widget.NewEntry()
+	pathEntry.SetPlaceHolder("Select project directory...")
+	portEntry := widget.NewEntry()
+	portEntry.SetPlaceHolder("Optional (e.g., 8080)")
+
+	// Version Selector
+	var versionOptions []string
+	var versionMap map[string]string
+	var detectedVersions []runner.PHPVersion
+	versionSelect := widget.NewSelect(nil, nil)
+
+	// binaryInfoLabel shows the versions reported by the selected binary.
+	binaryInfoLabel := widget.NewLabel("")
+	binaryInfoLabel.Wrapping = fyne.TextWrapWord
+	selectedBinary := func() string {
+		if path := versionMap[versionSelect.Selected]; path != "" {
+			return path
+		}
+		path := runner.DefaultFrankenPHPBinary()
+		if found, err := exec.LookPath(path); err == nil {
+			return found
+		}
+		return path
+	}
+	versionSelect.OnChanged = func(string) {
+		path := selectedBinary()
+		summary := "Not found"
+		for _, v := range detectedVersions {
+			if v.Path == path {
+				summary = v.Info.Summary()
+			}
+		}
+		binaryInfoLabel.SetText(summary)
+	}
+	var rescan func(full bool)
+	binaryInfoBtn := widget.NewButton("Details", func() {
+		showBinaryInfo(w, selectedBinary(), func() { rescan(false) })
+	})
+
+	// setVersions fills the version selector from detected binaries, keeping
+	// the current choice when it is still available.
+	setVersions := func(detected []runner.PHPVersion) {
+		detectedVersions = detected
+		versionOptions = nil
+		versionMap = make(map[string]string)
+
+		// Always add default "frankenphp" in path
+		versionOptions = append(versionOptions, defaultVersionLabel)
+		versionMap[defaultVersionLabel] = ""
+
+		for _, v := range detected {
+			label := v.Label
+			// avoid duplicates if default is same as detected
+			if _, exists := versionMap[label]; !exists {
+				versionOptions = append(versionOptions, label)
+				versionMap[label] = v.Path
+			}
+		}
+
+		selected := versionSelect.Selected
+		versionSelect.SetOptions(versionOptions)
+		if _, ok := versionMap[selected]; ok {
+			versionSelect.SetSelected(selected)
+		} else {
+			versionSelect.SetSelected(versionOptions[0])
+		}
+	}
+	setVersions(versions)
+
+	// rescan detects the binaries in the background, adding each one to the
+	// selector as soon as it is identified. A full rescan runs every binary
+	// again instead of trusting the cache. afterRescan, when set, runs once
+	// the scan has finished.
+	rescanBtn := widget.NewButton("Rescan", nil)
+	var afterRescan func()
+	rescan = func(full bool) {
+		rescanBtn.Disable()
+		rescanBtn.SetText("Scanning...")
+		go func() {
+			if full {
+				runner.DefaultDetector().Invalidate()
+			}
+			final := svc.RescanVersions(func(v runner.PHPVersion) {
+				fyne.Do(func() {
+					if !slices.ContainsFunc(detectedVersions, func(d runner.PHPVersion) bool { return d.Path == v.Path }) {
+						setVersions(append(slices.Clone(detectedVersions), v))
+					}
+				})
+			})
+			fyne.Do(func() {
+				setVersions(final)
+				rescanBtn.SetText("Rescan")
+				rescanBtn.Enable()
+				if fn := afterRescan; fn != nil {
+					afterRescan = nil
+					fn()
+				}
+			})
+		}()
+	}
+	rescanBtn.OnTapped = func() { rescan(true) }
+
+	// The daemon rescans periodically; binaries it finds show up here too.
+	svc.OnVersions(func(detected []runner.PHPVersion) {
+		fyne.Do(func() {
+			setVersions(detected)
+		})
+	})
+
+	addBinaryBtn := widget.NewButton("Add Binary…", func() {
+		dialog.ShowFileOpen(func(reader fyne.URIReadCloser, err error) {
+			if err != nil || reader == nil {
+				return
+			}
+			path := reader.URI().Path()
+			reader.Close()
+			go func() {
+				_, err := runner.DefaultDetector().AddCustomBinary(path)
+				fyne.Do(func() {
+					if err != nil {
+						dialog.ShowError(err, w)
+						return
+					}
+					rescan(false)
+				})
+			}()
+		}, w)
+	})
+
+	// Downloaded builds join the detected versions as soon as they are
+	// installed.
+	buildsBtn := widget.NewButton("Manage Builds", func() {
+		builds, err := updater.NewBuilds()
+		if err == nil {
+			builds.Client, err = releaseClient(a.Preferences())
+		}
+		if err != nil {
+			dialog.ShowError(err, w)
+			return
+		}
+		showBuilds(w, builds, func() {
+			rescan(false)
+		})
+	})
+
+	// Update Checker: every detected binary is compared with the newest
+	// release on the chosen channel.
+	var updateBtn *widget.Button
+	var checkUpdates func()
+	checkUpdates = func() {
+		updateBtn.Disable()
+		updateBtn.SetText("Checking...")
+
+		binaries := detectedVersions
+		opts := updateOptions(a.Preferences())
+		client, err := releaseClient(a.Preferences())
+		go func() {
+			var updates []updater.Update
+			if err == nil {
+				updates, err = client.Check(context.Background(), binaries, opts)
+			}
+
+			fyne.Do(func() {
+				updateBtn.SetText("Check for Updates")
+				updateBtn.Enable()
+				if err != nil {
+					dialog.ShowError(err, w)
+					return
+				}
+				showUpdates(w, a.Preferences(), updates, checkUpdates)
+			})
+		}()
+	}
+	updateBtn = widget.NewButton("Check for Updates", checkUpdates)
+
+	actionRow := func(buttons ...fyne.CanvasObject) *fyne.Container {
+		objects := make([]fyne.CanvasObject, 0, len(buttons)+1)
+		objects = append(objects, layout.NewSpacer())
+		objects = append(objects, buttons...)
+		return container.NewHBox(objects...)
+	}
+
+	formatUptime := func(start time.Time, running bool) string {
+		if !running || start.IsZero() {
+			return "stopped"
+		}
+		return time.Since(start).Round(time.Second).String()
+	}
+
+	formatCPU := func(cpu float64) string {
+		if cpu < 0 {
+			return "n/a"
+		}
+		return fmt.Sprintf("%.1f%%", cpu)
+	}
+
+	formatRAM := func(bytes int64) string {
+		if bytes <= 0 {
+			return "n/a"
+		}
+		return fmt.Sprintf("%.1f MB", float64(bytes)/(1024*1024))
+	}
+
+	appListContainer := container.NewVBox()
+	recentListContainer := container.NewVBox()
+
+	// updateProject persists a change to a saved project. Failures are logged
+	// rather than shown, matching how state saves have always been handled.
+	updateProject := func(path string, fn func(*store.Project)) {
+		if _, err := projectStore.Update(path, fn); err != nil {
+			fmt.Printf("Failed to save project state: %v\n", err)
+		}
+	}
+
+	parseTailCount := func(value string) int {
+		n, err := strconv.Atoi(value)
+		if err != nil || n <= 0 {
+			return defaultLogTailLines
+		}
+		return n
+	}
+
+	parsePortInput := func(value string) (int, error) {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			return 0, nil
+		}
+		portValue, err := strconv.Atoi(value)
+		if err != nil || portValue < 1 || portValue > 65535 {
+			return 0, fmt.Errorf("port must be a number between 1 and 65535")
+		}
+		return portValue, nil
+	}
+
+	// showLogs shows the output of a project, or of one of its sidecars when
+	// sidecar is set.
+	showLogs := func(info store.Project, sidecar string) {
+		tailOptions := []string{"50", "200", "500", "1000"}
+		tailSelect := widget.NewSelect(tailOptions, nil)
+		tailSelect.SetSelected(fmt.Sprintf("%d", defaultLogTailLines))
+
+		logEntry := widget.NewMultiLineEntry()
+		logEntry.Wrapping = fyne.TextWrapBreak
+		logEntry.Disable()
+
+		updateLogs := func() {
+			lines := parseTailCount(tailSelect.Selected)
+			text := mgr.TailLogs(info.Path, lines)
+			if sidecar != "" {
+				text = mgr.TailSidecarLogs(info.Path, sidecar, lines)
+			}
+			if text == "" {
+				text = "No logs yet."
+			}
+			logEntry.SetText(text)
+		}
+
+		tailSelect.OnChanged = func(string) {
+			updateLogs()
+		}
+
+		refreshBtn := widget.NewButton("Refresh", func() {
+			updateLogs()
+		})
+
+		copyBtn := widget.NewButton("Copy", func() {
+			updateLogs()
+			w.Clipboard().SetContent(logEntry.Text)
+		})
+
+		exportBtn := widget.NewButton("Export", func() {
+			updateLogs()
+			save := dialog.NewFileSave(func(writer fyne.URIWriteCloser, err error) {
+				if err != nil {
+					dialog.ShowError(err, w)
+					return
+				}
+				if writer == nil {
+					return
+				}
+				defer writer.Close()
+				if _, err := writer.Write([]byte(logEntry.Text)); err != nil {
+					dialog.ShowError(err, w)
+				}
+			}, w)
+
+			base := filepath.Base(info.Path)
+			if base == "" || base == "." || base == string(filepath.Separator) {
+				base = "frago"
+			}
+			if sidecar != "" {
+				base = fmt.Sprintf("%s-%s", base, sidecar)
+			}
+			save.SetFileName(fmt.Sprintf("%s.log", base))
+			save.Show()
+		})
+
+		controls := container.NewHBox(
+			widget.NewLabel("Tail"),
+			tailSelect,
+			refreshBtn,
+			layout.NewSpacer(),
+			copyBtn,
+			exportBtn,
+		)
+
+		logScroll := container.NewScroll(logEntry)
+		logScroll.SetMinSize(fyne.NewSize(0, 260))
+
+		content := container.NewBorder(controls, nil, nil, nil, logScroll)
+		title := fmt.Sprintf("Logs - %s", info.DisplayName())
+		if sidecar != "" {
+			title = fmt.Sprintf("Logs - %s (%s)", info.DisplayName(), sidecar)
+		}
+		logDialog := dialog.NewCustom(title, "Close", content, w)
+		logDialog.Resize(fyne.NewSize(720, 480))
+		updateLogs()
+		logDialog.Show()
+	}
+	var refreshAppList func()
+	var refreshTrayMenu func()
+
+	// runInBackground runs a start, stop or restart off the UI goroutine, as
+	// they wait on ports, the Caddyfile and sidecars, then reports any error
+	// and refreshes the list.
+	runInBackground := func(action func() error) {
+		go func() {
+			err := action()
+			fyne.Do(func() {
+				if err != nil {
+					dialog.ShowError(err, w)
+				}
+				refreshAppList()
+			})
+		}()
+	}
+	var startAllBtn *widget.Button
+	var stopAllBtn *widget.Button
+	var startGroupBtn *widget.Button
+	var stopGroupBtn *widget.Button
+
+	// The project list shows either every project or one workspace, in its
+	// start order.
+	activeWorkspace := a.Preferences().String(prefsWorkspaceKey)
+	workspaceIDs := make(map[string]string)
+	workspaceSelect := widget.NewSelect(nil, nil)
+	workspaceSelect.OnChanged = func(selected string) {
+		id := workspaceIDs[selected]
+		if id == activeWorkspace {
+			return
+		}
+		activeWorkspace = id
+		a.Preferences().SetString(prefsWorkspaceKey, id)
+		refreshAppList()
+	}
+	// The search box narrows the list to projects whose name, path, tags or
+	// status match every word typed.
+	searchEntry := widget.NewEntry()
+	searchEntry.SetPlaceHolder("Search name, path, tag or status")
+	searchEntry.OnChanged = func(string) {
+		refreshAppList()
+	}
+
+	refreshWorkspaceSelect := func() []store.Workspace {
+		workspaces := projectStore.Workspaces()
+		options := []string{allProjectsLabel}
+		ids := map[string]string{allProjectsLabel: ""}
+		selected := allProjectsLabel
+		for _, ws := range workspaces {
+			options = append(options, ws.Name)
+			ids[ws.Name] = ws.ID
+			if ws.ID == activeWorkspace {
+				selected = ws.Name
+			}
+		}
+		if selected == allProjectsLabel {
+			activeWorkspace = ""
+		}
+		workspaceIDs = ids
+		if strings.Join(options, "\n") != strings.Join(workspaceSelect.Options, "\n") {
+			workspaceSelect.SetOptions(options)
+		}
+		if workspaceSelect.Selected != selected {
+			workspaceSelect.SetSelected(selected)
+		}
+		return workspaces
+	}
+
+	refreshAppList = func() {
+		appListContainer.Objects = nil
+		recentListContainer.Objects = nil
+
+		processes := mgr.List()
+		running := make(map[string]*runner.Process)
+		for _, p := range processes {
+			running[p.ProjectPath] = p
+		}
+		ordered := projectStore.Sorted()
+		inWorkspace := false
+		for _, ws := range refreshWorkspaceSelect() {
+			if ws.ID != activeWorkspace {
+				continue
+			}
+			inWorkspace = true
+			ordered = ordered[:0]
+			for _, path := range ws.Projects {
+				if info, ok := projectStore.Get(path); ok {
+					ordered = append(ordered, info)
+				}
+			}
+		}
+		for _, btn := range []*widget.Button{startGroupBtn, stopGroupBtn} {
+			if btn == nil {
+				continue
+			}
+			if !inWorkspace {
+				btn.Hide()
+				continue
+			}
+			btn.Show()
+			if len(ordered) == 0 {
+				btn.Disable()
+			} else {
+				btn.Enable()
+			}
+		}
+		if startAllBtn != nil {
+			if len(ordered) == 0 {
+				startAllBtn.Disable()
+			} else {
+				startAllBtn.Enable()
+			}
+		}
+		if stopAllBtn != nil {
+			if len(ordered) == 0 {
+				stopAllBtn.Disable()
+			} else {
+				stopAllBtn.Enable()
+			}
+		}
+		if len(ordered) == 0 && inWorkspace {
+			appListContainer.Add(widget.NewLabel("No projects in this workspace. Use Workspaces to add some."))
+			recentListContainer.Add(widget.NewLabel("No recent projects yet."))
+		} else if len(ordered) == 0 {
+			appListContainer.Add(widget.NewLabel("No projects yet. Use New Project to launch one."))
+			recentListContainer.Add(widget.NewLabel("No recent projects yet."))
+		} else {
+			shown := 0
+			for _, info := range ordered {
+				infoCopy := info
+				proc, isRunning := running[info.Path]
+
+				title := info.Path
+				if info.Name != "" {
+					title = fmt.Sprintf("%s (%s)", info.Name, info.Path)
+				}
+				if len(info.Tags) > 0 {
+					title = fmt.Sprintf("%s  [%s]", title, strings.Join(info.Tags, ", "))
+				}
+				lbl := widget.NewLabel(title)
+				lbl.Wrapping = fyne.TextWrapBreak
+
+				versionLabel := info.LastVersionLabel
+				url := info.LastURL
+				startedAt := time.Time{}
+				if isRunning {
+					if proc.VersionLabel != "" {
+						versionLabel = proc.VersionLabel
+					}
+					if proc.URL != "" {
+						url = proc.URL
+					}
+					startedAt = proc.StartedAt
+				}
+
+				if versionLabel == "" {
+					versionLabel = "Unknown"
+				}
+				if url == "" {
+					url = "n/a"
+				}
+
+				statusText := "Stopped"
+				healthText := "n/a"
+				unhealthy := false
+				failed := false
+				if isRunning {
+					statusText = "Running"
+					healthText = "Checking"
+					if healthInfo, ok := svc.Health(info.Path); ok {
+						if healthInfo.Healthy {
+							healthText = "Healthy"
+						} else {
+							healthText = "Unhealthy"
+							unhealthy = true
+						}
+					}
+				} else if exitInfo, ok := mgr.LastExit(info.Path); ok && exitInfo.Failed {
+					statusText = "Failed"
+					healthText = "Failed"
+					failed = true
+				}
+
+				statusLine := fmt.Sprintf("Status: %s | Health: %s | PHP: %s | URL: %s | Uptime: %s | Debug: %s", statusText, healthText, versionLabel, url, formatUptime(startedAt, isRunning), service.XdebugSummary(info.Xdebug))
+				if isRunning {
+					cpuText := "n/a"
+					ramText := "n/a"
+					if statsInfo, ok := svc.Stats(info.Path); ok {
+						if statsInfo.LastError == "" || statsInfo.RSSBytes > 0 {
+							cpuText = formatCPU(statsInfo.CPUPercent)
+							ramText = formatRAM(statsInfo.RSSBytes)
+						}
+					}
+					statusLine = fmt.Sprintf("%s | CPU: %s | RAM: %s", statusLine, cpuText, ramText)
+				}
+
+				statusLabel := widget.NewLabel(statusLine)
+				statusLabel.Wrapping = fyne.TextWrapBreak
+
+				copyURL := url
+				copyBtn := widget.NewButton("Copy URL", func() {
+					w.Clipboard().SetContent(copyURL)
+				})
+				if copyURL == "n/a" {
+					copyBtn.Disable()
+				}
+
+				statusRow := container.NewBorder(nil, nil, nil, copyBtn, statusLabel)
+				rows := []fyne.CanvasObject{lbl, statusRow}
+
+				// Sidecars from the manifest are listed under the project.
+				// Stopping one may wait for it to exit, so their controls
+				// run off the UI thread.
+				sidecars, _ := svc.Sidecars(info.Path)
+				for _, sc := range sidecars {
+					scName := sc.Name
+					scStatus := "Stopped"
+					scStarted := time.Time{}
+					if sc.Running {
+						scStatus = "Running"
+						scStarted = sc.StartedAt
+					} else if sc.LastExit != nil && sc.LastExit.Failed {
+						scStatus = "Failed"
+					}
+					restartPolicy := sc.Restart
+					if restartPolicy == store.RestartNever {
+						restartPolicy = "never"
+					}
+					scLine := fmt.Sprintf("Sidecar %s: %s | Restart: %s | Uptime: %s", scName, scStatus, restartPolicy, formatUptime(scStarted, sc.Running))
+					if sc.Running {
+						cpuText := "n/a"
+						ramText := "n/a"
+						if sc.Stats != nil && (sc.Stats.LastError == "" || sc.Stats.RSSBytes > 0) {
+							cpuText = formatCPU(sc.Stats.CPUPercent)
+							ramText = formatRAM(sc.Stats.RSSBytes)
+						}
+						scLine = fmt.Sprintf("%s | CPU: %s | RAM: %s", scLine, cpuText, ramText)
+					}
+					scLabel := widget.NewLabel(scLine)
+					scLabel.Wrapping = fyne.TextWrapBreak
+
+					var scButtons []*widget.Button
+					sidecarAction := func(fn func(path, name string) error) func() {
+						return func() {
+							for _, btn := range scButtons {
+								btn.Disable()
+							}
+							go func() {
+								err := fn(infoCopy.Path, scName)
+								fyne.Do(func() {
+									if err != nil {
+										dialog.ShowError(err, w)
+									}
+									refreshAppList()
+								})
+							}()
+						}
+					}
+					scToggleBtn := widget.NewButton("Start", sidecarAction(svc.StartSidecar))
+					if sc.Running {
+						scToggleBtn = widget.NewButton("Stop", sidecarAction(svc.StopSidecar))
+					}
+					scRestartBtn := widget.NewButton("Restart", sidecarAction(svc.RestartSidecar))
+					scLogsBtn := widget.NewButton("Logs", func() {
+						showLogs(infoCopy, scName)
+					})
+					scButtons = []*widget.Button{scToggleBtn, scRestartBtn}
+					rows = append(rows, container.NewBorder(nil, nil, nil, container.NewHBox(scToggleBtn, scRestartBtn, scLogsBtn), scLabel))
+				}
+
+				pinLabel := "Pin"
+				if info.Pinned {
+					pinLabel = "Unpin"
+				}
+				pinBtn := widget.NewButton(pinLabel, func() {
+					updateProject(infoCopy.Path, func(p *store.Project) {
+						p.Pinned = !p.Pinned
+					})
+					refreshAppList()
+				})
+
+				autoStartCheck := widget.NewCheck("Auto-start", nil)
+				autoStartCheck.SetChecked(info.AutoStart)
+				autoStartCheck.OnChanged = func(checked bool) {
+					updateProject(infoCopy.Path, func(p *store.Project) {
+						p.AutoStart = checked
+					})
+					refreshAppList()
+				}
+
+				logsBtn := widget.NewButton("Logs", func() {
+					showLogs(infoCopy, "")
+				})
+
+				// Toggling Xdebug restarts a running project, which may take
+				// a moment, so it happens off the UI thread.
+				debugCheck := widget.NewCheck("Xdebug", nil)
+				debugCheck.SetChecked(info.Xdebug.Enabled)
+				debugCheck.OnChanged = func(checked bool) {
+					x := infoCopy.Xdebug
+					x.Enabled = checked
+					debugCheck.Disable()
+					go func() {
+						_, err := svc.SetXdebug(infoCopy.Path, x)
+						fyne.Do(func() {
+							if err != nil {
+								dialog.ShowError(err, w)
+							}
+							refreshAppList()
+						})
+					}()
+				}
+
+				editBtn := widget.NewButton("Edit", func() {
+					showProjectSettings(w, svc, infoCopy, versionOptions, versionMap, refreshAppList)
+				})
+
+				openFolderBtn := widget.NewButton("Open Folder", func() {
+					if err := runner.OpenFolder(infoCopy.Path); err != nil {
+						dialog.ShowError(err, w)
+						return
+					}
+					updateProject(infoCopy.Path, func(p *store.Project) {
+						p.LastUsed = time.Now()
+					})
+					refreshAppList()
+				})
+
+				var restartBtn *widget.Button
+				restartBtn = widget.NewButton("Restart", func() {
+					restartBtn.Disable()
+					runInBackground(func() error { return svc.Restart(infoCopy.Path) })
+				})
+
+				pathCopy := info.Path
+				var primaryBtn *widget.Button
+				var actionButtons []fyne.CanvasObject
+
+				if isRunning {
+					urlCopy := proc.URL
+					stopBtn := widget.NewButton("Stop", nil)
+					stopBtn.Importance = widget.DangerImportance
+
+					primaryBtn = widget.NewButton("Open", func() {
+						_ = runner.OpenBrowser(urlCopy)
+						updateProject(infoCopy.Path, func(p *store.Project) {
+							p.LastUsed = time.Now()
+						})
+						refreshAppList()
+					})
+
+					stopBtn.OnTapped = func() {
+						stopBtn.Disable()
+						runInBackground(func() error { return svc.Stop(pathCopy) })
+					}
+
+					actionButtons = []fyne.CanvasObject{autoStartCheck, debugCheck, openFolderBtn, logsBtn, editBtn, primaryBtn, stopBtn, pinBtn}
+					if unhealthy {
+						actionButtons = []fyne.CanvasObject{autoStartCheck, debugCheck, openFolderBtn, logsBtn, editBtn, restartBtn, primaryBtn, stopBtn, pinBtn}
+					}
+				} else {
+					deleteBtn := widget.NewButton("Delete", func() {
+						if err := svc.Delete(pathCopy); err != nil {
+							dialog.ShowError(err, w)
+							return
+						}
+						refreshAppList()
+					})
+					deleteBtn.Importance = widget.DangerImportance
+
+					if failed {
+						primaryBtn = restartBtn
+					} else {
+						// Saved projects run with the binary and port from
+						// their settings; see Edit.
+						var runSavedBtn *widget.Button
+						runSavedBtn = widget.NewButton("Run", func() {
+							runSavedBtn.Disable()
+							runInBackground(func() error { return svc.StartSaved(infoCopy.Path) })
+						})
+						primaryBtn = runSavedBtn
+					}
+
+					actionButtons = []fyne.CanvasObject{autoStartCheck, debugCheck, openFolderBtn, logsBtn, editBtn, primaryBtn, deleteBtn, pinBtn}
+				}
+
+				if info.Matches(searchEntry.Text, statusText+" "+healthText) {
+					appListContainer.Add(container.NewVBox(append(rows, actionRow(actionButtons...))...))
+					shown++
+				}
+
+				recentLabel := widget.NewLabel(info.Path)
+				recentLabel.Wrapping = fyne.TextWrapBreak
+
+				useBtn := widget.NewButton("Use", func() {
+					pathEntry.SetText(infoCopy.Path)
+					if infoCopy.PreferredPort > 0 {
+						portEntry.SetText(fmt.Sprintf("%d", infoCopy.PreferredPort))
+					} else {
+						portEntry.SetText("")
+					}
+					updateProject(infoCopy.Path, func(p *store.Project) {
+						p.LastUsed = time.Now()
+					})
+					refreshAppList()
+				})
+
+				recentPinLabel := "Pin"
+				if info.Pinned {
+					recentPinLabel = "Unpin"
+				}
+				recentPinBtn := widget.NewButton(recentPinLabel, func() {
+					updateProject(infoCopy.Path, func(p *store.Project) {
+						p.Pinned = !p.Pinned
+					})
+					refreshAppList()
+				})
+
+				recentListContainer.Add(container.NewVBox(recentLabel, actionRow(useBtn, recentPinBtn)))
+			}
+			if shown == 0 {
+				appListContainer.Add(widget.NewLabel("No projects match the search."))
+			}
+		}
+		appListContainer.Refresh()
+		recentListContainer.Refresh()
+		if refreshTrayMenu != nil {
+			refreshTrayMenu()
+		}
+	}
+
+	// syncProjects records the ports and binaries of running projects in the
+	// store, which locks and reads the project file, so it runs off the UI
+	// goroutine and only when the list is explicitly refreshed or the store
+	// changes; searching and the ticker redraw from memory.
+	syncProjects := func() {
+		go func() {
+			if err := svc.Sync(); err != nil {
+				fmt.Printf("Failed to save project state: %v\n", err)
+			}
+			fyne.Do(refreshAppList)
+		}()
+	}
+
+	// Initial refresh
+	refreshAppList()
+	syncProjects()
+
+	// Changes made through the API or by another process update the window.
+	projectStore.Subscribe(syncProjects)
+
+	// Bulk starts wait for dependencies to become healthy, so they run off
+	// the UI goroutine.
+	autoStartProjects := func() {
+		go func() {
+			failures := service.FormatFailures(svc.AutoStart())
+			fyne.Do(func() {
+				refreshAppList()
+				if failures != "" {
+					dialog.ShowError(fmt.Errorf("Auto-start failures:\n%s", failures), w)
+				}
+			})
+		}()
+	}
+
+	// Choose Folder Action
+	chooseBtn := widget.NewButton("Choose Folder", func() {
+		dialog.ShowFolderOpen(func(uri fyne.ListableURI, err error) {
+			if err != nil || uri == nil {
+				return
+			}
+			pathEntry.SetText(uri.Path())
+		}, w)
+	})
+
+	var runBtn *widget.Button
+	runBtn = widget.NewButton("Run FrankenPHP", func() {
+		dir := pathEntry.Text
+		if dir == "" {
+			dialog.ShowError(fmt.Errorf("please select a directory"), w)
+			return
+		}
+
+		desiredPort, err := parsePortInput(portEntry.Text)
+		if err != nil {
+			dialog.ShowError(err, w)
+			return
+		}
+
+		// Check if already running
+		if _, exists := mgr.Get(dir); exists {
+			dialog.ShowInformation("Already Running", "This project is already running.", w)
+			return
+		}
+
+		selectedLabel := versionSelect.Selected
+		versionLabel := selectedLabel
+		if strings.HasPrefix(selectedLabel, defaultVersionLabel) {
+			versionLabel = ""
+		}
+		binaryPath := versionMap[versionSelect.Selected]
+
+		runBtn.Disable()
+		go func() {
+			err := svc.Start(dir, binaryPath, versionLabel, desiredPort)
+			fyne.Do(func() {
+				runBtn.Enable()
+				if err != nil {
+					dialog.ShowError(err, w)
+					return
+				}
+
+				// Clear entry and refresh list
+				pathEntry.SetText("")
+				portEntry.SetText("")
+				refreshAppList()
+			})
+		}()
+	})
+	runBtn.Importance = widget.HighImportance
+
+	// Poller to keep UI in sync
+	go func() {
+		ticker := time.NewTicker(1 * time.Second)
+		defer ticker.Stop()
+		for range ticker.C {
+			fyne.Do(func() {
+				refreshAppList()
+			})
+		}
+	}()
+
+	// Manual refresh button
+	refreshBtn := widget.NewButton("Refresh List", syncProjects)
+
+	startAllProjects := func() {
+		go func() {
+			failures := service.FormatFailures(svc.StartAll())
+			fyne.Do(func() {
+				refreshAppList()
+				if failures != "" {
+					dialog.ShowError(fmt.Errorf("Some projects failed to start:\n%s", failures), w)
+				}
+			})
+		}()
+	}
+
+	stopAllProjects := func() {
+		dialog.ShowConfirm("Stop All Projects", "Stop all running projects?", func(confirm bool) {
+			if !confirm {
+				return
+			}
+			go func() {
+				results := svc.StopAll()
+				fyne.Do(func() {
+					refreshAppList()
+					if failures := service.FormatFailures(results); failures != "" {
+						dialog.ShowError(fmt.Errorf("Some projects failed to stop:\n%s", failures), w)
+						return
+					}
+					if len(results) == 0 {
+						dialog.ShowInformation("Stop All", "No running projects to stop.", w)
+					}
+				})
+			}()
+		}, w)
+	}
+
+	startGroup := func(ws store.Workspace) {
+		go func() {
+			results, err := svc.StartGroup(ws.ID)
+			fyne.Do(func() {
+				refreshAppList()
+				if err != nil {
+					dialog.ShowError(err, w)
+					return
+				}
+				if failures := service.FormatFailures(results); failures != "" {
+					dialog.ShowError(fmt.Errorf("Some projects in %s failed to start:\n%s", ws.Name, failures), w)
+				}
+			})
+		}()
+	}
+
+	stopGroup := func(ws store.Workspace) {
+		dialog.ShowConfirm("Stop Group", fmt.Sprintf("Stop the running projects in %s?", ws.Name), func(confirm bool) {
+			if !confirm {
+				return
+			}
+			go func() {
+				results, err := svc.StopGroup(ws.ID)
+				fyne.Do(func() {
+					refreshAppList()
+					if err != nil {
+						dialog.ShowError(err, w)
+						return
+					}
+					if failures := service.FormatFailures(results); failures != "" {
+						dialog.ShowError(fmt.Errorf("Some projects in %s failed to stop:\n%s", ws.Name, failures), w)
+					}
+				})
+			}()
+		}, w)
+	}
+
+	withActiveWorkspace := func(fn func(store.Workspace)) func() {
+		return func() {
+			if ws, ok := projectStore.GetWorkspace(activeWorkspace); ok {
+				fn(ws)
+			}
+		}
+	}
+
+	startGroupBtn = widget.NewButton("Start Group", withActiveWorkspace(startGroup))
+	startGroupBtn.Importance = widget.HighImportance
+
+	stopGroupBtn = widget.NewButton("Stop Group", withActiveWorkspace(stopGroup))
+	stopGroupBtn.Importance = widget.DangerImportance
+
+	workspacesBtn := widget.NewButton("Workspaces", func() {
+		showWorkspaces(w, projectStore)
+	})
+
+	startAllBtn = widget.NewButton("Start All", startAllProjects)
+	startAllBtn.Importance = widget.HighImportance
+
+	stopAllBtn = widget.NewButton("Stop All", stopAllProjects)
+	stopAllBtn.Importance = widget.DangerImportance
+
+	// Do-not-disturb silences notifications for every project; the tray and
+	// the main menu show its state.
+	var dndMenuItem *fyne.MenuItem
+	toggleDoNotDisturb := func() {
+		on := !a.Preferences().Bool(prefsDoNotDisturbKey)
+		a.Preferences().SetBool(prefsDoNotDisturbKey, on)
+		if dndMenuItem != nil {
+			dndMenuItem.Checked = on
+			if menu := w.MainMenu(); menu != nil {
+				menu.Refresh()
+			}
+		}
+		refreshTrayMenu()
+	}
+	notifyEvents(a, svc)
+	scheduleUpdateChecks(a, svc)
+
+	refreshTrayMenu = func() {
+		ordered := projectStore.Sorted()
+		running := make(map[string]*runner.Process)
+		for _, proc := range mgr.List() {
+			running[proc.ProjectPath] = proc
+		}
+
+		showItem := fyne.NewMenuItem("Show Frago", func() {
+			w.Show()
+			w.RequestFocus()
+		})
+
+		startAllItem := fyne.NewMenuItem("Start All", startAllProjects)
+		stopAllItem := fyne.NewMenuItem("Stop All", stopAllProjects)
+		if len(ordered) == 0 {
+			startAllItem.Disabled = true
+			stopAllItem.Disabled = true
+		}
+
+		// Projects that share a display name are told apart by their parent
+		// directory.
+		nameCount := make(map[string]int)
+		for i, info := range ordered {
+			if i < trayRecentLimit {
+				nameCount[info.DisplayName()]++
+			}
+		}
+
+		recentItems := make([]*fyne.MenuItem, 0, trayRecentLimit)
+		for _, info := range ordered {
+			if len(recentItems) >= trayRecentLimit {
+				break
+			}
+			infoCopy := info
+			label := infoCopy.DisplayName()
+			if nameCount[label] > 1 {
+				label = fmt.Sprintf("%s (%s)", label, filepath.Base(filepath.Dir(infoCopy.Path)))
+			}
+
+			projectItem := fyne.NewMenuItem(label, nil)
+			var actions []*fyne.MenuItem
+
+			if proc, ok := running[infoCopy.Path]; ok {
+				urlCopy := proc.URL
+				openItem := fyne.NewMenuItem("Open", func() {
+					_ = runner.OpenBrowser(urlCopy)
+					updateProject(infoCopy.Path, func(p *store.Project) {
+						p.LastUsed = time.Now()
+					})
+					refreshAppList()
+				})
+				stopItem := fyne.NewMenuItem("Stop", func() {
+					runInBackground(func() error { return svc.Stop(infoCopy.Path) })
+				})
+				actions = append(actions, openItem, stopItem)
+			} else {
+				startItem := fyne.NewMenuItem("Start", func() {
+					runInBackground(func() error { return svc.StartSaved(infoCopy.Path) })
+				})
+				actions = append(actions, startItem)
+			}
+
+			openFolderItem := fyne.NewMenuItem("Open Folder", func() {
+				if err := runner.OpenFolder(infoCopy.Path); err != nil {
+					dialog.ShowError(err, w)
+					return
+				}
+				updateProject(infoCopy.Path, func(p *store.Project) {
+					p.LastUsed = time.Now()
+				})
+				refreshAppList()
+			})
+			actions = append(actions, openFolderItem)
+
+			muteItem := fyne.NewMenuItem("Mute Notifications", func() {
+				updateProject(infoCopy.Path, func(p *store.Project) {
+					p.Muted = !p.Muted
+				})
+				refreshAppList()
+			})
+			muteItem.Checked = infoCopy.Muted
+			actions = append(actions, fyne.NewMenuItemSeparator(), muteItem)
+
+			projectItem.ChildMenu = fyne.NewMenu(label, actions...)
+			recentItems = append(recentItems, projectItem)
+		}
+
+		if len(recentItems) == 0 {
+			empty := fyne.NewMenuItem("No recent projects", nil)
+			empty.Disabled = true
+			recentItems = append(recentItems, empty)
+		}
+
+		recentItem := fyne.NewMenuItem("Recent", nil)
+		recentItem.ChildMenu = fyne.NewMenu("Recent", recentItems...)
+
+		var workspaceItems []*fyne.MenuItem
+		for _, ws := range projectStore.Workspaces() {
+			wsCopy := ws
+			item := fyne.NewMenuItem(ws.Name, nil)
+			item.ChildMenu = fyne.NewMenu(ws.Name,
+				fyne.NewMenuItem("Start Group", func() { startGroup(wsCopy) }),
+				fyne.NewMenuItem("Stop Group", func() { stopGroup(wsCopy) }),
+			)
+			workspaceItems = append(workspaceItems, item)
+		}
+		if len(workspaceItems) == 0 {
+			empty := fyne.NewMenuItem("No workspaces", nil)
+			empty.Disabled = true
+			workspaceItems = append(workspaceItems, empty)
+		}
+		workspacesItem := fyne.NewMenuItem("Workspaces", nil)
+		workspacesItem.ChildMenu = fyne.NewMenu("Workspaces", workspaceItems...)
+
+		dndItem := fyne.NewMenuItem("Do Not Disturb", toggleDoNotDisturb)
+		dndItem.Checked = a.Preferences().Bool(prefsDoNotDisturbKey)
+
+		trayMenu := fyne.NewMenu("Frago",
+			showItem,
+			fyne.NewMenuItemSeparator(),
+			startAllItem,
+			stopAllItem,
+			fyne.NewMenuItemSeparator(),
+			workspacesItem,
+			recentItem,
+			fyne.NewMenuItemSeparator(),
+			dndItem,
+		)
+
+		if desk, ok := a.(desktop.App); ok {
+			desk.SetSystemTrayMenu(trayMenu)
+		}
+	}
+	refreshAppList()
+
+	apiLabel := widget.NewLabel(fmt.Sprintf("API available at http://localhost:%d", apiPort))
+	apiLabel.TextStyle = fyne.TextStyle{Monospace: true}
+	apiLabel.Alignment = fyne.TextAlignCenter
+	copyTokenBtn := widget.NewButton("Copy Token", func() {
+		w.Clipboard().SetContent(apiToken)
+	})
+	if apiToken == "" {
+		copyTokenBtn.Disable()
+	}
+
+	title := widget.NewLabelWithStyle("Frago FrankenPHP Launcher", fyne.TextAlignLeading, fyne.TextStyle{Bold: true})
+	subtitle := widget.NewLabel("Launch and manage FrankenPHP projects")
+	header := container.NewVBox(title, subtitle)
+
+	projectDirField := container.NewVBox(
+		pathEntry,
+		actionRow(chooseBtn),
+	)
+	portField := container.NewVBox(portEntry)
+	versionField := container.NewVBox(
+		versionSelect,
+		container.NewBorder(nil, nil, nil, binaryInfoBtn, binaryInfoLabel),
+		actionRow(rescanBtn, addBinaryBtn, buildsBtn, updateBtn),
+	)
+	launchForm := widget.NewForm(
+		&widget.FormItem{Text: "Project Directory", Widget: projectDirField},
+		&widget.FormItem{Text: "Port (Optional)", Widget: portField},
+		&widget.FormItem{Text: "PHP Version", Widget: versionField},
+	)
+
+	recentHeader := widget.NewLabelWithStyle("Recent Projects", fyne.TextAlignLeading, fyne.TextStyle{Bold: true})
+	recentScroll := container.NewScroll(recentListContainer)
+	recentScroll.SetMinSize(fyne.NewSize(0, 140))
+
+	launchCard := widget.NewCard("New Project", "Configure and launch FrankenPHP for your PHP application.", container.NewVBox(
+		launchForm,
+		runBtn,
+		widget.NewSeparator(),
+		recentHeader,
+		recentScroll,
+	))
+
+	listHeader := container.NewVBox(
+		container.NewBorder(nil, nil, widget.NewLabel("Show"), workspacesBtn, workspaceSelect),
+		searchEntry,
+		container.NewHBox(layout.NewSpacer(), startGroupBtn, stopGroupBtn, startAllBtn, stopAllBtn, refreshBtn),
+	)
+	scrollList := container.NewScroll(appListContainer)
+	scrollList.SetMinSize(fyne.NewSize(0, 300))
+
+	listArea := container.NewBorder(listHeader, nil, nil, nil, scrollList)
+
+	runningCard := widget.NewCard("Running Applications", "", listArea)
+
+	body := container.NewGridWithColumns(2,
+		launchCard,
+		runningCard,
+	)
+
+	content := container.NewBorder(
+		header,
+		container.NewVBox(widget.NewSeparator(), container.NewBorder(nil, nil, nil, copyTokenBtn, apiLabel)),
+		nil, nil,
+		container.NewPadded(body),
+	)
+
+	w.SetContent(content)
+
+	aboutItem := fyne.NewMenuItem("About Frago", func() {
+		go func() {
+			frankenInfo := "Unknown"
+			if info, err := runner.InspectBinary(runner.DefaultFrankenPHPBinary()); err == nil {
+				frankenInfo = info.Summary()
+				if len(info.Extensions) > 0 {
+					frankenInfo += fmt.Sprintf("\nPHP extensions: %d", len(info.Extensions))
+				}
+			}
+
+			text := fmt.Sprintf("%s version: %s\nFrankenPHP: %s\nBebo: %s\nFyne: %s\n\nPowered by Bebo and Fyne.", appName, appVersion, frankenInfo, beboVersion, fyneVersion)
+			fyne.Do(func() {
+				dialog.ShowInformation("About Frago", text, w)
+			})
+		}()
+	})
+
+	exportItem := fyne.NewMenuItem("Export Projects…", func() {
+		showExportProjects(w, projectStore)
+	})
+	importItem := fyne.NewMenuItem("Import Projects…", func() {
+		showImportProjects(w, projectStore)
+	})
+
+	updateSettingsItem := fyne.NewMenuItem("Update Settings…", func() {
+		showUpdateSettings(w, a.Preferences())
+	})
+	fragoUpdateItem := fyne.NewMenuItem("Check for Frago Updates…", func() {
+		checkFragoUpdate(a, w)
+	})
+	rollbackItem := fyne.NewMenuItem("Roll Back Frago Update…", func() {
+		rollbackFrago(a, w)
+	})
+
+	dndMenuItem = fyne.NewMenuItem("Do Not Disturb", toggleDoNotDisturb)
+	dndMenuItem.Checked = a.Preferences().Bool(prefsDoNotDisturbKey)
+
+	mainMenu := fyne.NewMainMenu(
+		fyne.NewMenu("Frago", aboutItem, fyne.NewMenuItemSeparator(), exportItem, importItem, fyne.NewMenuItemSeparator(), fragoUpdateItem, rollbackItem, updateSettingsItem, fyne.NewMenuItemSeparator(), dndMenuItem),
+	)
+	w.SetMainMenu(mainMenu)
+	// Auto-start waits for the first scan, so PHP constraints and binaries
+	// remembered by label resolve against the binaries installed now rather
+	// than a stale or empty cache.
+	a.Lifecycle().SetOnStarted(func() {
+		showWhatsNew(a, w)
+		afterRescan = autoStartProjects
+		rescan(false)
+	})
+	w.ShowAndRun()
+
+	d.Close()
+}
@@ -0,0 +1,182 @@
+//go:build !nogui
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/devmarvs/frago/internal/runner"
+	"github.com/devmarvs/frago/internal/updater"
+)
+
+// showBuilds lists the FrankenPHP releases available for this platform with
+// actions to download and remove them. changed runs after a build is
+// installed or removed.
+func showBuilds(w fyne.Window, builds *updater.Builds, changed func()) {
+	list := container.NewVBox(widget.NewLabel("Loading releases..."))
+	var releases []updater.ReleaseInfo
+	// installing is the tag being downloaded; one download runs at a time.
+	installing := ""
+	progress := widget.NewProgressBar()
+
+	var render func()
+	install := func(release updater.ReleaseInfo) {
+		installing = release.TagName
+		progress.SetValue(0)
+		render()
+		go func() {
+			_, err := builds.Install(context.Background(), release, func(done, total int64) {
+				if total > 0 {
+					fyne.Do(func() { progress.SetValue(float64(done) / float64(total)) })
+				}
+			})
+			fyne.Do(func() {
+				installing = ""
+				render()
+				if err != nil {
+					dialog.ShowError(err, w)
+					return
+				}
+				changed()
+			})
+		}()
+	}
+
+	render = func() {
+		list.Objects = nil
+		shown := 0
+		for _, r := range releases {
+			release := r
+			asset, err := updater.PlatformAsset(release, runtime.GOOS, runtime.GOARCH)
+			if err != nil {
+				continue
+			}
+			shown++
+			title := release.TagName
+			if release.Prerelease {
+				title += " (pre-release)"
+			}
+			label := widget.NewLabel(fmt.Sprintf("%s  %s", title, asset.Name))
+
+			var action fyne.CanvasObject
+			if _, installed := builds.Path(release.TagName); installed {
+				removeBtn := widget.NewButton("Remove", func() {
+					if err := builds.Remove(release.TagName); err != nil {
+						dialog.ShowError(err, w)
+						return
+					}
+					render()
+					changed()
+				})
+				removeBtn.Importance = widget.DangerImportance
+				action = container.NewHBox(widget.NewLabel("Installed"), removeBtn)
+			} else if installing == release.TagName {
+				action = container.NewGridWrap(fyne.NewSize(160, progress.MinSize().Height), progress)
+			} else {
+				installBtn := widget.NewButton("Download", func() {
+					install(release)
+				})
+				if installing != "" {
+					installBtn.Disable()
+				}
+				action = installBtn
+			}
+			list.Add(container.NewBorder(nil, nil, nil, action, label))
+		}
+		if shown == 0 {
+			list.Add(widget.NewLabel(fmt.Sprintf("No FrankenPHP releases for %s/%s.", runtime.GOOS, runtime.GOARCH)))
+		}
+		list.Refresh()
+	}
+
+	go func() {
+		fetched, err := builds.Client.Releases(context.Background())
+		fyne.Do(func() {
+			if err != nil {
+				list.Objects = []fyne.CanvasObject{widget.NewLabel("Could not load releases: " + err.Error())}
+				list.Refresh()
+				return
+			}
+			releases = fetched
+			render()
+		})
+	}()
+
+	info := widget.NewLabel(fmt.Sprintf("Builds are verified against their published checksums and stored in %s. Installed builds appear in every PHP Version list.", builds.Dir))
+	info.Wrapping = fyne.TextWrapWord
+	scroll := container.NewScroll(list)
+	scroll.SetMinSize(fyne.NewSize(0, 280))
+	d := dialog.NewCustom("FrankenPHP Builds", "Close", container.NewBorder(info, nil, nil, nil, scroll), w)
+	d.Resize(fyne.NewSize(640, 420))
+	d.Show()
+}
+
+// showBinaryInfo shows the versions, build and compiled PHP extensions of the
+// FrankenPHP binary at path. Binaries added by hand can be removed from the
+// dialog; removed is called afterwards.
+func showBinaryInfo(w fyne.Window, path string, removed func()) {
+	go func() {
+		info, err := runner.InspectBinary(path)
+		fyne.Do(func() {
+			if err != nil {
+				dialog.ShowError(fmt.Errorf("could not run %s: %w", path, err), w)
+				return
+			}
+			unknown := func(s string) string {
+				if s == "" {
+					return "unknown"
+				}
+				return s
+			}
+			threadSafety := "NTS (worker mode unavailable)"
+			if info.ZTS {
+				threadSafety = "ZTS"
+			}
+			platform := ""
+			if info.OS != "" && info.Arch != "" {
+				platform = info.OS + "/" + info.Arch
+			}
+			form := widget.NewForm(
+				widget.NewFormItem("Path", widget.NewLabel(info.Path)),
+				widget.NewFormItem("FrankenPHP", widget.NewLabel(unknown(info.FrankenPHP))),
+				widget.NewFormItem("PHP", widget.NewLabel(unknown(info.PHP)+", "+threadSafety)),
+				widget.NewFormItem("Caddy", widget.NewLabel(unknown(info.Caddy))),
+				widget.NewFormItem("Go", widget.NewLabel(unknown(info.Go))),
+				widget.NewFormItem("Platform", widget.NewLabel(unknown(platform))),
+			)
+			extensions := widget.NewLabel(strings.Join(info.Extensions, ", "))
+			if len(info.Extensions) == 0 {
+				extensions.SetText("Could not list the extensions (php-cli is unavailable).")
+			}
+			extensions.Wrapping = fyne.TextWrapWord
+			scroll := container.NewVScroll(extensions)
+			scroll.SetMinSize(fyne.NewSize(0, 140))
+			content := container.NewBorder(form, nil, nil, nil,
+				widget.NewCard("", fmt.Sprintf("PHP Extensions (%d)", len(info.Extensions)), scroll))
+			d := dialog.NewCustom("FrankenPHP Binary", "Close", content, w)
+			if detector := runner.DefaultDetector(); detector.IsCustomBinary(path) {
+				d.SetButtons([]fyne.CanvasObject{
+					widget.NewButton("Remove from Frago", func() {
+						d.Hide()
+						if err := detector.RemoveCustomBinary(path); err != nil {
+							dialog.ShowError(err, w)
+							return
+						}
+						removed()
+					}),
+					widget.NewButton("Close", d.Hide),
+				})
+			}
+			d.Resize(fyne.NewSize(560, 460))
+			d.Show()
+		})
+	}()
+}
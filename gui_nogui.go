@@ -0,0 +1,15 @@
+//go:build nogui
+
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+// runGUI reports that this build has no desktop window. Headless builds are
+// made with "go build -tags nogui" and do not link Fyne.
+func runGUI() {
+	fmt.Fprintf(os.Stderr, "%s %s was built without the desktop window.\nRun \"frago daemon\" to serve projects, or \"frago help\" for commands.\n", appName, appVersion)
+	os.Exit(2)
+}
@@ -0,0 +1,57 @@
+//go:build !nogui
+
+package main
+
+import (
+	"fmt"
+
+	"fyne.io/fyne/v2"
+
+	"github.com/devmarvs/frago/internal/service"
+	"github.com/devmarvs/frago/internal/store"
+)
+
+// prefsDoNotDisturbKey turns off every desktop notification.
+const prefsDoNotDisturbKey = "do_not_disturb"
+
+// notifyEvents shows a desktop notification for each crash, health failure,
+// PHP version mismatch or sidecar failure, unless do-not-disturb is on or
+// the project is muted.
+func notifyEvents(a fyne.App, svc *service.Service) {
+	svc.OnEvent(func(e service.Event) {
+		if a.Preferences().Bool(prefsDoNotDisturbKey) {
+			return
+		}
+		info, ok := svc.Projects().Get(e.Path)
+		if !ok {
+			info = store.Project{Path: e.Path}
+		}
+		if info.Muted {
+			return
+		}
+		n := eventNotification(info, e)
+		fyne.Do(func() {
+			a.SendNotification(n)
+		})
+	})
+}
+
+func eventNotification(info store.Project, e service.Event) *fyne.Notification {
+	title := fmt.Sprintf("%s crashed", info.DisplayName())
+	switch e.Kind {
+	case service.EventUnhealthy:
+		title = fmt.Sprintf("%s is unhealthy", info.DisplayName())
+	case service.EventPHPMismatch:
+		return fyne.NewNotification(fmt.Sprintf("%s uses the wrong PHP version", info.DisplayName()), e.Error)
+	case service.EventSidecarFailed:
+		title = fmt.Sprintf("Sidecar %s of %s failed", e.Sidecar, info.DisplayName())
+	}
+	content := e.LastLine
+	if content == "" {
+		content = e.Error
+	}
+	if content == "" {
+		content = info.Path
+	}
+	return fyne.NewNotification(title, content)
+}
@@ -0,0 +1,278 @@
+//go:build !nogui
+
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"sort"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/devmarvs/frago/internal/manifest"
+	"github.com/devmarvs/frago/internal/phpini"
+	"github.com/devmarvs/frago/internal/runner"
+	"github.com/devmarvs/frago/internal/service"
+	"github.com/devmarvs/frago/internal/store"
+)
+
+// phpIniPresetLabels maps php.ini presets to the choices shown in the
+// php.ini dialog, in display order.
+var phpIniPresetLabels = []struct {
+	preset string
+	label  string
+}{
+	{phpini.PresetNone, "Default"},
+	{phpini.PresetDevelopment, "Development"},
+	{phpini.PresetProduction, "Production-like"},
+}
+
+// phpIniSummary describes the php.ini settings saved for a project in a few
+// words.
+func phpIniSummary(info store.Project) string {
+	var parts []string
+	for _, p := range phpIniPresetLabels {
+		if p.preset != phpini.PresetNone && p.preset == info.PHPIniPreset {
+			parts = append(parts, p.label+" preset")
+		}
+	}
+	if n := len(info.PHPIni); n > 0 {
+		parts = append(parts, fmt.Sprintf("%d override(s)", n))
+	}
+	if len(parts) == 0 {
+		return "From manifest"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// showPHPIni edits the php.ini preset and overrides saved for a project and
+// lists the values that apply. done runs after they are saved.
+func showPHPIni(w fyne.Window, svc *service.Service, info store.Project, done func()) {
+	m, _ := manifest.Load(info.Path)
+
+	var presetOptions []string
+	presetLabel := phpIniPresetLabels[0].label
+	for _, p := range phpIniPresetLabels {
+		presetOptions = append(presetOptions, p.label)
+		if p.preset == info.PHPIniPreset {
+			presetLabel = p.label
+		}
+	}
+	presetSelect := widget.NewSelect(presetOptions, nil)
+	presetSelect.SetSelected(presetLabel)
+
+	overridesEntry := widget.NewMultiLineEntry()
+	overridesEntry.SetText(formatPHPIni(info.PHPIni))
+	overridesEntry.SetPlaceHolder("memory_limit = 512M\nxdebug.mode = debug")
+	overridesEntry.SetMinRowsVisible(5)
+
+	// pending returns the settings as entered, without saving them.
+	pending := func() (store.Project, error) {
+		updated := info
+		for _, p := range phpIniPresetLabels {
+			if p.label == presetSelect.Selected {
+				updated.PHPIniPreset = p.preset
+			}
+		}
+		values, err := parsePHPIni(overridesEntry.Text)
+		if err != nil {
+			return updated, err
+		}
+		updated.PHPIni = values
+		return updated, phpini.Validate(updated.PHPIniPreset, updated.PHPIni)
+	}
+
+	// The effective values follow the entries; "Check with PHP" adds the
+	// values PHP itself reports, which include php.ini files Frago did not
+	// write.
+	var settings []phpini.Setting
+	var reported map[string]string
+	table := widget.NewTable(
+		func() (int, int) { return len(settings) + 1, 4 },
+		func() fyne.CanvasObject { return widget.NewLabel("") },
+		func(id widget.TableCellID, o fyne.CanvasObject) {
+			label := o.(*widget.Label)
+			label.TextStyle = fyne.TextStyle{Bold: id.Row == 0}
+			if id.Row == 0 {
+				label.SetText([]string{"Setting", "Value", "From", "PHP reports"}[id.Col])
+				return
+			}
+			s := settings[id.Row-1]
+			text := ""
+			switch id.Col {
+			case 0:
+				text = s.Key
+			case 1:
+				text = s.Value
+			case 2:
+				text = s.Source
+			case 3:
+				if reported != nil {
+					text = "unknown setting"
+					if v, ok := reported[s.Key]; ok {
+						text = v
+					}
+				}
+			}
+			label.SetText(text)
+		},
+	)
+	for col, width := range []float32{200, 150, 130, 150} {
+		table.SetColumnWidth(col, width)
+	}
+	refreshTable := func() {
+		updated, err := pending()
+		if err != nil {
+			return
+		}
+		preset := m.PHPIniPreset
+		if updated.PHPIniPreset != phpini.PresetNone {
+			preset = updated.PHPIniPreset
+		}
+		settings = phpini.Effective(preset,
+			phpini.Layer{Name: service.PHPFromManifest, Values: m.PHPIni},
+			phpini.Layer{Name: service.PHPFromSettings, Values: updated.PHPIni},
+			service.XdebugLayer(info.Xdebug),
+		)
+		reported = nil
+		table.Refresh()
+	}
+	presetSelect.OnChanged = func(string) { refreshTable() }
+	overridesEntry.OnChanged = func(string) { refreshTable() }
+	refreshTable()
+
+	checkBtn := widget.NewButton("Check with PHP", nil)
+	checkBtn.OnTapped = func() {
+		binary := svc.StartBinary(info)
+		if binary == "" {
+			binary = runner.DefaultFrankenPHPBinary()
+			if found, err := exec.LookPath(binary); err == nil {
+				binary = found
+			}
+		}
+		checkBtn.Disable()
+		go func() {
+			// The saved settings are what the project starts with.
+			values, err := svc.QueryPHPIni(info.Path, binary)
+			fyne.Do(func() {
+				checkBtn.Enable()
+				if err != nil {
+					dialog.ShowError(fmt.Errorf("could not run %s: %w", binary, err), w)
+					return
+				}
+				reported = values
+				for _, key := range sortedKeys(values) {
+					if !containsSetting(settings, key) {
+						settings = append(settings, phpini.Setting{Key: key, Source: "php.ini"})
+					}
+				}
+				table.Refresh()
+			})
+		}()
+	}
+
+	tableScroll := container.NewScroll(table)
+	tableScroll.SetMinSize(fyne.NewSize(0, 200))
+	form := widget.NewForm(
+		&widget.FormItem{Text: "Preset", Widget: presetSelect, HintText: "Default keeps the php_ini_preset of the manifest, if any"},
+		&widget.FormItem{Text: "Overrides", Widget: overridesEntry, HintText: "key = value, one per line; extension = a, b loads extensions"},
+	)
+	effective := widget.NewCard("", "Effective values (Check with PHP uses the saved settings)",
+		container.NewBorder(nil, container.NewHBox(checkBtn), nil, nil, tableScroll))
+	content := container.NewBorder(form, nil, nil, nil, effective)
+
+	d := dialog.NewCustomConfirm("php.ini", "Save", "Cancel", content, func(save bool) {
+		if !save {
+			return
+		}
+		updated, err := pending()
+		if err != nil {
+			dialog.ShowError(err, w)
+			return
+		}
+		if _, err := svc.Projects().Update(info.Path, func(p *store.Project) {
+			p.PHPIniPreset = updated.PHPIniPreset
+			p.PHPIni = updated.PHPIni
+		}); err != nil {
+			dialog.ShowError(err, w)
+			return
+		}
+		done()
+
+		if _, running := svc.Manager().Get(info.Path); !running {
+			return
+		}
+		dialog.ShowConfirm("Restart Project", "Restart the project now to apply the new php.ini settings?", func(restart bool) {
+			if !restart {
+				return
+			}
+			go func() {
+				err := svc.Restart(info.Path)
+				fyne.Do(func() {
+					if err != nil {
+						dialog.ShowError(err, w)
+					}
+					done()
+				})
+			}()
+		}, w)
+	}, w)
+	d.Resize(fyne.NewSize(700, 600))
+	d.Show()
+}
+
+// formatPHPIni writes values as "key = value" lines.
+func formatPHPIni(values map[string]string) string {
+	lines := make([]string, 0, len(values))
+	for _, key := range sortedKeys(values) {
+		lines = append(lines, key+" = "+values[key])
+	}
+	return strings.Join(lines, "\n")
+}
+
+// parsePHPIni reads "key = value" lines, skipping blank lines and ; or #
+// comments. Quotes around a value are removed.
+func parsePHPIni(text string) (map[string]string, error) {
+	var values map[string]string
+	for i, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, ";") || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("php.ini line %d: expected key = value", i+1)
+		}
+		value = strings.TrimSpace(value)
+		if len(value) >= 2 && value[0] == '"' && value[len(value)-1] == '"' {
+			value = value[1 : len(value)-1]
+		}
+		if values == nil {
+			values = make(map[string]string)
+		}
+		values[strings.TrimSpace(key)] = value
+	}
+	return values, nil
+}
+
+func sortedKeys(values map[string]string) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func containsSetting(settings []phpini.Setting, key string) bool {
+	for _, s := range settings {
+		if s.Key == key {
+			return true
+		}
+	}
+	return false
+}
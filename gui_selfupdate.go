@@ -0,0 +1,153 @@
+//go:build !nogui
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/devmarvs/frago/internal/updater"
+)
+
+const (
+	// prefsWhatsNewVersionKey and prefsWhatsNewNotesKey hold the release
+	// notes of an installed update until the new version first starts.
+	prefsWhatsNewVersionKey = "whats_new_version"
+	prefsWhatsNewNotesKey   = "whats_new_notes"
+)
+
+// checkFragoUpdate looks for a newer Frago release and offers to install it.
+func checkFragoUpdate(a fyne.App, w fyne.Window) {
+	u, err := updater.NewSelfUpdate(appVersion)
+	if err != nil {
+		dialog.ShowError(err, w)
+		return
+	}
+	opts := updater.CheckOptions{Channel: updateOptions(a.Preferences()).Channel}
+	go func() {
+		release, err := u.Check(context.Background(), opts)
+		fyne.Do(func() {
+			switch {
+			case errors.Is(err, updater.ErrDevelopmentBuild):
+				dialog.ShowInformation("Frago Updates", "This is a development build of Frago, so it is not updated automatically.", w)
+			case err != nil:
+				dialog.ShowError(err, w)
+			case release == nil:
+				dialog.ShowInformation("Frago Updates", fmt.Sprintf("%s %s is up to date.", appName, appVersion), w)
+			default:
+				showFragoUpdate(a, w, u, release)
+			}
+		})
+	}()
+}
+
+// showFragoUpdate shows the notes of release and installs it on request.
+func showFragoUpdate(a fyne.App, w fyne.Window, u *updater.SelfUpdate, release *updater.ReleaseInfo) {
+	title := fmt.Sprintf("Frago %s is available (you have %s)", release.TagName, appVersion)
+	dialog.ShowCustomConfirm(title, "Install", "Later", releaseNotes(release.Body), func(install bool) {
+		if !install {
+			return
+		}
+		progress := widget.NewProgressBar()
+		busy := dialog.NewCustomWithoutButtons("Updating Frago", progress, w)
+		busy.Show()
+		go func() {
+			err := u.Apply(context.Background(), *release, func(done, total int64) {
+				if total > 0 {
+					fyne.Do(func() { progress.SetValue(float64(done) / float64(total)) })
+				}
+			})
+			fyne.Do(func() {
+				busy.Hide()
+				if err != nil {
+					dialog.ShowError(err, w)
+					return
+				}
+				a.Preferences().SetString(prefsWhatsNewVersionKey, release.TagName)
+				a.Preferences().SetString(prefsWhatsNewNotesKey, release.Body)
+				message := fmt.Sprintf("Frago %s is installed and runs the next time Frago starts. Restart now? Running projects are stopped.", release.TagName)
+				dialog.ShowConfirm("Update Installed", message, func(restart bool) {
+					if restart {
+						restartFrago(a, w, u.Executable)
+					}
+				}, w)
+			})
+		}()
+	}, w)
+}
+
+// restartFrago starts exe and quits this instance.
+func restartFrago(a fyne.App, w fyne.Window, exe string) {
+	if err := exec.Command(exe).Start(); err != nil {
+		dialog.ShowError(err, w)
+		return
+	}
+	a.Quit()
+}
+
+// rollbackFrago restores the binary replaced by the last update.
+func rollbackFrago(a fyne.App, w fyne.Window) {
+	u, err := updater.NewSelfUpdate(appVersion)
+	if err != nil {
+		dialog.ShowError(err, w)
+		return
+	}
+	if !u.CanRollback() {
+		dialog.ShowInformation("Roll Back Update", "There is no previous version of Frago to go back to.", w)
+		return
+	}
+	dialog.ShowConfirm("Roll Back Update", "Replace this version of Frago with the one it was updated from?", func(ok bool) {
+		if !ok {
+			return
+		}
+		if err := u.Rollback(); err != nil {
+			dialog.ShowError(err, w)
+			return
+		}
+		a.Preferences().RemoveValue(prefsWhatsNewVersionKey)
+		a.Preferences().RemoveValue(prefsWhatsNewNotesKey)
+		dialog.ShowConfirm("Update Rolled Back", "The previous version runs the next time Frago starts. Restart now? Running projects are stopped.", func(restart bool) {
+			if restart {
+				restartFrago(a, w, u.Executable)
+			}
+		}, w)
+	}, w)
+}
+
+// showWhatsNew shows the notes saved when this version was installed, once.
+func showWhatsNew(a fyne.App, w fyne.Window) {
+	prefs := a.Preferences()
+	installed, err := updater.ParseVersion(prefs.String(prefsWhatsNewVersionKey))
+	if err != nil {
+		return
+	}
+	running, err := updater.ParseVersion(appVersion)
+	if err != nil || running.Compare(installed) != 0 {
+		return
+	}
+	notes := prefs.String(prefsWhatsNewNotesKey)
+	prefs.RemoveValue(prefsWhatsNewVersionKey)
+	prefs.RemoveValue(prefsWhatsNewNotesKey)
+	d := dialog.NewCustom(fmt.Sprintf("What's New in Frago %s", installed), "Close", releaseNotes(notes), w)
+	d.Resize(fyne.NewSize(560, 420))
+	d.Show()
+}
+
+// releaseNotes renders Markdown release notes in a scrollable area.
+func releaseNotes(body string) fyne.CanvasObject {
+	if body == "" {
+		body = "No release notes were published for this version."
+	}
+	notes := widget.NewRichTextFromMarkdown(body)
+	notes.Wrapping = fyne.TextWrapWord
+	scroll := container.NewVScroll(notes)
+	scroll.SetMinSize(fyne.NewSize(480, 260))
+	return scroll
+}
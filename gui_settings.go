@@ -0,0 +1,334 @@
+//go:build !nogui
+
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/devmarvs/frago/internal/manifest"
+	"github.com/devmarvs/frago/internal/service"
+	"github.com/devmarvs/frago/internal/store"
+)
+
+// restartPolicyLabels maps restart policies to the choices shown in the
+// settings dialog, in display order.
+var restartPolicyLabels = []struct {
+	policy string
+	label  string
+}{
+	{store.RestartNever, "Never"},
+	{store.RestartOnFailure, "On failure"},
+	{store.RestartAlways, "Always"},
+}
+
+// showProjectSettings edits the saved settings of a project. versionOptions
+// and versionMap are the binaries offered by the New Project form. done runs
+// after the settings are saved.
+func showProjectSettings(w fyne.Window, svc *service.Service, info store.Project, versionOptions []string, versionMap map[string]string, done func()) {
+	// The manifest shows what applies when a field is left empty.
+	m, _ := manifest.Load(info.Path)
+
+	nameEntry := widget.NewEntry()
+	nameEntry.SetText(info.Name)
+	nameEntry.SetPlaceHolder("Optional (shown instead of the path)")
+
+	tagsEntry := widget.NewEntry()
+	tagsEntry.SetText(strings.Join(info.Tags, ", "))
+	tagsEntry.SetPlaceHolder("e.g. laravel, client-a")
+
+	portEntry := widget.NewEntry()
+	if info.PreferredPort > 0 {
+		portEntry.SetText(strconv.Itoa(info.PreferredPort))
+	}
+	portEntry.SetPlaceHolder(placeholder("Automatic", m.Port > 0, strconv.Itoa(m.Port)))
+
+	versionSelect := widget.NewSelect(versionOptions, nil)
+	binaryEntry := widget.NewEntry()
+	binaryEntry.SetPlaceHolder("Optional path to a FrankenPHP binary")
+	versionSelect.SetSelected(versionOptions[0])
+	if info.LastBinaryPath == "" {
+		for _, option := range versionOptions {
+			if option == info.LastVersionLabel {
+				versionSelect.SetSelected(option)
+			}
+		}
+	} else {
+		binaryEntry.SetText(info.LastBinaryPath)
+		for _, option := range versionOptions {
+			if versionMap[option] == info.LastBinaryPath {
+				versionSelect.SetSelected(option)
+				binaryEntry.SetText("")
+			}
+		}
+	}
+
+	// The PHP constraint overrides the one from the manifest or
+	// composer.json; the label below it names the binary it picks.
+	inheritedPHP, inheritedFrom := m.PHP, "manifest"
+	if inheritedPHP == "" {
+		inheritedPHP, _ = manifest.ComposerPHP(info.Path)
+		inheritedFrom = manifest.ComposerFile
+	}
+	phpEntry := widget.NewEntry()
+	phpEntry.SetText(info.PHP)
+	phpEntry.SetPlaceHolder("e.g. ^8.3")
+	if inheritedPHP != "" {
+		phpEntry.SetPlaceHolder(fmt.Sprintf("From %s: %s", inheritedFrom, inheritedPHP))
+	}
+	phpMatchLabel := widget.NewLabel("")
+	phpMatchLabel.Wrapping = fyne.TextWrapWord
+	phpEntry.OnChanged = func(text string) {
+		constraint := strings.TrimSpace(text)
+		if constraint == "" {
+			constraint = inheritedPHP
+		}
+		phpMatchLabel.SetText(phpMatchHint(svc, constraint))
+	}
+	phpEntry.OnChanged(phpEntry.Text)
+
+	docrootEntry := widget.NewEntry()
+	docrootEntry.SetText(info.Docroot)
+	docrootEntry.SetPlaceHolder(placeholder("Project directory", m.Docroot != "", m.Docroot))
+
+	healthEntry := widget.NewEntry()
+	healthEntry.SetText(info.HealthPath)
+	healthEntry.SetPlaceHolder(placeholder("/", m.HealthPath != "", m.HealthPath))
+
+	envEntry := widget.NewMultiLineEntry()
+	envEntry.SetText(formatEnv(info.Env))
+	envEntry.SetPlaceHolder("KEY=value, one per line")
+	envEntry.SetMinRowsVisible(4)
+
+	var restartOptions []string
+	restartLabel := restartPolicyLabels[0].label
+	for _, p := range restartPolicyLabels {
+		restartOptions = append(restartOptions, p.label)
+		if p.policy == info.RestartPolicy {
+			restartLabel = p.label
+		}
+	}
+	restartSelect := widget.NewSelect(restartOptions, nil)
+	restartSelect.SetSelected(restartLabel)
+
+	phpIniBtn := widget.NewButton(phpIniSummary(info)+"…", func() {
+		showPHPIni(w, svc, info, done)
+	})
+
+	// Xdebug itself is switched on from the project row; these settings say
+	// where the IDE listens and when to debug.
+	xdebugClientEntry := widget.NewEntry()
+	xdebugClientEntry.SetText(formatXdebugClient(info.Xdebug))
+	xdebugClientEntry.SetPlaceHolder("localhost:9003")
+	xdebugTriggerCheck := widget.NewCheck("Only requests with XDEBUG_TRIGGER or XDEBUG_SESSION", nil)
+	xdebugTriggerCheck.SetChecked(info.Xdebug.Trigger)
+
+	muteCheck := widget.NewCheck("Mute crash and health alerts", nil)
+	muteCheck.SetChecked(info.Muted)
+
+	items := []*widget.FormItem{
+		{Text: "Display name", Widget: nameEntry},
+		{Text: "Tags", Widget: tagsEntry, HintText: "Comma-separated; search with tag:NAME"},
+		{Text: "Port", Widget: portEntry},
+		{Text: "PHP Constraint", Widget: container.NewVBox(phpEntry, phpMatchLabel), HintText: "Picks the newest matching binary, e.g. ^8.3 or >=8.2, <8.4"},
+		{Text: "PHP Version", Widget: versionSelect, HintText: "Leave on the default to use the constraint"},
+		{Text: "Binary", Widget: binaryEntry, HintText: "Overrides the PHP version when set"},
+		{Text: "Docroot", Widget: docrootEntry, HintText: "Relative to the project directory"},
+		{Text: "Health path", Widget: healthEntry},
+		{Text: "Env vars", Widget: envEntry, HintText: "Override the project manifest"},
+		{Text: "php.ini", Widget: phpIniBtn, HintText: "Preset and overrides, saved separately"},
+		{Text: "Xdebug client", Widget: xdebugClientEntry, HintText: "host:port of the IDE"},
+		{Text: "Xdebug trigger", Widget: xdebugTriggerCheck},
+		{Text: "Restart", Widget: restartSelect, HintText: "When the process exits without being stopped"},
+		{Text: "Notifications", Widget: muteCheck},
+	}
+
+	form := dialog.NewForm("Project Settings", "Save", "Cancel", items, func(save bool) {
+		if !save {
+			return
+		}
+
+		updated := info
+		updated.Name = strings.TrimSpace(nameEntry.Text)
+		updated.Tags = store.NormalizeTags([]string{tagsEntry.Text})
+		updated.PreferredPort = 0
+		if text := strings.TrimSpace(portEntry.Text); text != "" {
+			port, err := strconv.Atoi(text)
+			if err != nil {
+				dialog.ShowError(fmt.Errorf("port %q is not a number", text), w)
+				return
+			}
+			updated.PreferredPort = port
+		}
+		updated.LastBinaryPath = versionMap[versionSelect.Selected]
+		updated.LastVersionLabel = versionSelect.Selected
+		if strings.HasPrefix(updated.LastVersionLabel, defaultVersionLabel) {
+			updated.LastVersionLabel = ""
+		}
+		if binary := strings.TrimSpace(binaryEntry.Text); binary != "" {
+			updated.LastBinaryPath = binary
+			updated.LastVersionLabel = ""
+		}
+		updated.PHP = strings.TrimSpace(phpEntry.Text)
+		updated.Docroot = strings.TrimSpace(docrootEntry.Text)
+		updated.HealthPath = strings.TrimSpace(healthEntry.Text)
+		env, err := parseEnv(envEntry.Text)
+		if err != nil {
+			dialog.ShowError(err, w)
+			return
+		}
+		updated.Env = env
+		for _, p := range restartPolicyLabels {
+			if p.label == restartSelect.Selected {
+				updated.RestartPolicy = p.policy
+			}
+		}
+
+		host, port, err := parseXdebugClient(xdebugClientEntry.Text)
+		if err != nil {
+			dialog.ShowError(err, w)
+			return
+		}
+		updated.Xdebug.ClientHost = host
+		updated.Xdebug.ClientPort = port
+		updated.Xdebug.Trigger = xdebugTriggerCheck.Checked
+
+		updated.Muted = muteCheck.Checked
+
+		if err := svc.ValidateSettings(updated); err != nil {
+			dialog.ShowError(err, w)
+			return
+		}
+		if _, err := svc.Projects().Update(info.Path, func(p *store.Project) {
+			p.Name = updated.Name
+			p.Tags = updated.Tags
+			p.PreferredPort = updated.PreferredPort
+			p.LastBinaryPath = updated.LastBinaryPath
+			p.LastVersionLabel = updated.LastVersionLabel
+			p.PHP = updated.PHP
+			p.Docroot = updated.Docroot
+			p.HealthPath = updated.HealthPath
+			p.Env = updated.Env
+			p.RestartPolicy = updated.RestartPolicy
+			p.Xdebug.ClientHost = updated.Xdebug.ClientHost
+			p.Xdebug.ClientPort = updated.Xdebug.ClientPort
+			p.Xdebug.Trigger = updated.Xdebug.Trigger
+			p.Muted = updated.Muted
+		}); err != nil {
+			dialog.ShowError(err, w)
+			return
+		}
+		done()
+
+		if _, running := svc.Manager().Get(info.Path); !running {
+			return
+		}
+		dialog.ShowConfirm("Restart Project", "Restart the project now to apply the new settings?", func(restart bool) {
+			if !restart {
+				return
+			}
+			// Restart may wait seconds for sidecars to exit.
+			go func() {
+				err := svc.Restart(info.Path)
+				fyne.Do(func() {
+					if err != nil {
+						dialog.ShowError(err, w)
+					}
+					done()
+				})
+			}()
+		}, w)
+	}, w)
+	form.Resize(fyne.NewSize(620, 560))
+	form.Show()
+}
+
+// placeholder returns "From manifest: value" when the manifest sets a value
+// and fallback otherwise.
+func placeholder(fallback string, fromManifest bool, value string) string {
+	if fromManifest {
+		return "From manifest: " + value
+	}
+	return fallback
+}
+
+// formatEnv renders env as sorted KEY=value lines.
+func formatEnv(env map[string]string) string {
+	keys := make([]string, 0, len(env))
+	for key := range env {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	lines := make([]string, 0, len(keys))
+	for _, key := range keys {
+		lines = append(lines, key+"="+env[key])
+	}
+	return strings.Join(lines, "\n")
+}
+
+// parseEnv reads KEY=value lines, skipping blank lines and # comments.
+func parseEnv(text string) (map[string]string, error) {
+	var env map[string]string
+	for i, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("env line %d: expected KEY=value", i+1)
+		}
+		if env == nil {
+			env = make(map[string]string)
+		}
+		env[strings.TrimSpace(key)] = value
+	}
+	return env, nil
+}
+
+// formatXdebugClient renders the Xdebug client as host:port, leaving out
+// what is unset.
+func formatXdebugClient(x store.Xdebug) string {
+	if x.ClientPort == 0 {
+		return x.ClientHost
+	}
+	return x.ClientHost + ":" + strconv.Itoa(x.ClientPort)
+}
+
+// parseXdebugClient reads "host", "host:port" or ":port". Missing parts are
+// returned empty so Xdebug's defaults apply.
+func parseXdebugClient(text string) (string, int, error) {
+	text = strings.TrimSpace(text)
+	i := strings.LastIndex(text, ":")
+	if i < 0 || strings.HasSuffix(text, "]") {
+		return text, 0, nil
+	}
+	port, err := strconv.Atoi(text[i+1:])
+	if err != nil {
+		return "", 0, fmt.Errorf("xdebug client port %q is not a number", text[i+1:])
+	}
+	return text[:i], port, nil
+}
+
+// phpMatchHint describes the binary constraint picks, or warns when no
+// detected binary satisfies it.
+func phpMatchHint(svc *service.Service, constraint string) string {
+	if constraint == "" {
+		return ""
+	}
+	if _, err := manifest.ParseConstraint(constraint); err != nil {
+		return err.Error()
+	}
+	match, err := svc.MatchPHP(constraint)
+	if err != nil {
+		return "⚠ No installed binary matches " + constraint
+	}
+	return "Uses " + match.Label
+}
@@ -0,0 +1,107 @@
+//go:build !nogui
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/devmarvs/frago/internal/service"
+	"github.com/devmarvs/frago/internal/store"
+)
+
+// showExportProjects saves the project list and workspaces to a JSON or TOML
+// file, chosen by the file extension.
+func showExportProjects(w fyne.Window, projects *store.Store) {
+	save := dialog.NewFileSave(func(writer fyne.URIWriteCloser, err error) {
+		if err != nil {
+			dialog.ShowError(err, w)
+			return
+		}
+		if writer == nil {
+			return
+		}
+		defer writer.Close()
+		data, err := store.EncodeExport(projects.Export(), store.ExportFileFormat(writer.URI().Path()))
+		if err == nil {
+			_, err = writer.Write(data)
+		}
+		if err != nil {
+			dialog.ShowError(err, w)
+		}
+	}, w)
+	save.SetFileName("frago-projects.json")
+	save.Show()
+}
+
+// showImportProjects reads an export and asks how paths from the exporting
+// machine map to this one before adding the projects.
+func showImportProjects(w fyne.Window, projects *store.Store) {
+	open := dialog.NewFileOpen(func(reader fyne.URIReadCloser, err error) {
+		if err != nil {
+			dialog.ShowError(err, w)
+			return
+		}
+		if reader == nil {
+			return
+		}
+		defer reader.Close()
+		data, err := io.ReadAll(reader)
+		if err != nil {
+			dialog.ShowError(err, w)
+			return
+		}
+		e, err := store.DecodeExport(data, store.ExportFileFormat(reader.URI().Path()))
+		if err != nil {
+			dialog.ShowError(err, w)
+			return
+		}
+		showImportRemap(w, projects, e)
+	}, w)
+	open.Show()
+}
+
+func showImportRemap(w fyne.Window, projects *store.Store, e store.Export) {
+	fromEntry := widget.NewEntry()
+	fromEntry.SetText(e.Home)
+	fromEntry.SetPlaceHolder("e.g. /home/alice/sites")
+	toEntry := widget.NewEntry()
+	if home, err := os.UserHomeDir(); err == nil {
+		toEntry.SetText(home)
+	}
+
+	items := []*widget.FormItem{
+		{Text: "Projects", Widget: widget.NewLabel(fmt.Sprintf("%d projects, %d workspaces", len(e.Projects), len(e.Workspaces)))},
+		{Text: "Replace path", Widget: fromEntry, HintText: "Leave empty to keep paths as exported"},
+		{Text: "With", Widget: toEntry},
+	}
+	dialog.ShowForm("Import Projects", "Import", "Cancel", items, func(ok bool) {
+		if !ok {
+			return
+		}
+		from := strings.TrimSpace(fromEntry.Text)
+		to := strings.TrimSpace(toEntry.Text)
+		if from != "" && to != "" {
+			e = e.Remap(from, to)
+		}
+		summary, err := projects.Import(e, service.ValidateProject)
+		if err != nil {
+			dialog.ShowError(err, w)
+			return
+		}
+		text := fmt.Sprintf("Added %d and updated %d projects.\nImported %d workspaces.", summary.Added, summary.Updated, summary.Workspaces)
+		if len(summary.Skipped) > 0 {
+			text += "\n\nThese projects were skipped:\n" + strings.Join(summary.Skipped, "\n")
+		}
+		if len(summary.DroppedBinaryPath) > 0 {
+			text += "\n\nThese binaries do not exist here; a detected binary is used instead:\n" + strings.Join(summary.DroppedBinaryPath, "\n")
+		}
+		dialog.ShowInformation("Import Projects", text, w)
+	}, w)
+}
@@ -0,0 +1,316 @@
+//go:build !nogui
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/devmarvs/frago/internal/service"
+	"github.com/devmarvs/frago/internal/updater"
+)
+
+const (
+	// prefsUpdateChannelKey holds the updater.Channel used by update checks.
+	prefsUpdateChannelKey = "update_channel"
+	// prefsSkippedUpdatesKey lists the release tags the user chose to skip.
+	prefsSkippedUpdatesKey = "skipped_updates"
+	// prefsReleaseSourceKey holds the release source as KIND:LOCATION;
+	// FRAGO_RELEASE_SOURCE applies when it is empty.
+	prefsReleaseSourceKey = "release_source"
+	// prefsUpdateIntervalKey is how often updates are checked in the
+	// background: "daily" (the default), "weekly" or "never".
+	prefsUpdateIntervalKey = "update_check_interval"
+	// prefsLastUpdateCheckKey is the Unix time of the last background check.
+	prefsLastUpdateCheckKey = "last_update_check"
+	// prefsNotifiedUpdatesKey lists the releases already announced in a
+	// notification, so each is announced once.
+	prefsNotifiedUpdatesKey = "notified_updates"
+)
+
+var updateIntervals = []struct {
+	key, label string
+	every      time.Duration
+}{
+	{"daily", "Daily", 24 * time.Hour},
+	{"weekly", "Weekly", 7 * 24 * time.Hour},
+	{"never", "Never", 0},
+}
+
+var sourceKindLabels = []struct {
+	kind        updater.SourceKind
+	label, hint string
+}{
+	{updater.SourceGitHub, "GitHub API", updater.GitHubAPI},
+	{updater.SourceFeed, "JSON feed", "https://mirror.example.com/frankenphp/releases.json"},
+	{updater.SourceDir, "Local directory", "/srv/frankenphp (one folder per version)"},
+}
+
+var updateChannelLabels = map[updater.Channel]string{
+	updater.ChannelStable:     "Stable",
+	updater.ChannelPrerelease: "Pre-release",
+}
+
+// updateOptions returns the update check options saved in prefs.
+func updateOptions(prefs fyne.Preferences) updater.CheckOptions {
+	channel := updater.ChannelStable
+	if updater.Channel(prefs.String(prefsUpdateChannelKey)) == updater.ChannelPrerelease {
+		channel = updater.ChannelPrerelease
+	}
+	return updater.CheckOptions{Channel: channel, Skip: prefs.StringList(prefsSkippedUpdatesKey)}
+}
+
+// releaseClient returns a client for the release source saved in prefs.
+func releaseClient(prefs fyne.Preferences) (*updater.Client, error) {
+	src, err := updater.SourceFromEnv()
+	if saved := prefs.String(prefsReleaseSourceKey); saved != "" {
+		src, err = updater.ParseSource(saved)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return updater.NewClientFor(src), nil
+}
+
+// updateInterval returns how often background checks run; 0 turns them off.
+func updateInterval(prefs fyne.Preferences) time.Duration {
+	key := prefs.String(prefsUpdateIntervalKey)
+	for _, i := range updateIntervals {
+		if i.key == key {
+			return i.every
+		}
+	}
+	return updateIntervals[0].every
+}
+
+// scheduleUpdateChecks checks Frago and the detected binaries for updates
+// in the background whenever the interval saved in prefs has passed, and
+// announces each new release once in a desktop notification.
+func scheduleUpdateChecks(a fyne.App, svc *service.Service) {
+	prefs := a.Preferences()
+	notify := func(key, title, content string) {
+		notified := prefs.StringList(prefsNotifiedUpdatesKey)
+		if slices.Contains(notified, key) || prefs.Bool(prefsDoNotDisturbKey) {
+			return
+		}
+		prefs.SetStringList(prefsNotifiedUpdatesKey, append(notified, key))
+		n := fyne.NewNotification(title, content)
+		fyne.Do(func() {
+			a.SendNotification(n)
+		})
+	}
+
+	check := func() {
+		every := updateInterval(prefs)
+		last := time.Unix(int64(prefs.Int(prefsLastUpdateCheckKey)), 0)
+		if every == 0 || time.Since(last) < every {
+			return
+		}
+		client, err := releaseClient(prefs)
+		if err != nil {
+			return
+		}
+		opts := updateOptions(prefs)
+		updates, err := client.Check(context.Background(), svc.Versions(), opts)
+		if err != nil {
+			return
+		}
+		prefs.SetInt(prefsLastUpdateCheckKey, int(time.Now().Unix()))
+
+		// Binaries offered the same release share one notification.
+		byTag := make(map[string][]string)
+		var tags []string
+		for _, u := range updates {
+			if u.Release == nil {
+				continue
+			}
+			if _, ok := byTag[u.Release.TagName]; !ok {
+				tags = append(tags, u.Release.TagName)
+			}
+			byTag[u.Release.TagName] = append(byTag[u.Release.TagName], u.Label)
+		}
+		for _, tag := range tags {
+			content := fmt.Sprintf("%s is available for %s.", tag, byTag[tag][0])
+			if n := len(byTag[tag]); n > 1 {
+				content = fmt.Sprintf("%s is available for %d FrankenPHP binaries.", tag, n)
+			}
+			notify(tag, "FrankenPHP Update Available", content)
+		}
+
+		if u, err := updater.NewSelfUpdate(appVersion); err == nil {
+			release, err := u.Check(context.Background(), updater.CheckOptions{Channel: opts.Channel})
+			if err == nil && release != nil {
+				notify("frago:"+release.TagName, "Frago Update Available",
+					fmt.Sprintf("Frago %s is available. Use Frago › Check for Frago Updates… to install it.", release.TagName))
+			}
+		}
+	}
+
+	go func() {
+		ticker := time.NewTicker(time.Hour)
+		defer ticker.Stop()
+		for {
+			check()
+			<-ticker.C
+		}
+	}()
+}
+
+// showUpdateSettings edits where releases come from and how often they are
+// checked in the background.
+func showUpdateSettings(w fyne.Window, prefs fyne.Preferences) {
+	current, err := updater.SourceFromEnv()
+	if saved := prefs.String(prefsReleaseSourceKey); saved != "" {
+		current, err = updater.ParseSource(saved)
+	}
+	if err != nil {
+		current = updater.DefaultSource
+	}
+
+	locationEntry := widget.NewEntry()
+	locationEntry.SetText(current.Location)
+	kindLabels := make([]string, len(sourceKindLabels))
+	for i, k := range sourceKindLabels {
+		kindLabels[i] = k.label
+	}
+	kindSelect := widget.NewSelect(kindLabels, func(label string) {
+		for _, k := range sourceKindLabels {
+			if k.label == label {
+				locationEntry.SetPlaceHolder(k.hint)
+			}
+		}
+	})
+	for _, k := range sourceKindLabels {
+		if k.kind == current.Kind {
+			kindSelect.SetSelected(k.label)
+		}
+	}
+
+	intervalLabels := make([]string, len(updateIntervals))
+	for i, interval := range updateIntervals {
+		intervalLabels[i] = interval.label
+	}
+	intervalSelect := widget.NewSelect(intervalLabels, nil)
+	for _, interval := range updateIntervals {
+		if interval.every == updateInterval(prefs) {
+			intervalSelect.SetSelected(interval.label)
+		}
+	}
+
+	items := []*widget.FormItem{
+		widget.NewFormItem("Release Source", kindSelect),
+		widget.NewFormItem("Location", locationEntry),
+		widget.NewFormItem("Check Automatically", intervalSelect),
+	}
+	dialog.ShowForm("Update Settings", "Save", "Cancel", items, func(save bool) {
+		if !save {
+			return
+		}
+		var kind updater.SourceKind
+		for _, k := range sourceKindLabels {
+			if k.label == kindSelect.Selected {
+				kind = k.kind
+			}
+		}
+		location := strings.TrimSpace(locationEntry.Text)
+		if kind == updater.SourceGitHub && location == "" {
+			location = updater.GitHubAPI
+		}
+		src, err := updater.ParseSource(string(kind) + ":" + location)
+		if err != nil {
+			dialog.ShowError(err, w)
+			return
+		}
+		if src != current {
+			prefs.SetString(prefsReleaseSourceKey, src.String())
+		}
+		for _, interval := range updateIntervals {
+			if interval.label == intervalSelect.Selected {
+				prefs.SetString(prefsUpdateIntervalKey, interval.key)
+			}
+		}
+	}, w)
+}
+
+// showUpdates lists the result of an update check for each binary, with
+// actions to open a release and to skip it. recheck runs the check again
+// after the channel or the skipped versions change.
+func showUpdates(w fyne.Window, prefs fyne.Preferences, updates []updater.Update, recheck func()) {
+	var d *dialog.CustomDialog
+	rerun := func() {
+		d.Hide()
+		recheck()
+	}
+
+	list := container.NewVBox()
+	for _, u := range updates {
+		update := u
+		var status string
+		var action fyne.CanvasObject
+		switch {
+		case update.Err != nil:
+			status = "version unknown"
+		case update.Release == nil:
+			status = update.Current + ", up to date"
+		default:
+			status = fmt.Sprintf("%s → %s", update.Current, update.Release.TagName)
+			notesBtn := widget.NewButton("Release Notes", func() {
+				updater.OpenUpdatePage(update.Release.HtmlUrl)
+			})
+			var skipBtn *widget.Button
+			skipBtn = widget.NewButton("Skip This Version", func() {
+				skipped := prefs.StringList(prefsSkippedUpdatesKey)
+				prefs.SetStringList(prefsSkippedUpdatesKey, append(skipped, update.Release.TagName))
+				rerun()
+			})
+			action = container.NewHBox(notesBtn, skipBtn)
+		}
+		label := widget.NewLabel(fmt.Sprintf("%s\n%s", update.Label, status))
+		if action == nil {
+			list.Add(label)
+		} else {
+			list.Add(container.NewBorder(nil, nil, nil, action, label))
+		}
+	}
+	if len(updates) == 0 {
+		list.Add(widget.NewLabel("No FrankenPHP binaries were found."))
+	}
+
+	opts := updateOptions(prefs)
+	channelSelect := widget.NewSelect([]string{
+		updateChannelLabels[updater.ChannelStable],
+		updateChannelLabels[updater.ChannelPrerelease],
+	}, nil)
+	channelSelect.SetSelected(updateChannelLabels[opts.Channel])
+	channelSelect.OnChanged = func(label string) {
+		for channel, l := range updateChannelLabels {
+			if l == label && channel != opts.Channel {
+				prefs.SetString(prefsUpdateChannelKey, string(channel))
+				rerun()
+			}
+		}
+	}
+	footer := container.NewHBox(widget.NewLabel("Channel"), channelSelect, widget.NewButton("Settings…", func() {
+		showUpdateSettings(w, prefs)
+	}))
+	if len(opts.Skip) > 0 {
+		footer.Add(widget.NewButton(fmt.Sprintf("Unskip %d Version(s)", len(opts.Skip)), func() {
+			prefs.RemoveValue(prefsSkippedUpdatesKey)
+			rerun()
+		}))
+	}
+
+	scroll := container.NewScroll(list)
+	scroll.SetMinSize(fyne.NewSize(0, 200))
+	d = dialog.NewCustom("FrankenPHP Updates", "Close", container.NewBorder(nil, footer, nil, nil, scroll), w)
+	d.Resize(fyne.NewSize(600, 360))
+	d.Show()
+}
@@ -0,0 +1,175 @@
+//go:build !nogui
+
+package main
+
+import (
+	"fmt"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/devmarvs/frago/internal/store"
+)
+
+// showWorkspaces lists the workspaces with actions to add, edit and delete
+// them.
+func showWorkspaces(w fyne.Window, projects *store.Store) {
+	list := container.NewVBox()
+	var refresh func()
+	refresh = func() {
+		list.Objects = nil
+		workspaces := projects.Workspaces()
+		if len(workspaces) == 0 {
+			list.Add(widget.NewLabel("No workspaces yet."))
+		}
+		for _, ws := range workspaces {
+			wsCopy := ws
+			label := widget.NewLabel(fmt.Sprintf("%s (%d projects)", ws.Name, len(ws.Projects)))
+			editBtn := widget.NewButton("Edit", func() {
+				showWorkspaceEditor(w, projects, &wsCopy, refresh)
+			})
+			deleteBtn := widget.NewButton("Delete", func() {
+				dialog.ShowConfirm("Delete Workspace", fmt.Sprintf("Delete workspace %q? Its projects stay saved.", wsCopy.Name), func(confirm bool) {
+					if !confirm {
+						return
+					}
+					if err := projects.DeleteWorkspace(wsCopy.ID); err != nil {
+						dialog.ShowError(err, w)
+					}
+					refresh()
+				}, w)
+			})
+			deleteBtn.Importance = widget.DangerImportance
+			list.Add(container.NewBorder(nil, nil, nil, container.NewHBox(editBtn, deleteBtn), label))
+		}
+		list.Refresh()
+	}
+	refresh()
+
+	addBtn := widget.NewButton("New Workspace", func() {
+		showWorkspaceEditor(w, projects, nil, refresh)
+	})
+	addBtn.Importance = widget.HighImportance
+
+	scroll := container.NewScroll(list)
+	scroll.SetMinSize(fyne.NewSize(0, 220))
+	content := container.NewBorder(nil, container.NewHBox(layout.NewSpacer(), addBtn), nil, nil, scroll)
+	d := dialog.NewCustom("Workspaces", "Close", content, w)
+	d.Resize(fyne.NewSize(560, 360))
+	d.Show()
+}
+
+// showWorkspaceEditor edits ws, or creates a workspace when ws is nil. Every
+// saved project can be ticked as a member; members start from top to bottom.
+func showWorkspaceEditor(w fyne.Window, projects *store.Store, ws *store.Workspace, done func()) {
+	nameEntry := widget.NewEntry()
+	nameEntry.SetPlaceHolder("e.g. Shop")
+
+	// order holds members first, in start order, then the other projects.
+	var order []string
+	member := make(map[string]bool)
+	if ws != nil {
+		nameEntry.SetText(ws.Name)
+		for _, path := range ws.Projects {
+			order = append(order, path)
+			member[path] = true
+		}
+	}
+	for _, p := range projects.Sorted() {
+		if !member[p.Path] {
+			order = append(order, p.Path)
+		}
+	}
+
+	rows := container.NewVBox()
+	var render func()
+	move := func(i, delta int) {
+		j := i + delta
+		if j < 0 || j >= len(order) || !member[order[j]] {
+			return
+		}
+		order[i], order[j] = order[j], order[i]
+		render()
+	}
+	render = func() {
+		rows.Objects = nil
+		// Keep members on top so their position is their start order.
+		var members, others []string
+		for _, path := range order {
+			if member[path] {
+				members = append(members, path)
+			} else {
+				others = append(others, path)
+			}
+		}
+		order = append(members, others...)
+
+		if len(order) == 0 {
+			rows.Add(widget.NewLabel("No saved projects yet."))
+		}
+		for i, path := range order {
+			index := i
+			pathCopy := path
+			check := widget.NewCheck(path, nil)
+			check.SetChecked(member[path])
+			check.OnChanged = func(checked bool) {
+				member[pathCopy] = checked
+				render()
+			}
+			upBtn := widget.NewButton("Up", func() { move(index, -1) })
+			downBtn := widget.NewButton("Down", func() { move(index, 1) })
+			if !member[path] || index == 0 {
+				upBtn.Disable()
+			}
+			if !member[path] || index+1 >= len(order) || !member[order[index+1]] {
+				downBtn.Disable()
+			}
+			rows.Add(container.NewBorder(nil, nil, nil, container.NewHBox(upBtn, downBtn), check))
+		}
+		rows.Refresh()
+	}
+	render()
+
+	scroll := container.NewScroll(rows)
+	scroll.SetMinSize(fyne.NewSize(0, 260))
+	form := container.NewBorder(
+		widget.NewForm(&widget.FormItem{Text: "Name", Widget: nameEntry}),
+		nil, nil, nil,
+		container.NewBorder(widget.NewLabel("Projects (started top to bottom, stopped in reverse)"), nil, nil, nil, scroll),
+	)
+
+	title := "New Workspace"
+	if ws != nil {
+		title = "Edit Workspace"
+	}
+	editor := dialog.NewCustomConfirm(title, "Save", "Cancel", form, func(save bool) {
+		if !save {
+			return
+		}
+		var members []string
+		for _, path := range order {
+			if member[path] {
+				members = append(members, path)
+			}
+		}
+		var err error
+		if ws == nil {
+			_, err = projects.CreateWorkspace(nameEntry.Text, members)
+		} else {
+			_, err = projects.UpdateWorkspace(ws.ID, func(u *store.Workspace) {
+				u.Name = nameEntry.Text
+				u.Projects = members
+			})
+		}
+		if err != nil {
+			dialog.ShowError(err, w)
+			return
+		}
+		done()
+	}, w)
+	editor.Resize(fyne.NewSize(620, 460))
+	editor.Show()
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strconv"
 	"strings"
 
@@ -18,14 +19,37 @@ type Config struct {
 	BackupPath string
 }
 
+// Site describes how a generated Caddyfile serves the project. The zero value
+// serves the project directory with php_server.
+type Site struct {
+	// Root is the document root relative to the project directory.
+	Root string
+	// WorkerFile enables FrankenPHP worker mode with this script.
+	WorkerFile string
+	// WorkerNum is the number of workers; 0 lets FrankenPHP decide.
+	WorkerNum int
+}
+
 // ErrDesiredPortUnavailable indicates a requested port cannot be used.
 var ErrDesiredPortUnavailable = errors.New("desired port unavailable")
 
+// generatedCaddyfile matches Caddyfiles written by Site.render, including
+// those from releases that always served the project directory. The port is
+// the third group.
+var generatedCaddyfile = regexp.MustCompile(`^(\{\n\tfrankenphp \{\n\t\tworker \{\n\t\t\tfile [^\n]+\n(\t\t\tnum \d+\n)?\t\t\}\n\t\}\n\}\n\n)?:(\d+) \{\n\troot \* [^\n]+\n\tphp_server\n\tfile_server\n\}\n?$`)
+
 // EnsureCaddyfile ensures a Caddyfile exists and uses a valid port.
 // usedPorts is an optional set of ports already in use by the runner.Manager.
 // desiredPort is optional; when set, it must be available or an error is returned.
 // Returns a Config struct containing details about the operation.
 func EnsureCaddyfile(dir string, usedPorts map[int]struct{}, desiredPort int) (*Config, error) {
+	return EnsureSiteCaddyfile(dir, Site{}, usedPorts, desiredPort)
+}
+
+// EnsureSiteCaddyfile is EnsureCaddyfile with site settings applied to the
+// Caddyfiles Frago generates, whether new or left from an earlier start.
+// Other existing Caddyfiles only have their port changed.
+func EnsureSiteCaddyfile(dir string, site Site, usedPorts map[int]struct{}, desiredPort int) (*Config, error) {
 	path := filepath.Join(dir, "Caddyfile")
 	defaultStartPort := 8080
 	defaultEndPort := 9000
@@ -78,7 +102,7 @@ func EnsureCaddyfile(dir string, usedPorts map[int]struct{}, desiredPort int) (*
 			}
 		}
 
-		content := fmt.Sprintf(":%d {\n\troot * .\n\tphp_server\n\tfile_server\n}", p)
+		content := site.render(p)
 		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
 			return nil, err
 		}
@@ -92,6 +116,31 @@ func EnsureCaddyfile(dir string, usedPorts map[int]struct{}, desiredPort int) (*
 	}
 	content := string(data)
 
+	if m := generatedCaddyfile.FindStringSubmatch(content); m != nil {
+		p := desiredPort
+		if !hasDesired {
+			p, _ = strconv.Atoi(m[3])
+			_, used := usedPorts[p]
+			if used || !port.IsPortFree(p) {
+				if p, err = findFreePort(defaultStartPort, defaultEndPort); err != nil {
+					return nil, err
+				}
+			}
+		}
+		rendered := site.render(p)
+		if rendered == content {
+			return &Config{Path: path, Port: p, IsNew: false}, nil
+		}
+		backupPath := path + ".bak"
+		if err := os.WriteFile(backupPath, data, 0644); err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(path, []byte(rendered), 0644); err != nil {
+			return nil, err
+		}
+		return &Config{Path: path, Port: p, IsNew: false, BackupPath: backupPath}, nil
+	}
+
 	lines := strings.Split(content, "\n")
 	var (
 		targetLine   int
@@ -239,18 +288,50 @@ func EnsureCaddyfile(dir string, usedPorts map[int]struct{}, desiredPort int) (*
 
 // EnsureCaddyfileAutoPort prefers desiredPort when available and falls back to any free port otherwise.
 func EnsureCaddyfileAutoPort(dir string, usedPorts map[int]struct{}, desiredPort int) (*Config, error) {
-	cfg, err := EnsureCaddyfile(dir, usedPorts, desiredPort)
+	return EnsureSiteCaddyfileAutoPort(dir, Site{}, usedPorts, desiredPort)
+}
+
+// EnsureSiteCaddyfileAutoPort is EnsureCaddyfileAutoPort with site settings.
+func EnsureSiteCaddyfileAutoPort(dir string, site Site, usedPorts map[int]struct{}, desiredPort int) (*Config, error) {
+	cfg, err := EnsureSiteCaddyfile(dir, site, usedPorts, desiredPort)
 	if err == nil {
 		return cfg, nil
 	}
 
 	if desiredPort > 0 && errors.Is(err, ErrDesiredPortUnavailable) {
-		return EnsureCaddyfile(dir, usedPorts, 0)
+		return EnsureSiteCaddyfile(dir, site, usedPorts, 0)
 	}
 
 	return nil, err
 }
 
+func (s Site) render(port int) string {
+	root := s.Root
+	if root == "" {
+		root = "."
+	}
+
+	var b strings.Builder
+	if s.WorkerFile != "" {
+		b.WriteString("{\n\tfrankenphp {\n\t\tworker {\n")
+		fmt.Fprintf(&b, "\t\t\tfile %s\n", quoteArg(s.WorkerFile))
+		if s.WorkerNum > 0 {
+			fmt.Fprintf(&b, "\t\t\tnum %d\n", s.WorkerNum)
+		}
+		b.WriteString("\t\t}\n\t}\n}\n\n")
+	}
+	fmt.Fprintf(&b, ":%d {\n\troot * %s\n\tphp_server\n\tfile_server\n}", port, quoteArg(root))
+	return b.String()
+}
+
+// quoteArg quotes a Caddyfile argument that contains whitespace or quotes.
+func quoteArg(arg string) string {
+	if strings.ContainsAny(arg, " \t\"") {
+		return strconv.Quote(arg)
+	}
+	return arg
+}
+
 func RemoveCaddyfile(dir string) error {
 	path := filepath.Join(dir, "Caddyfile")
 	backup := path + ".bak"
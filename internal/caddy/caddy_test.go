@@ -99,3 +99,78 @@ func TestEnsureCaddyfileAutoPort_FallsBackWhenDesiredUsedByManager(t *testing.T)
 		t.Fatalf("expected fallback port, got managed in-use port %d", desired)
 	}
 }
+
+func TestEnsureSiteCaddyfile_RendersDocrootAndWorker(t *testing.T) {
+	dir := t.TempDir()
+	site := Site{Root: "public", WorkerFile: "public/index.php", WorkerNum: 2}
+
+	cfg, err := EnsureSiteCaddyfile(dir, site, nil, 0)
+	if err != nil {
+		t.Fatalf("EnsureSiteCaddyfile returned error: %v", err)
+	}
+	data, err := os.ReadFile(cfg.Path)
+	if err != nil {
+		t.Fatalf("read Caddyfile: %v", err)
+	}
+	content := string(data)
+	for _, want := range []string{"file public/index.php", "num 2", "root * public", fmt.Sprintf(":%d {", cfg.Port)} {
+		if !strings.Contains(content, want) {
+			t.Fatalf("Caddyfile missing %q:\n%s", want, content)
+		}
+	}
+
+	// The generated file must still be understood when it is reused.
+	again, err := EnsureCaddyfile(dir, nil, 0)
+	if err != nil {
+		t.Fatalf("reusing generated Caddyfile: %v", err)
+	}
+	if again.IsNew {
+		t.Fatalf("expected the existing Caddyfile to be reused")
+	}
+}
+
+func TestEnsureSiteCaddyfile_UpdatesGeneratedCaddyfile(t *testing.T) {
+	dir := t.TempDir()
+	// A Caddyfile left by an earlier start, from before docroot support.
+	legacy := ":8123 {\n\troot * .\n\tphp_server\n\tfile_server\n}"
+	if err := os.WriteFile(filepath.Join(dir, "Caddyfile"), []byte(legacy), 0644); err != nil {
+		t.Fatalf("write Caddyfile: %v", err)
+	}
+
+	cfg, err := EnsureSiteCaddyfile(dir, Site{Root: "public"}, nil, 0)
+	if err != nil {
+		t.Fatalf("EnsureSiteCaddyfile returned error: %v", err)
+	}
+	data, _ := os.ReadFile(cfg.Path)
+	if !strings.Contains(string(data), "root * public") || cfg.BackupPath == "" {
+		t.Fatalf("expected the docroot to be applied with a backup, got %+v:\n%s", cfg, data)
+	}
+
+	cfg, err = EnsureSiteCaddyfile(dir, Site{Root: "web", WorkerFile: "web/index.php"}, nil, 0)
+	if err != nil {
+		t.Fatalf("EnsureSiteCaddyfile returned error: %v", err)
+	}
+	data, _ = os.ReadFile(cfg.Path)
+	if !strings.Contains(string(data), "root * web") || !strings.Contains(string(data), "file web/index.php") {
+		t.Fatalf("expected the new docroot and worker, got:\n%s", data)
+	}
+}
+
+func TestEnsureSiteCaddyfile_KeepsUserCaddyfile(t *testing.T) {
+	dir := t.TempDir()
+	custom := ":8124 {\n\troot * dist\n\tencode gzip\n\tphp_server\n\tfile_server\n}"
+	if err := os.WriteFile(filepath.Join(dir, "Caddyfile"), []byte(custom), 0644); err != nil {
+		t.Fatalf("write Caddyfile: %v", err)
+	}
+	desired := pickFreePort(t)
+
+	cfg, err := EnsureSiteCaddyfile(dir, Site{Root: "public"}, nil, desired)
+	if err != nil {
+		t.Fatalf("EnsureSiteCaddyfile returned error: %v", err)
+	}
+	data, _ := os.ReadFile(cfg.Path)
+	want := strings.Replace(custom, ":8124", fmt.Sprintf(":%d", desired), 1)
+	if string(data) != want {
+		t.Fatalf("expected only the port to change, got:\n%s", data)
+	}
+}
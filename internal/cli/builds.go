@@ -0,0 +1,120 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"text/tabwriter"
+
+	"github.com/devmarvs/frago/internal/updater"
+)
+
+func buildsCommand(ctx context.Context, c *cli, args []string) error {
+	fs := newFlagSet("builds", c)
+	source := fs.String("source", "", "release source as KIND:LOCATION, e.g. dir:/srv/frankenphp (default: $FRAGO_RELEASE_SOURCE or GitHub)")
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	action := "list"
+	if len(positional) > 0 {
+		action, positional = positional[0], positional[1:]
+	}
+
+	builds, err := updater.NewBuilds()
+	if err != nil {
+		return err
+	}
+	if *source != "" {
+		src, err := updater.ParseSource(*source)
+		if err != nil {
+			return fmt.Errorf("%w: %v", errUsage, err)
+		}
+		builds.Client = updater.NewClientFor(src)
+	}
+
+	switch action {
+	case "list":
+		if len(positional) != 0 {
+			return fmt.Errorf("%w: list takes no arguments", errUsage)
+		}
+		installed := builds.Installed()
+		if len(installed) == 0 {
+			fmt.Fprintf(c.stdout, "No FrankenPHP builds downloaded to %s.\n", builds.Dir)
+			return nil
+		}
+		tw := tabwriter.NewWriter(c.stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "VERSION\tPATH")
+		for _, b := range installed {
+			fmt.Fprintf(tw, "%s\t%s\n", b.Version, b.Path)
+		}
+		return tw.Flush()
+
+	case "available":
+		if len(positional) != 0 {
+			return fmt.Errorf("%w: available takes no arguments", errUsage)
+		}
+		releases, err := builds.Client.Releases(ctx)
+		if err != nil {
+			return err
+		}
+		tw := tabwriter.NewWriter(c.stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "VERSION\tASSET\tINSTALLED")
+		for _, r := range releases {
+			asset, err := updater.PlatformAsset(r, runtime.GOOS, runtime.GOARCH)
+			if err != nil {
+				continue
+			}
+			_, installed := builds.Path(r.TagName)
+			fmt.Fprintf(tw, "%s\t%s\t%s\n", r.TagName, asset.Name, yesNo(installed))
+		}
+		return tw.Flush()
+
+	case "install":
+		if len(positional) > 1 {
+			return fmt.Errorf("%w: install takes at most one version", errUsage)
+		}
+		release, err := findRelease(ctx, builds.Client, positional)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(c.stderr, "Downloading FrankenPHP %s...\n", release.TagName)
+		build, err := builds.Install(ctx, *release, nil)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(c.stdout, "Installed FrankenPHP %s to %s\n", build.Version, build.Path)
+		return nil
+
+	case "remove":
+		if len(positional) != 1 {
+			return fmt.Errorf("%w: remove takes one version", errUsage)
+		}
+		if err := builds.Remove(positional[0]); err != nil {
+			return err
+		}
+		fmt.Fprintf(c.stdout, "Removed FrankenPHP %s\n", positional[0])
+		return nil
+
+	default:
+		return fmt.Errorf("%w: unknown builds action %q", errUsage, action)
+	}
+}
+
+// findRelease returns the release tagged args[0], or the latest release when
+// args is empty.
+func findRelease(ctx context.Context, client *updater.Client, args []string) (*updater.ReleaseInfo, error) {
+	if len(args) == 0 {
+		return client.Latest(ctx)
+	}
+	releases, err := client.Releases(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range releases {
+		if releases[i].TagName == args[0] {
+			return &releases[i], nil
+		}
+	}
+	return nil, fmt.Errorf("FrankenPHP release %s not found", args[0])
+}
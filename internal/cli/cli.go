@@ -0,0 +1,759 @@
+// Package cli implements the frago command-line interface. Commands talk to a
+// running Frago instance through the API; "run" falls back to managing
+// FrankenPHP in-process when no instance is running.
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/devmarvs/frago/internal/daemon"
+	"github.com/devmarvs/frago/internal/service"
+	"github.com/devmarvs/frago/internal/store"
+	"github.com/devmarvs/frago/pkg/fragoclient"
+)
+
+// Environment variables that point the CLI at a specific API instead of the
+// one found through the local socket or discovery file, e.g. over an SSH
+// tunnel.
+const (
+	envURL   = "FRAGO_URL"
+	envToken = "FRAGO_TOKEN"
+)
+
+const logPollInterval = 500 * time.Millisecond
+
+// Version is the Frago version reported by "frago version" and compared with
+// releases by "frago self-update". main sets it.
+var Version = "dev"
+
+// errUsage marks errors caused by bad arguments; they exit with status 2.
+var errUsage = errors.New("usage error")
+
+type command struct {
+	usage   string
+	summary string
+	run     func(ctx context.Context, c *cli, args []string) error
+}
+
+var commands = map[string]command{
+	"run": {
+		usage:   "run [dir] [--port N] [--binary PATH]",
+		summary: "Start FrankenPHP for a project (default: current directory)",
+		run:     runCommand,
+	},
+	"stop": {
+		usage:   "stop [dir] [--all]",
+		summary: "Stop a running project, or every project with --all",
+		run:     stopCommand,
+	},
+	"status": {
+		usage:   "status [--json]",
+		summary: "Show running and saved projects",
+		run:     statusCommand,
+	},
+	"logs": {
+		usage:   "logs [dir] [-f] [-n LINES] [--sidecar NAME]",
+		summary: "Print a project's output; -f keeps following it",
+		run:     logsCommand,
+	},
+	"debug": {
+		usage:   "debug [on|off] [dir] [--trigger=BOOL] [--host HOST] [--port N]",
+		summary: "Show or toggle Xdebug for a saved project, restarting it if running",
+		run:     debugCommand,
+	},
+	"sidecars": {
+		usage:   "sidecars [list | start NAME | stop NAME | restart NAME] [dir]",
+		summary: "List or control the sidecar processes of a saved project",
+		run:     sidecarsCommand,
+	},
+	"daemon": {
+		usage:   "daemon [--projects FILE]",
+		summary: "Run the manager and API without the desktop window",
+		run:     daemonCommand,
+	},
+	"list": {
+		usage:   "list [--json]",
+		summary: "List saved projects",
+		run:     listCommand,
+	},
+	"export": {
+		usage:   "export [FILE] [--format json|toml] [--projects FILE]",
+		summary: "Write saved projects and workspaces to FILE (default: stdout)",
+		run:     exportCommand,
+	},
+	"import": {
+		usage:   "import FILE [--map OLD=NEW]... [--keep-home] [--dry-run] [--projects FILE]",
+		summary: "Add projects and workspaces from an export",
+		run:     importCommand,
+	},
+	"builds": {
+		usage:   "builds [list | available | install [VERSION] | remove VERSION]",
+		summary: "Manage FrankenPHP builds downloaded by Frago",
+		run:     buildsCommand,
+	},
+	"version": {
+		usage:   "version",
+		summary: "Print the Frago version",
+		run:     versionCommand,
+	},
+	"self-update": {
+		usage:   "self-update [--check] [--pre] [--rollback]",
+		summary: "Replace this Frago binary with the latest release",
+		run:     selfUpdateCommand,
+	},
+}
+
+type cli struct {
+	stdout io.Writer
+	stderr io.Writer
+}
+
+// IsCommand reports whether name is a CLI subcommand. main uses it to decide
+// between the CLI and the GUI, so unrelated arguments passed by the desktop
+// environment still open the window.
+func IsCommand(name string) bool {
+	if name == "help" || name == "-h" || name == "--help" {
+		return true
+	}
+	_, ok := commands[name]
+	return ok
+}
+
+// Main runs the CLI with the process arguments (without the program name) and
+// returns the exit status. Interrupts cancel the running command.
+func Main(args []string) int {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	return Run(ctx, args, os.Stdout, os.Stderr)
+}
+
+// Run executes one CLI command and returns the exit status.
+func Run(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	c := &cli{stdout: stdout, stderr: stderr}
+	if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		c.usage(stdout)
+		return 0
+	}
+
+	cmd, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(stderr, "frago: unknown command %q\n\n", args[0])
+		c.usage(stderr)
+		return 2
+	}
+
+	if err := cmd.run(ctx, c, args[1:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		if errors.Is(err, errUsage) {
+			fmt.Fprintf(stderr, "frago: %v\nusage: frago %s\n", err, cmd.usage)
+			return 2
+		}
+		fmt.Fprintf(stderr, "frago: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+func (c *cli) usage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: frago [command]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Without a command, frago opens the desktop window.")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	for _, name := range names {
+		fmt.Fprintf(tw, "  %s\t%s\n", commands[name].usage, commands[name].summary)
+	}
+	tw.Flush()
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "Set %s (and %s) to use a specific API instead of the local instance.\n", envURL, envToken)
+}
+
+// connect returns a client for the running Frago instance.
+func connect(ctx context.Context) (*fragoclient.Client, error) {
+	if url := os.Getenv(envURL); url != "" {
+		return fragoclient.New(url, os.Getenv(envToken)), nil
+	}
+	return fragoclient.Discover(ctx)
+}
+
+func newFlagSet(name string, c *cli) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(c.stderr)
+	return fs
+}
+
+// parseArgs parses flags that may appear before or after positional
+// arguments, e.g. "frago run ./site --port 8080".
+func parseArgs(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				return nil, err
+			}
+			return nil, fmt.Errorf("%w: %v", errUsage, err)
+		}
+		rest := fs.Args()
+		if len(rest) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, rest[0])
+		args = rest[1:]
+	}
+}
+
+// projectDir resolves the optional directory argument to an absolute path.
+func projectDir(args []string) (string, error) {
+	if len(args) > 1 {
+		return "", fmt.Errorf("%w: expected at most one directory", errUsage)
+	}
+	dir := "."
+	if len(args) == 1 {
+		dir = args[0]
+	}
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Clean(abs), nil
+}
+
+func runCommand(ctx context.Context, c *cli, args []string) error {
+	fs := newFlagSet("run", c)
+	port := fs.Int("port", 0, "port to listen on (default: saved or next free port)")
+	binary := fs.String("binary", "", "FrankenPHP binary to use (default: frankenphp on PATH)")
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	dir, err := projectDir(positional)
+	if err != nil {
+		return err
+	}
+	if *port < 0 || *port > 65535 {
+		return fmt.Errorf("%w: port must be between 1 and 65535", errUsage)
+	}
+	if st, err := os.Stat(dir); err != nil {
+		return err
+	} else if !st.IsDir() {
+		return fmt.Errorf("%s is not a directory", dir)
+	}
+
+	client, err := connect(ctx)
+	if err != nil {
+		return runLocal(ctx, c, dir, *binary, *port)
+	}
+	resp, err := client.Run(ctx, fragoclient.RunRequest{ProjectPath: dir, BinaryPath: *binary, Port: *port})
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(c.stdout, "Started %s at %s\n", dir, resp.URL)
+	return nil
+}
+
+func stopCommand(ctx context.Context, c *cli, args []string) error {
+	fs := newFlagSet("stop", c)
+	all := fs.Bool("all", false, "stop every running project")
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if *all && len(positional) > 0 {
+		return fmt.Errorf("%w: --all does not take a directory", errUsage)
+	}
+
+	client, err := connect(ctx)
+	if err != nil {
+		return err
+	}
+	if *all {
+		resp, err := client.StopAll(ctx)
+		if err != nil {
+			return err
+		}
+		return c.printBulk(resp)
+	}
+
+	dir, err := projectDir(positional)
+	if err != nil {
+		return err
+	}
+	if _, err := client.Stop(ctx, dir); err != nil {
+		return err
+	}
+	fmt.Fprintf(c.stdout, "Stopped %s\n", dir)
+	return nil
+}
+
+func statusCommand(ctx context.Context, c *cli, args []string) error {
+	fs := newFlagSet("status", c)
+	asJSON := fs.Bool("json", false, "print the raw API response")
+	if _, err := parseArgs(fs, args); err != nil {
+		return err
+	}
+
+	client, err := connect(ctx)
+	if err != nil {
+		return err
+	}
+	status, err := client.Status(ctx)
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		return c.printJSON(status)
+	}
+
+	if len(status.Processes) == 0 && len(status.Stopped) == 0 {
+		fmt.Fprintln(c.stdout, "No projects.")
+		return nil
+	}
+	tw := tabwriter.NewWriter(c.stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "STATE\tURL\tPID\tUPTIME\tHEALTH\tPATH")
+	for _, p := range append(status.Processes, status.Stopped...) {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			p.State, dash(p.URL), dashInt(p.PID), formatUptime(p.UptimeSeconds), formatHealth(p), p.ProjectPath)
+	}
+	return tw.Flush()
+}
+
+func listCommand(ctx context.Context, c *cli, args []string) error {
+	fs := newFlagSet("list", c)
+	asJSON := fs.Bool("json", false, "print the raw API response")
+	if _, err := parseArgs(fs, args); err != nil {
+		return err
+	}
+
+	client, err := connect(ctx)
+	if err != nil {
+		return err
+	}
+	projects, err := client.ListProjects(ctx)
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		return c.printJSON(projects)
+	}
+
+	if len(projects) == 0 {
+		fmt.Fprintln(c.stdout, "No saved projects.")
+		return nil
+	}
+	tw := tabwriter.NewWriter(c.stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tRUNNING\tPORT\tFLAGS\tNAME\tTAGS\tPATH")
+	for _, p := range projects {
+		var flags []string
+		if p.Pinned {
+			flags = append(flags, "pinned")
+		}
+		if p.AutoStart {
+			flags = append(flags, "auto-start")
+		}
+		port := p.PreferredPort
+		if port == 0 {
+			port = p.LastPort
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			p.ID, yesNo(p.Running), dashInt(port), dash(strings.Join(flags, ",")), dash(p.Name), dash(strings.Join(p.Tags, ",")), p.Path)
+	}
+	return tw.Flush()
+}
+
+func logsCommand(ctx context.Context, c *cli, args []string) error {
+	fs := newFlagSet("logs", c)
+	follow := fs.Bool("f", false, "keep printing new output until interrupted")
+	lines := fs.Int("n", 200, "number of lines to show")
+	sidecar := fs.String("sidecar", "", "show the output of this sidecar instead of FrankenPHP")
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	dir, err := projectDir(positional)
+	if err != nil {
+		return err
+	}
+	if *lines < 1 {
+		return fmt.Errorf("%w: -n must be positive", errUsage)
+	}
+
+	client, err := connect(ctx)
+	if err != nil {
+		return err
+	}
+	print := func(line string) { fmt.Fprintln(c.stdout, line) }
+	if *follow && *sidecar != "" {
+		return client.FollowSidecarLogs(ctx, dir, *sidecar, *lines, logPollInterval, print)
+	}
+	if *follow {
+		return client.FollowLogs(ctx, dir, *lines, logPollInterval, print)
+	}
+	var resp fragoclient.LogsResponse
+	if *sidecar != "" {
+		resp, err = client.SidecarLogs(ctx, dir, *sidecar, *lines)
+	} else {
+		resp, err = client.Logs(ctx, dir, *lines)
+	}
+	if err != nil {
+		return err
+	}
+	for _, line := range resp.Lines {
+		fmt.Fprintln(c.stdout, line)
+	}
+	return nil
+}
+
+func debugCommand(ctx context.Context, c *cli, args []string) error {
+	fs := newFlagSet("debug", c)
+	trigger := fs.Bool("trigger", false, "debug only requests with XDEBUG_TRIGGER or XDEBUG_SESSION")
+	host := fs.String("host", "", "host the IDE listens on (default: localhost)")
+	port := fs.Int("port", 0, "port the IDE listens on (default: 9003)")
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+
+	var req fragoclient.DebugRequest
+	if len(positional) > 0 && (positional[0] == "on" || positional[0] == "off") {
+		enabled := positional[0] == "on"
+		req.Enabled = &enabled
+		positional = positional[1:]
+	}
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "trigger":
+			req.Trigger = trigger
+		case "host":
+			req.ClientHost = host
+		case "port":
+			req.ClientPort = port
+		}
+	})
+	if *port < 0 || *port > 65535 {
+		return fmt.Errorf("%w: port must be between 1 and 65535", errUsage)
+	}
+	dir, err := projectDir(positional)
+	if err != nil {
+		return err
+	}
+
+	client, err := connect(ctx)
+	if err != nil {
+		return err
+	}
+	id := store.ProjectID(dir)
+	var resp fragoclient.DebugResponse
+	if req == (fragoclient.DebugRequest{}) {
+		resp, err = client.ProjectDebug(ctx, id)
+	} else {
+		resp, err = client.SetProjectDebug(ctx, id, req)
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(c.stdout, "Xdebug: %s\n", resp.Summary)
+	if resp.Restarted {
+		fmt.Fprintf(c.stdout, "Restarted %s\n", dir)
+	}
+	return nil
+}
+
+func sidecarsCommand(ctx context.Context, c *cli, args []string) error {
+	fs := newFlagSet("sidecars", c)
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	action, name := "list", ""
+	if len(positional) > 0 {
+		action, positional = positional[0], positional[1:]
+	}
+	switch action {
+	case "list":
+	case "start", "stop", "restart":
+		if len(positional) == 0 {
+			return fmt.Errorf("%w: %s needs a sidecar name", errUsage, action)
+		}
+		name, positional = positional[0], positional[1:]
+	default:
+		// "frago sidecars DIR" lists the sidecars of DIR.
+		action, positional = "list", append([]string{action}, positional...)
+	}
+	dir, err := projectDir(positional)
+	if err != nil {
+		return err
+	}
+
+	client, err := connect(ctx)
+	if err != nil {
+		return err
+	}
+	id := store.ProjectID(dir)
+	done := ""
+	switch action {
+	case "start":
+		_, err = client.StartSidecar(ctx, id, name)
+		done = "Started"
+	case "stop":
+		_, err = client.StopSidecar(ctx, id, name)
+		done = "Stopped"
+	case "restart":
+		_, err = client.RestartSidecar(ctx, id, name)
+		done = "Restarted"
+	}
+	if err != nil {
+		return err
+	}
+	if done != "" {
+		fmt.Fprintf(c.stdout, "%s sidecar %s of %s\n", done, name, dir)
+		return nil
+	}
+
+	resp, err := client.Sidecars(ctx, id)
+	if err != nil {
+		return err
+	}
+	if len(resp.Sidecars) == 0 {
+		fmt.Fprintf(c.stdout, "No sidecars declared for %s.\n", dir)
+		return nil
+	}
+	tw := tabwriter.NewWriter(c.stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tSTATE\tPID\tUPTIME\tRESTART\tCOMMAND")
+	for _, sc := range resp.Sidecars {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			sc.Name, sc.State, dashInt(sc.PID), formatUptime(sc.UptimeSeconds), dash(sc.Restart), strings.Join(sc.Command, " "))
+	}
+	return tw.Flush()
+}
+
+func daemonCommand(ctx context.Context, c *cli, args []string) error {
+	fs := newFlagSet("daemon", c)
+	path := projectFileFlag(fs)
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) > 0 {
+		return fmt.Errorf("%w: unexpected argument %q", errUsage, positional[0])
+	}
+	if *path == "" {
+		if *path, err = store.DefaultPath(); err != nil {
+			return err
+		}
+	}
+	return daemon.Run(ctx, *path)
+}
+
+// projectFileFlag adds the --projects flag used by commands that work on the
+// project file directly.
+func projectFileFlag(fs *flag.FlagSet) *string {
+	return fs.String("projects", "", "project file (default: frago/projects.json in the user config directory)")
+}
+
+// openProjects loads the project file at path, or the default one. Running
+// instances pick up changes saved to it.
+func openProjects(path string) (*store.Store, error) {
+	if path == "" {
+		var err error
+		if path, err = store.DefaultPath(); err != nil {
+			return nil, err
+		}
+	}
+	projects := store.New(store.FileBackend{Path: path})
+	if err := projects.Load(); err != nil {
+		return nil, fmt.Errorf("load projects from %s: %w", path, err)
+	}
+	return projects, nil
+}
+
+func exportCommand(ctx context.Context, c *cli, args []string) error {
+	fs := newFlagSet("export", c)
+	format := fs.String("format", "", "json or toml (default: from the file extension, json for stdout)")
+	projectsPath := projectFileFlag(fs)
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) > 1 {
+		return fmt.Errorf("%w: expected at most one file", errUsage)
+	}
+	out := ""
+	if len(positional) == 1 {
+		out = positional[0]
+	}
+	if *format == "" {
+		*format = store.ExportFileFormat(out)
+	}
+
+	projects, err := openProjects(*projectsPath)
+	if err != nil {
+		return err
+	}
+	data, err := store.EncodeExport(projects.Export(), *format)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errUsage, err)
+	}
+	if out == "" {
+		_, err = c.stdout.Write(data)
+		return err
+	}
+	if err := os.WriteFile(out, data, 0600); err != nil {
+		return err
+	}
+	fmt.Fprintf(c.stdout, "Exported %d projects to %s\n", len(projects.List()), out)
+	return nil
+}
+
+// pathMaps collects repeated --map OLD=NEW flags.
+type pathMaps [][2]string
+
+func (m *pathMaps) String() string {
+	return fmt.Sprint(*m)
+}
+
+func (m *pathMaps) Set(value string) error {
+	from, to, ok := strings.Cut(value, "=")
+	if !ok || from == "" || to == "" {
+		return fmt.Errorf("expected OLD=NEW")
+	}
+	*m = append(*m, [2]string{from, to})
+	return nil
+}
+
+func importCommand(ctx context.Context, c *cli, args []string) error {
+	fs := newFlagSet("import", c)
+	var maps pathMaps
+	fs.Var(&maps, "map", "replace the path prefix OLD with NEW (repeatable)")
+	keepHome := fs.Bool("keep-home", false, "do not move paths from the exporting home directory to yours")
+	dryRun := fs.Bool("dry-run", false, "print the projects that would be imported without saving them")
+	projectsPath := projectFileFlag(fs)
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return fmt.Errorf("%w: expected one export file", errUsage)
+	}
+
+	data, err := os.ReadFile(positional[0])
+	if err != nil {
+		return err
+	}
+	e, err := store.DecodeExport(data, store.ExportFileFormat(positional[0]))
+	if err != nil {
+		return err
+	}
+	for _, m := range maps {
+		e = e.Remap(m[0], m[1])
+	}
+	if !*keepHome {
+		if home, err := os.UserHomeDir(); err == nil {
+			e = e.Remap(e.Home, home)
+		}
+	}
+
+	if *dryRun {
+		for _, p := range e.Projects {
+			fmt.Fprintln(c.stdout, p.Path)
+		}
+		return nil
+	}
+	projects, err := openProjects(*projectsPath)
+	if err != nil {
+		return err
+	}
+	summary, err := projects.Import(e, service.ValidateProject)
+	if err != nil {
+		return err
+	}
+	for _, skipped := range summary.Skipped {
+		fmt.Fprintf(c.stderr, "frago: skipped %s\n", skipped)
+	}
+	for _, path := range summary.DroppedBinaryPath {
+		fmt.Fprintf(c.stderr, "frago: %s does not exist here; the project will use a detected binary\n", path)
+	}
+	fmt.Fprintf(c.stdout, "Imported %d new and %d existing projects, %d workspaces\n", summary.Added, summary.Updated, summary.Workspaces)
+	return nil
+}
+
+func (c *cli) printJSON(v any) error {
+	enc := json.NewEncoder(c.stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func (c *cli) printBulk(resp fragoclient.BulkResponse) error {
+	for _, r := range resp.Results {
+		if r.OK {
+			fmt.Fprintf(c.stdout, "%s: %s\n", r.Path, r.Status)
+		} else {
+			fmt.Fprintf(c.stdout, "%s: %s: %s\n", r.Path, r.Status, r.Error)
+		}
+	}
+	if resp.Failed > 0 {
+		return fmt.Errorf("%d of %d projects failed", resp.Failed, len(resp.Results))
+	}
+	return nil
+}
+
+func formatUptime(seconds int64) string {
+	if seconds <= 0 {
+		return "-"
+	}
+	return (time.Duration(seconds) * time.Second).String()
+}
+
+func formatHealth(p fragoclient.ProjectStatus) string {
+	switch {
+	case p.Health != nil && p.Health.Healthy:
+		return "ok"
+	case p.Health != nil:
+		return "failing"
+	case p.LastExit != nil && p.LastExit.Failed:
+		return "crashed"
+	default:
+		return "-"
+	}
+}
+
+func dash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+func dashInt(n int) string {
+	if n == 0 {
+		return "-"
+	}
+	return fmt.Sprint(n)
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
@@ -0,0 +1,298 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/devmarvs/frago/internal/runner"
+	"github.com/devmarvs/frago/internal/server"
+	"github.com/devmarvs/frago/internal/service"
+	"github.com/devmarvs/frago/internal/store"
+)
+
+func newTestAPI(t *testing.T) *store.Store {
+	t.Helper()
+	projects := store.New(&memoryBackend{})
+	svc := service.New(runner.NewManager(), projects, nil)
+	ts := httptest.NewServer(server.New(svc, server.Config{Token: "secret"}))
+	t.Cleanup(ts.Close)
+	t.Setenv(envURL, ts.URL)
+	t.Setenv(envToken, "secret")
+	return projects
+}
+
+func run(t *testing.T, args ...string) (int, string, string) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	code := Run(context.Background(), args, &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestListAndStatus(t *testing.T) {
+	projects := newTestAPI(t)
+	if _, err := projects.Create(store.Project{Path: "/srv/shop", PreferredPort: 8090, Pinned: true}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	code, out, errOut := run(t, "list")
+	if code != 0 {
+		t.Fatalf("list exited %d: %s", code, errOut)
+	}
+	if !strings.Contains(out, "/srv/shop") || !strings.Contains(out, "8090") || !strings.Contains(out, "pinned") {
+		t.Fatalf("unexpected list output:\n%s", out)
+	}
+
+	code, out, errOut = run(t, "status")
+	if code != 0 {
+		t.Fatalf("status exited %d: %s", code, errOut)
+	}
+	if !strings.Contains(out, "stopped") || !strings.Contains(out, "/srv/shop") {
+		t.Fatalf("unexpected status output:\n%s", out)
+	}
+
+	code, out, _ = run(t, "status", "--json")
+	if code != 0 || !strings.Contains(out, `"schema_version"`) {
+		t.Fatalf("unexpected json status (%d):\n%s", code, out)
+	}
+}
+
+func TestRunLocalServesUntilExit(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell script as the FrankenPHP binary")
+	}
+	runtimeDir, err := os.MkdirTemp("", "frago")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(runtimeDir) })
+	t.Setenv("XDG_RUNTIME_DIR", runtimeDir)
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv("FRAGO_PHP_INI_DIR", t.TempDir())
+	t.Setenv("FRAGO_BINARIES_DIR", t.TempDir())
+	bin := t.TempDir()
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	binary := filepath.Join(bin, "frankenphp")
+	t.Setenv("FRANKENPHP_BINARY", binary)
+	if err := os.WriteFile(binary, []byte("#!/bin/sh\nexec sleep 1\n"), 0755); err != nil {
+		t.Fatalf("write binary: %v", err)
+	}
+
+	dir := t.TempDir()
+	var stdout, stderr bytes.Buffer
+	c := &cli{stdout: &stdout, stderr: &stderr}
+	if err := runLocal(context.Background(), c, dir, binary, 0); err != nil {
+		t.Fatalf("run: %v\n%s", err, stderr.String())
+	}
+	if !strings.Contains(stderr.String(), "Serving "+dir) {
+		t.Fatalf("unexpected output:\n%s", stderr.String())
+	}
+}
+
+func TestStopReportsAPIError(t *testing.T) {
+	newTestAPI(t)
+	code, _, errOut := run(t, "stop", t.TempDir())
+	if code != 1 || !strings.Contains(errOut, "no process running") {
+		t.Fatalf("expected API error, got %d: %s", code, errOut)
+	}
+}
+
+func TestDebug(t *testing.T) {
+	projects := newTestAPI(t)
+	dir := t.TempDir()
+	if _, err := projects.Create(store.Project{Path: dir}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	code, out, errOut := run(t, "debug", dir)
+	if code != 0 || !strings.Contains(out, "Xdebug: Off") {
+		t.Fatalf("debug exited %d: %s%s", code, out, errOut)
+	}
+	code, out, errOut = run(t, "debug", "on", dir, "--trigger", "--port", "9010")
+	if code != 0 || !strings.Contains(out, "On (trigger, localhost:9010)") {
+		t.Fatalf("debug on exited %d: %s%s", code, out, errOut)
+	}
+	if p, _ := projects.Get(dir); !p.Xdebug.Enabled || !p.Xdebug.Trigger || p.Xdebug.ClientPort != 9010 {
+		t.Fatalf("unexpected saved settings %+v", p.Xdebug)
+	}
+	code, out, _ = run(t, "debug", "off", dir)
+	if code != 0 || !strings.Contains(out, "Xdebug: Off") {
+		t.Fatalf("debug off exited %d: %s", code, out)
+	}
+	if code, _, _ := run(t, "debug", "on", dir, "--port", "70000"); code != 2 {
+		t.Fatalf("an invalid port should exit 2, got %d", code)
+	}
+}
+
+func TestSidecars(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh as the sidecar")
+	}
+	projects := newTestAPI(t)
+	dir := t.TempDir()
+	manifest := "[sidecars.worker]\ncommand = [\"sh\", \"-c\", \"echo working; sleep 60\"]\nauto_start = false\n"
+	if err := os.WriteFile(filepath.Join(dir, "frago.toml"), []byte(manifest), 0o644); err != nil {
+		t.Fatalf("write manifest: %v", err)
+	}
+	if _, err := projects.Create(store.Project{Path: dir}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	code, out, errOut := run(t, "sidecars", dir)
+	if code != 0 || !strings.Contains(out, "worker") || !strings.Contains(out, "stopped") {
+		t.Fatalf("sidecars exited %d: %s%s", code, out, errOut)
+	}
+	code, out, errOut = run(t, "sidecars", "start", "worker", dir)
+	if code != 0 || !strings.Contains(out, "Started sidecar worker") {
+		t.Fatalf("sidecars start exited %d: %s%s", code, out, errOut)
+	}
+	t.Cleanup(func() { run(t, "sidecars", "stop", "worker", dir) })
+
+	deadline := time.Now().Add(3 * time.Second)
+	for {
+		code, out, _ = run(t, "logs", dir, "--sidecar", "worker")
+		if code == 0 && strings.Contains(out, "working") {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("logs --sidecar exited %d: %s", code, out)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	if code, out, _ = run(t, "sidecars", "list", dir); code != 0 || !strings.Contains(out, "running") {
+		t.Fatalf("expected a running sidecar, got %d: %s", code, out)
+	}
+	if code, _, errOut = run(t, "sidecars", "start", "missing", dir); code != 1 {
+		t.Fatalf("an unknown sidecar should exit 1, got %d: %s", code, errOut)
+	}
+	if code, _, _ = run(t, "sidecars", "stop"); code != 2 {
+		t.Fatalf("stop without a name should exit 2, got %d", code)
+	}
+}
+
+func TestUsageErrors(t *testing.T) {
+	if code, _, _ := run(t, "bogus"); code != 2 {
+		t.Fatalf("unknown command should exit 2, got %d", code)
+	}
+	if code, _, errOut := run(t, "logs", "-n", "0"); code != 2 {
+		t.Fatalf("invalid -n should exit 2, got %d: %s", code, errOut)
+	}
+	if code, _, _ := run(t, "stop", "--all", "/srv/x"); code != 2 {
+		t.Fatalf("--all with a directory should exit 2, got %d", code)
+	}
+}
+
+func TestParseArgsAllowsTrailingFlags(t *testing.T) {
+	c := &cli{stdout: &bytes.Buffer{}, stderr: &bytes.Buffer{}}
+	fs := newFlagSet("run", c)
+	port := fs.Int("port", 0, "")
+	positional, err := parseArgs(fs, []string{"./site", "--port", "8080"})
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if *port != 8080 || len(positional) != 1 || positional[0] != "./site" {
+		t.Fatalf("unexpected parse: port=%d positional=%v", *port, positional)
+	}
+}
+
+func TestIsCommand(t *testing.T) {
+	for _, name := range []string{"run", "stop", "status", "logs", "debug", "sidecars", "list", "export", "import", "builds", "version", "self-update", "help"} {
+		if !IsCommand(name) {
+			t.Errorf("%s should be a command", name)
+		}
+	}
+	if IsCommand("-psn_0_12345") {
+		t.Errorf("desktop launcher arguments must not be treated as commands")
+	}
+}
+
+func TestExportImport(t *testing.T) {
+	dir := t.TempDir()
+	from := filepath.Join(dir, "from.json")
+	source := store.New(store.FileBackend{Path: from})
+	if _, err := source.Create(store.Project{Path: "/home/alice/shop", PreferredPort: 8090, DependsOn: []string{"/home/alice/api"}}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if _, err := source.Create(store.Project{Path: "/home/alice/api"}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if _, err := source.Create(store.Project{Path: "/home/alice/broken", RestartPolicy: "sometimes"}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	exported := filepath.Join(dir, "projects.toml")
+	if code, _, errOut := run(t, "export", exported, "--projects", from); code != 0 {
+		t.Fatalf("export exited %d: %s", code, errOut)
+	}
+
+	to := filepath.Join(dir, "to.json")
+	code, out, errOut := run(t, "import", exported, "--map", "/home/alice=/Users/bob", "--keep-home", "--projects", to)
+	if code != 0 {
+		t.Fatalf("import exited %d: %s", code, errOut)
+	}
+	if !strings.Contains(out, "2 new") {
+		t.Fatalf("unexpected import output: %s", out)
+	}
+	if !strings.Contains(errOut, "skipped /Users/bob/broken") {
+		t.Fatalf("expected the invalid project to be reported, got: %s", errOut)
+	}
+
+	target := store.New(store.FileBackend{Path: to})
+	if err := target.Load(); err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	p, ok := target.Get("/Users/bob/shop")
+	if !ok || p.PreferredPort != 8090 || len(p.DependsOn) != 1 || p.DependsOn[0] != "/Users/bob/api" {
+		t.Fatalf("unexpected imported project: %+v (found %v)", p, ok)
+	}
+
+	if code, _, _ := run(t, "import"); code != 2 {
+		t.Fatalf("import without a file should exit 2, got %d", code)
+	}
+}
+
+func TestBuildsList(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("FRAGO_BINARIES_DIR", dir)
+
+	code, out, errOut := run(t, "builds")
+	if code != 0 || !strings.Contains(out, "No FrankenPHP builds") {
+		t.Fatalf("builds exited %d: %s%s", code, out, errOut)
+	}
+
+	build := filepath.Join(dir, "v1.9.0")
+	if err := os.MkdirAll(build, 0755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(build, runner.ManagedBinaryName()), nil, 0755); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	code, out, _ = run(t, "builds", "list")
+	if code != 0 || !strings.Contains(out, "v1.9.0") {
+		t.Fatalf("unexpected builds list (%d): %s", code, out)
+	}
+
+	if code, _, _ := run(t, "builds", "remove"); code != 2 {
+		t.Fatalf("remove without a version should exit 2, got %d", code)
+	}
+	if code, _, _ := run(t, "builds", "remove", "v1.9.0"); code != 0 {
+		t.Fatalf("remove exited %d", code)
+	}
+
+	mirror := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(mirror, "v1.10.0"), 0755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if code, out, errOut := run(t, "builds", "available", "--source", "dir:"+mirror); code != 0 || !strings.Contains(out, "VERSION") {
+		t.Fatalf("available from a directory exited %d: %s%s", code, out, errOut)
+	}
+	if code, _, _ := run(t, "builds", "available", "--source", "s3:bucket"); code != 2 {
+		t.Fatalf("an invalid source should exit 2, got %d", code)
+	}
+}
@@ -0,0 +1,102 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/devmarvs/frago/internal/ipc"
+	"github.com/devmarvs/frago/internal/runner"
+	"github.com/devmarvs/frago/internal/server"
+	"github.com/devmarvs/frago/internal/service"
+	"github.com/devmarvs/frago/internal/store"
+)
+
+// localStopTimeout leaves sidecars time to exit before they are killed.
+const localStopTimeout = runner.SidecarStopGrace + time.Second
+
+// memoryBackend keeps in-process runs from touching the saved projects,
+// which belong to the GUI. It returns what was last saved, as the store
+// reloads the backend before every change.
+type memoryBackend struct {
+	data []byte
+}
+
+func (b *memoryBackend) Load() ([]byte, error) { return b.data, nil }
+
+func (b *memoryBackend) Save(data []byte) error {
+	b.data = append([]byte(nil), data...)
+	return nil
+}
+
+// runLocal serves dir in the foreground, with its sidecars, until ctx is
+// canceled or FrankenPHP exits. The API is exposed on the local socket
+// meanwhile, so "frago status", "frago logs" and "frago stop" work from
+// another terminal.
+func runLocal(ctx context.Context, c *cli, dir, binaryPath string, port int) error {
+	mgr := runner.NewManager()
+	versions, _ := runner.DetectVersions()
+	svc := service.New(mgr, store.New(&memoryBackend{}), versions)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	if ln, err := ipc.Listen(); err == nil {
+		go func() {
+			if err := server.ServeLocal(ctx, server.New(svc, server.Config{}), ln); err != nil {
+				fmt.Fprintf(c.stderr, "frago: local API: %v\n", err)
+			}
+		}()
+	} else if !errors.Is(err, ipc.ErrAlreadyListening) {
+		fmt.Fprintf(c.stderr, "frago: local API unavailable: %v\n", err)
+	}
+
+	if err := svc.Start(dir, binaryPath, "", port); err != nil {
+		return err
+	}
+	if proc, ok := mgr.Get(dir); ok {
+		fmt.Fprintf(c.stderr, "Serving %s at %s (press Ctrl+C to stop)\n", dir, proc.URL)
+	}
+
+	ticker := time.NewTicker(250 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return stopLocal(svc, dir)
+		case <-ticker.C:
+			if _, running := mgr.Get(dir); running {
+				continue
+			}
+			if err := stopLocal(svc, dir); err != nil {
+				return err
+			}
+			if exit, ok := mgr.LastExit(dir); ok && exit.Failed {
+				return fmt.Errorf("frankenphp exited: %s", exit.Err)
+			}
+			return nil
+		}
+	}
+}
+
+// stopLocal stops FrankenPHP and the sidecars of dir and waits for them to
+// exit.
+func stopLocal(svc *service.Service, dir string) error {
+	mgr := svc.Manager()
+	if _, running := mgr.Get(dir); running {
+		if err := svc.Stop(dir); err != nil {
+			return err
+		}
+	} else {
+		svc.StopSidecars(dir)
+	}
+	deadline := time.Now().Add(localStopTimeout)
+	for time.Now().Before(deadline) {
+		if _, running := mgr.Get(dir); !running && len(mgr.Sidecars(dir)) == 0 {
+			return nil
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	return fmt.Errorf("timeout waiting for FrankenPHP to stop")
+}
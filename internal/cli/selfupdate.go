@@ -0,0 +1,75 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/devmarvs/frago/internal/updater"
+)
+
+func versionCommand(ctx context.Context, c *cli, args []string) error {
+	fs := newFlagSet("version", c)
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 0 {
+		return fmt.Errorf("%w: version takes no arguments", errUsage)
+	}
+	fmt.Fprintf(c.stdout, "Frago %s\n", Version)
+	return nil
+}
+
+func selfUpdateCommand(ctx context.Context, c *cli, args []string) error {
+	fs := newFlagSet("self-update", c)
+	checkOnly := fs.Bool("check", false, "only report whether an update is available")
+	pre := fs.Bool("pre", false, "include pre-releases")
+	rollback := fs.Bool("rollback", false, "restore the binary replaced by the last update")
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 0 {
+		return fmt.Errorf("%w: self-update takes no arguments", errUsage)
+	}
+
+	u, err := updater.NewSelfUpdate(Version)
+	if err != nil {
+		return err
+	}
+	if *rollback {
+		if err := u.Rollback(); err != nil {
+			return err
+		}
+		fmt.Fprintf(c.stdout, "Restored the previous Frago binary at %s\n", u.Executable)
+		return nil
+	}
+
+	opts := updater.CheckOptions{Channel: updater.ChannelStable}
+	if *pre {
+		opts.Channel = updater.ChannelPrerelease
+	}
+	release, err := u.Check(ctx, opts)
+	if errors.Is(err, updater.ErrDevelopmentBuild) {
+		return fmt.Errorf("%w; install a release build to update it", err)
+	}
+	if err != nil {
+		return err
+	}
+	if release == nil {
+		fmt.Fprintf(c.stdout, "Frago %s is up to date.\n", Version)
+		return nil
+	}
+	if *checkOnly {
+		fmt.Fprintf(c.stdout, "Frago %s is available (running %s): %s\n", release.TagName, Version, release.HtmlUrl)
+		return nil
+	}
+
+	fmt.Fprintf(c.stderr, "Downloading Frago %s...\n", release.TagName)
+	if err := u.Apply(ctx, *release, nil); err != nil {
+		return err
+	}
+	fmt.Fprintf(c.stdout, "Updated Frago to %s. Run \"frago self-update --rollback\" to go back to %s.\n", release.TagName, Version)
+	return nil
+}
@@ -0,0 +1,218 @@
+// Package daemon hosts the process manager, the API and the background
+// monitors without any GUI dependency. The desktop app embeds a Daemon, and
+// "frago daemon" runs one on its own for headless machines and containers.
+package daemon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/devmarvs/bebo"
+	"github.com/devmarvs/frago/internal/ipc"
+	"github.com/devmarvs/frago/internal/port"
+	"github.com/devmarvs/frago/internal/runner"
+	"github.com/devmarvs/frago/internal/server"
+	"github.com/devmarvs/frago/internal/service"
+	"github.com/devmarvs/frago/internal/store"
+)
+
+const (
+	apiPortMin      = 5600
+	apiPortMax      = 5799
+	fallbackAPIPort = 5678
+
+	// MonitorInterval is how often health checks and stats sampling run.
+	MonitorInterval = 5 * time.Second
+
+	// storeWatchInterval is how often the project file is checked for
+	// changes saved by other processes.
+	storeWatchInterval = 2 * time.Second
+
+	// versionScanInterval is how often FrankenPHP binaries are detected
+	// again.
+	versionScanInterval = 5 * time.Minute
+
+	// shutdownStopTimeout leaves sidecars time to exit before they are
+	// killed.
+	shutdownStopTimeout = runner.SidecarStopGrace + time.Second
+)
+
+// Daemon owns a service and exposes it over TCP and the local socket.
+type Daemon struct {
+	svc   *service.Service
+	app   *bebo.App
+	port  int
+	token string
+
+	local  net.Listener
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// New creates a daemon for projects. versions are the detected FrankenPHP
+// binaries. The API port is picked from 5600-5799 and a fresh token is
+// generated for it.
+func New(projects *store.Store, versions []runner.PHPVersion) (*Daemon, error) {
+	apiPort, err := port.FindFreePort(apiPortMin, apiPortMax)
+	if err != nil {
+		apiPort = fallbackAPIPort
+	}
+	token, err := ipc.NewToken()
+	if err != nil {
+		return nil, err
+	}
+
+	svc := service.New(runner.NewManager(), projects, versions)
+	return &Daemon{
+		svc:   svc,
+		app:   server.New(svc, server.Config{Port: apiPort, Token: token}),
+		port:  apiPort,
+		token: token,
+	}, nil
+}
+
+// Service returns the service the daemon exposes.
+func (d *Daemon) Service() *service.Service {
+	return d.svc
+}
+
+// Port returns the TCP port of the API.
+func (d *Daemon) Port() int {
+	return d.port
+}
+
+// Token returns the token required by TCP API requests.
+func (d *Daemon) Token() string {
+	return d.token
+}
+
+// Start serves the API, writes the discovery file and starts the health and
+// stats monitor. It returns ipc.ErrAlreadyListening, without starting
+// anything, when another instance owns the local socket.
+func (d *Daemon) Start() error {
+	ln, err := ipc.Listen()
+	if errors.Is(err, ipc.ErrAlreadyListening) {
+		return err
+	} else if err != nil {
+		log.Printf("Local API socket unavailable: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	d.cancel = cancel
+	d.local = ln
+
+	d.goRun(func() {
+		log.Printf("Starting Bebo API on 127.0.0.1:%d", d.port)
+		if err := d.app.Run(ctx); err != nil {
+			log.Printf("Bebo API server error: %v", err)
+		}
+	})
+
+	// Local socket (named pipe on Windows) plus a discovery file so clients
+	// can find the randomly chosen TCP port and its token.
+	discovery := ipc.Discovery{
+		PID:       os.Getpid(),
+		Port:      d.port,
+		URL:       fmt.Sprintf("http://127.0.0.1:%d", d.port),
+		Token:     d.token,
+		StartedAt: time.Now(),
+	}
+	if ln != nil {
+		discovery.Socket = ln.Addr().String()
+		d.goRun(func() {
+			if err := server.ServeLocal(ctx, d.app, ln); err != nil {
+				log.Printf("Local API server error: %v", err)
+			}
+		})
+	}
+	if err := ipc.WriteDiscovery(discovery); err != nil {
+		log.Printf("Failed to write discovery file: %v", err)
+	}
+
+	d.goRun(func() {
+		d.svc.RunMonitor(ctx, MonitorInterval)
+	})
+	d.goRun(func() {
+		d.svc.Projects().Watch(ctx, storeWatchInterval)
+	})
+	d.goRun(func() {
+		d.svc.RunVersionScans(ctx, versionScanInterval)
+	})
+	return nil
+}
+
+// Close stops serving the API and removes the discovery file. Running
+// projects are left alone; see Run for a full shutdown.
+func (d *Daemon) Close() {
+	if d.cancel == nil {
+		return
+	}
+	d.cancel()
+	if d.local != nil {
+		_ = d.local.Close()
+	}
+	d.wg.Wait()
+	_ = ipc.RemoveDiscovery(os.Getpid())
+}
+
+func (d *Daemon) goRun(fn func()) {
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+		fn()
+	}()
+}
+
+// Run starts a daemon for the project file at path, auto-starts projects,
+// and serves until ctx is canceled. On shutdown every running project is
+// stopped.
+func Run(ctx context.Context, path string) error {
+	projects := store.New(store.FileBackend{Path: path})
+	if err := projects.Load(); err != nil {
+		return fmt.Errorf("load projects from %s: %w", path, err)
+	}
+
+	versions, _ := runner.DetectVersions()
+	d, err := New(projects, versions)
+	if err != nil {
+		return err
+	}
+	if err := d.Start(); errors.Is(err, ipc.ErrAlreadyListening) {
+		return errors.New("frago is already running")
+	} else if err != nil {
+		return err
+	}
+	defer d.Close()
+
+	log.Printf("Frago daemon running (pid %d, projects in %s)", os.Getpid(), path)
+	for _, r := range d.svc.AutoStart() {
+		if r.OK {
+			log.Printf("Auto-start %s: %s", r.Path, r.Status)
+		} else {
+			log.Printf("Auto-start %s failed: %s", r.Path, r.Error)
+		}
+	}
+
+	<-ctx.Done()
+	log.Printf("Shutting down")
+	for _, r := range d.svc.StopAll() {
+		if !r.OK {
+			log.Printf("Stop %s failed: %s", r.Path, r.Error)
+		}
+	}
+	waitAllStopped(d.svc.Manager(), shutdownStopTimeout)
+	return nil
+}
+
+func waitAllStopped(mgr *runner.Manager, timeout time.Duration) {
+	deadline := time.Now().Add(timeout)
+	for (len(mgr.List()) > 0 || len(mgr.Sidecars("")) > 0) && time.Now().Before(deadline) {
+		time.Sleep(100 * time.Millisecond)
+	}
+}
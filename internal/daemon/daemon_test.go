@@ -0,0 +1,53 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/devmarvs/frago/internal/ipc"
+	"github.com/devmarvs/frago/internal/store"
+	"github.com/devmarvs/frago/pkg/fragoclient"
+)
+
+func TestStartServesLocalAPIAndClose(t *testing.T) {
+	t.Setenv("XDG_RUNTIME_DIR", t.TempDir())
+
+	d, err := New(store.New(store.FileBackend{Path: t.TempDir() + "/projects.json"}), nil)
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+	if err := d.Start(); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+
+	disc, err := ipc.ReadDiscovery()
+	if err != nil {
+		t.Fatalf("read discovery: %v", err)
+	}
+	if disc.PID != os.Getpid() || disc.Port != d.Port() || disc.Token != d.Token() {
+		t.Fatalf("unexpected discovery: %+v", disc)
+	}
+
+	status, err := fragoclient.NewLocal().Status(context.Background())
+	if err != nil {
+		t.Fatalf("status over socket: %v", err)
+	}
+	if status.SchemaVersion != fragoclient.StatusSchemaVersion {
+		t.Fatalf("unexpected status: %+v", status)
+	}
+
+	second, err := New(store.New(store.FileBackend{Path: t.TempDir() + "/projects.json"}), nil)
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+	if err := second.Start(); !errors.Is(err, ipc.ErrAlreadyListening) {
+		t.Fatalf("expected ErrAlreadyListening for a second daemon, got %v", err)
+	}
+
+	d.Close()
+	if _, err := ipc.ReadDiscovery(); !os.IsNotExist(err) {
+		t.Fatalf("expected discovery file to be removed, got %v", err)
+	}
+}
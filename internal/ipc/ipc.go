@@ -0,0 +1,137 @@
+// Package ipc locates a running Frago instance: the well-known local socket
+// (a Unix domain socket, or a named pipe on Windows) and the discovery file
+// that records the current TCP port and API token.
+package ipc
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const discoveryFileName = "discovery.json"
+
+// ErrAlreadyListening indicates another process already serves the local socket.
+var ErrAlreadyListening = errors.New("another Frago instance is listening on the local socket")
+
+// Discovery describes how to reach the API of a running Frago instance.
+type Discovery struct {
+	PID       int       `json:"pid"`
+	Port      int       `json:"port"`
+	URL       string    `json:"url"`
+	Token     string    `json:"token"`
+	Socket    string    `json:"socket,omitempty"`
+	StartedAt time.Time `json:"started_at"`
+}
+
+// RuntimeDir returns the per-user directory holding the socket and discovery file.
+// The directory is created with owner-only permissions if it does not exist.
+func RuntimeDir() (string, error) {
+	dir, err := runtimeDir()
+	if err != nil {
+		return "", err
+	}
+	if err := ensurePrivateDir(dir); err != nil {
+		return "", err
+	}
+	return dir, nil
+}
+
+// DiscoveryPath returns the location of the discovery file.
+func DiscoveryPath() (string, error) {
+	dir, err := RuntimeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, discoveryFileName), nil
+}
+
+// NewToken returns a random hex-encoded API token.
+func NewToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("generate token: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// WriteDiscovery atomically replaces the discovery file. The file is readable
+// only by the current user because it contains the API token.
+func WriteDiscovery(d Discovery) error {
+	path, err := DiscoveryPath()
+	if err != nil {
+		return err
+	}
+
+	raw, err := json.MarshalIndent(d, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), discoveryFileName+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath)
+
+	if err := tmp.Chmod(0600); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if _, err := tmp.Write(raw); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
+
+// ReadDiscovery loads the discovery file written by a running instance.
+func ReadDiscovery() (Discovery, error) {
+	path, err := DiscoveryPath()
+	if err != nil {
+		return Discovery{}, err
+	}
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return Discovery{}, err
+	}
+
+	var d Discovery
+	if err := json.Unmarshal(raw, &d); err != nil {
+		return Discovery{}, fmt.Errorf("parse discovery file: %w", err)
+	}
+	return d, nil
+}
+
+// RemoveDiscovery deletes the discovery file if it was written by pid.
+func RemoveDiscovery(pid int) error {
+	d, err := ReadDiscovery()
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	if d.PID != pid {
+		return nil
+	}
+
+	path, err := DiscoveryPath()
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+//go:build !windows
+
+package ipc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"syscall"
+	"time"
+)
+
+const socketFileName = "frago.sock"
+
+func runtimeDir() (string, error) {
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+		return filepath.Join(dir, "frago"), nil
+	}
+	return filepath.Join(os.TempDir(), fmt.Sprintf("frago-%d", os.Getuid())), nil
+}
+
+// ensurePrivateDir creates dir with mode 0700 and refuses directories that are
+// symlinks or owned by another user, since the socket inherits their access.
+func ensurePrivateDir(dir string) error {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+
+	st, err := os.Lstat(dir)
+	if err != nil {
+		return err
+	}
+	if !st.IsDir() {
+		return fmt.Errorf("runtime dir %s is not a directory", dir)
+	}
+	if sys, ok := st.Sys().(*syscall.Stat_t); ok && int(sys.Uid) != os.Getuid() {
+		return fmt.Errorf("runtime dir %s is owned by another user", dir)
+	}
+	if st.Mode().Perm()&0077 != 0 {
+		return os.Chmod(dir, 0700)
+	}
+	return nil
+}
+
+// Address returns the path of the local API socket.
+func Address() (string, error) {
+	dir, err := RuntimeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, socketFileName), nil
+}
+
+// Listen opens the local API socket. A stale socket left behind by a crashed
+// instance is replaced; a live one yields ErrAlreadyListening.
+func Listen() (net.Listener, error) {
+	path, err := Address()
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := os.Lstat(path); err == nil {
+		if conn, err := net.DialTimeout("unix", path, time.Second); err == nil {
+			_ = conn.Close()
+			return nil, ErrAlreadyListening
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, fmt.Errorf("remove stale socket: %w", err)
+		}
+	}
+
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, 0600); err != nil {
+		_ = ln.Close()
+		return nil, err
+	}
+	return ln, nil
+}
+
+// Dial connects to the local API socket.
+func Dial(ctx context.Context) (net.Conn, error) {
+	path, err := Address()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("no Frago instance listening on %s", path)
+	}
+	var d net.Dialer
+	return d.DialContext(ctx, "unix", path)
+}
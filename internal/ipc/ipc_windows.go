@@ -0,0 +1,78 @@
+//go:build windows
+
+package ipc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+
+	"github.com/Microsoft/go-winio"
+	"golang.org/x/sys/windows"
+)
+
+const pipeBufferSize = 64 * 1024
+
+func runtimeDir() (string, error) {
+	base, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(base, "Frago"), nil
+}
+
+// ensurePrivateDir creates dir under the user's local app data, which is
+// already restricted to the current user by its inherited ACL.
+func ensurePrivateDir(dir string) error {
+	return os.MkdirAll(dir, 0700)
+}
+
+// Address returns the name of the local API pipe. The name embeds the user's
+// SID so that each account on the machine gets its own pipe.
+func Address() (string, error) {
+	user, err := windows.GetCurrentProcessToken().GetTokenUser()
+	if err != nil {
+		return "", err
+	}
+	return `\\.\pipe\frago-` + user.User.Sid.String(), nil
+}
+
+// Listen opens the local API named pipe. Only the current user may connect and
+// remote clients are rejected. A pipe already owned by another instance
+// yields ErrAlreadyListening.
+func Listen() (net.Listener, error) {
+	name, err := Address()
+	if err != nil {
+		return nil, err
+	}
+	user, err := windows.GetCurrentProcessToken().GetTokenUser()
+	if err != nil {
+		return nil, err
+	}
+
+	ln, err := winio.ListenPipe(name, &winio.PipeConfig{
+		SecurityDescriptor: "D:P(A;;GA;;;" + user.User.Sid.String() + ")",
+		InputBufferSize:    pipeBufferSize,
+		OutputBufferSize:   pipeBufferSize,
+	})
+	if errors.Is(err, windows.ERROR_ACCESS_DENIED) || errors.Is(err, windows.ERROR_ALREADY_EXISTS) {
+		return nil, ErrAlreadyListening
+	}
+	return ln, err
+}
+
+// Dial connects to the local API named pipe.
+func Dial(ctx context.Context) (net.Conn, error) {
+	name, err := Address()
+	if err != nil {
+		return nil, err
+	}
+	conn, err := winio.DialPipeContext(ctx, name)
+	if errors.Is(err, windows.ERROR_FILE_NOT_FOUND) {
+		return nil, fmt.Errorf("no Frago instance listening on %s", name)
+	}
+	return conn, err
+}
@@ -0,0 +1,33 @@
+package manifest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// ComposerFile is the Composer manifest whose "require.php" constraint is
+// used when a project sets none of its own.
+const ComposerFile = "composer.json"
+
+// ComposerPHP returns the PHP constraint required by the composer.json in
+// dir, such as "^8.2". It returns "" when the project has no composer.json
+// or the file does not require a PHP version.
+func ComposerPHP(dir string) (string, error) {
+	data, err := os.ReadFile(filepath.Join(dir, ComposerFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	var composer struct {
+		Require map[string]string `json:"require"`
+	}
+	if err := json.Unmarshal(data, &composer); err != nil {
+		return "", fmt.Errorf("%s: %w", ComposerFile, err)
+	}
+	return composer.Require["php"], nil
+}
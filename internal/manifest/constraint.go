@@ -0,0 +1,230 @@
+package manifest
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Constraint is a parsed PHP version constraint, written as in frago.toml or
+// in the "require.php" field of composer.json. A bare version such as "8.3"
+// matches every 8.3.x release, as does the wildcard "8.3.*". Comparisons
+// (>=, >, <=, <, =, !=), caret ranges (^8.3 means >=8.3, <9) and tilde
+// ranges (~8.3.1 means >=8.3.1, <8.4) can be combined with commas or spaces,
+// and all of them must match. Alternatives are separated by "||".
+type Constraint struct {
+	raw  string
+	alts [][]term
+}
+
+type term struct {
+	op      string
+	version []int
+}
+
+// operators are the comparison prefixes, longest first.
+var operators = []string{">=", "<=", "!=", "==", ">", "<", "=", "^", "~"}
+
+// ParseConstraint parses a constraint such as "8.3", ">=8.2, <8.4" or
+// "^8.2 || ^8.3".
+func ParseConstraint(s string) (Constraint, error) {
+	c := Constraint{raw: s}
+	for _, alt := range strings.Split(strings.ReplaceAll(s, "||", "|"), "|") {
+		terms, err := parseTerms(alt)
+		if err != nil {
+			return Constraint{}, fmt.Errorf("php constraint %q: %w", s, err)
+		}
+		c.alts = append(c.alts, terms)
+	}
+	return c, nil
+}
+
+// parseTerms parses the comparisons of one alternative. An operator may be
+// separated from its version by spaces, as in ">= 8.2".
+func parseTerms(s string) ([]term, error) {
+	fields := strings.Fields(strings.ReplaceAll(s, ",", " "))
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("missing version")
+	}
+	var terms []term
+	for i := 0; i < len(fields); i++ {
+		part := fields[i]
+		op := ""
+		for _, candidate := range operators {
+			if strings.HasPrefix(part, candidate) {
+				op = candidate
+				break
+			}
+		}
+		version := strings.TrimPrefix(part, op)
+		if version == "" && i+1 < len(fields) {
+			i++
+			version = fields[i]
+		}
+		if op == "==" {
+			op = "="
+		}
+		// Wildcards match like a bare version: "8.3.*" is "8.3", and "*"
+		// matches everything.
+		if op == "" && (version == "*" || strings.HasSuffix(version, ".*")) {
+			version = strings.TrimSuffix(strings.TrimSuffix(version, "*"), ".")
+			if version == "" {
+				terms = append(terms, term{op: "*"})
+				continue
+			}
+		}
+		v, err := parseVersion(version)
+		if err != nil {
+			return nil, err
+		}
+		terms = append(terms, term{op: op, version: v})
+	}
+	return terms, nil
+}
+
+// String returns the constraint as written.
+func (c Constraint) String() string {
+	return c.raw
+}
+
+// Matches reports whether version, such as "8.3.1", satisfies c. Versions
+// that cannot be parsed never match.
+func (c Constraint) Matches(version string) bool {
+	v, err := parseVersion(version)
+	if err != nil {
+		return false
+	}
+	for _, terms := range c.alts {
+		if matchesAll(v, terms) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesAll(v []int, terms []term) bool {
+	for _, t := range terms {
+		cmp := compareVersions(v, t.version)
+		var ok bool
+		switch t.op {
+		case "*":
+			ok = true
+		case "":
+			ok = hasPrefix(v, t.version)
+		case "=":
+			ok = cmp == 0
+		case "!=":
+			ok = cmp != 0
+		case ">=":
+			ok = cmp >= 0
+		case ">":
+			ok = cmp > 0
+		case "<=":
+			ok = cmp <= 0
+		case "<":
+			ok = cmp < 0
+		case "^":
+			ok = cmp >= 0 && compareVersions(v, caretLimit(t.version)) < 0
+		case "~":
+			ok = cmp >= 0 && compareVersions(v, tildeLimit(t.version)) < 0
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// caretLimit returns the first version excluded by ^v: the next major
+// release, or for 0.x the next release of the first non-zero component.
+func caretLimit(v []int) []int {
+	for i := 0; i < len(v)-1; i++ {
+		if v[i] != 0 {
+			return bump(v, i)
+		}
+	}
+	return bump(v, len(v)-1)
+}
+
+// tildeLimit returns the first version excluded by ~v: the last given
+// component may grow, the others may not. ~8 and ~8.3 both allow any 8.x.
+func tildeLimit(v []int) []int {
+	if len(v) == 1 {
+		return bump(v, 0)
+	}
+	return bump(v, len(v)-2)
+}
+
+// bump returns v up to component i, with that component incremented.
+func bump(v []int, i int) []int {
+	out := append([]int(nil), v[:i+1]...)
+	out[i]++
+	return out
+}
+
+func parseVersion(s string) ([]int, error) {
+	if s == "" {
+		return nil, fmt.Errorf("missing version")
+	}
+	parts := strings.Split(s, ".")
+	if len(parts) > 3 {
+		return nil, fmt.Errorf("invalid version %q", s)
+	}
+	version := make([]int, len(parts))
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid version %q", s)
+		}
+		version[i] = n
+	}
+	return version, nil
+}
+
+// CompareVersions compares two PHP versions such as "8.3.1" and "8.4",
+// returning -1, 0 or 1. Versions that cannot be parsed sort first.
+func CompareVersions(a, b string) int {
+	va, errA := parseVersion(a)
+	vb, errB := parseVersion(b)
+	switch {
+	case errA != nil && errB != nil:
+		return 0
+	case errA != nil:
+		return -1
+	case errB != nil:
+		return 1
+	}
+	return compareVersions(va, vb)
+}
+
+// compareVersions compares a and b with missing components treated as 0.
+func compareVersions(a, b []int) int {
+	for i := 0; i < 3; i++ {
+		var x, y int
+		if i < len(a) {
+			x = a[i]
+		}
+		if i < len(b) {
+			y = b[i]
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+func hasPrefix(v, prefix []int) bool {
+	if len(prefix) > len(v) {
+		return compareVersions(v, prefix) == 0
+	}
+	for i := range prefix {
+		if v[i] != prefix[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,311 @@
+// Package manifest reads the optional per-project settings file that teams
+// check into a repository: frago.toml or .frago.yaml in the project root.
+// A frago.local.toml or .frago.local.yaml next to it overrides individual
+// settings for one machine and is meant to be ignored by version control.
+package manifest
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+
+	"github.com/devmarvs/frago/internal/phpini"
+	"github.com/devmarvs/frago/internal/store"
+)
+
+// Shared and local file names, in lookup order.
+var (
+	SharedFiles = []string{"frago.toml", ".frago.yaml"}
+	LocalFiles  = []string{"frago.local.toml", ".frago.local.yaml"}
+)
+
+// Manifest holds project settings. Zero values mean "not set".
+type Manifest struct {
+	// Docroot is the directory served, relative to the project root.
+	Docroot string `toml:"docroot" yaml:"docroot"`
+	// Port is the preferred port used when none is chosen explicitly.
+	Port int `toml:"port" yaml:"port"`
+	// PHP is a version constraint such as "8.3" or ">=8.2, <8.4" used to
+	// pick a FrankenPHP binary when none is chosen explicitly.
+	PHP string `toml:"php" yaml:"php"`
+	// AutoStart starts the saved project when Frago starts.
+	AutoStart *bool `toml:"auto_start" yaml:"auto_start"`
+	// HealthPath is requested by health checks instead of "/".
+	HealthPath string `toml:"health_path" yaml:"health_path"`
+	// Env is added to the FrankenPHP process environment.
+	Env map[string]string `toml:"env" yaml:"env"`
+	// PHPIniPreset is a php.ini preset from phpini.Presets, such as
+	// "development".
+	PHPIniPreset string `toml:"php_ini_preset" yaml:"php_ini_preset"`
+	// PHPIni holds php.ini settings applied on top of the preset.
+	PHPIni map[string]string `toml:"php_ini" yaml:"php_ini"`
+	// Worker enables FrankenPHP worker mode.
+	Worker *Worker `toml:"worker" yaml:"worker"`
+	// DependsOn lists projects, as paths relative to this one, that must be
+	// running and ready before it starts.
+	DependsOn []string `toml:"depends_on" yaml:"depends_on"`
+	// Sidecars are companion processes, such as queue workers or a Vite dev
+	// server, keyed by name.
+	Sidecars map[string]Sidecar `toml:"sidecars" yaml:"sidecars"`
+
+	// Files lists the files the manifest was read from.
+	Files []string `toml:"-" yaml:"-"`
+}
+
+// Worker configures FrankenPHP worker mode.
+type Worker struct {
+	File string `toml:"file" yaml:"file"`
+	Num  int    `toml:"num" yaml:"num"`
+}
+
+// Sidecar is a command run alongside the project.
+type Sidecar struct {
+	// Command is the program and its arguments. A command starting with
+	// "php" runs with the project's FrankenPHP binary as "php-cli".
+	Command []string `toml:"command" yaml:"command"`
+	// Env is added to the environment of the project.
+	Env map[string]string `toml:"env" yaml:"env"`
+	// Restart is one of store.RestartPolicies.
+	Restart string `toml:"restart" yaml:"restart"`
+	// AutoStart starts the sidecar with the project. It defaults to true.
+	AutoStart *bool `toml:"auto_start" yaml:"auto_start"`
+}
+
+// StartsWithProject reports whether the sidecar starts with the project.
+func (s Sidecar) StartsWithProject() bool {
+	return s.AutoStart == nil || *s.AutoStart
+}
+
+var sidecarNameRegex = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+
+// Load reads the manifest of the project in dir, applying local overrides.
+// A project without any manifest file yields an empty Manifest.
+func Load(dir string) (Manifest, error) {
+	var m Manifest
+	for _, names := range [][]string{SharedFiles, LocalFiles} {
+		path, err := find(dir, names)
+		if err != nil {
+			return Manifest{}, err
+		}
+		if path == "" {
+			continue
+		}
+		layer, err := readFile(path)
+		if err != nil {
+			return Manifest{}, err
+		}
+		m.merge(layer)
+		m.Files = append(m.Files, path)
+	}
+	if err := m.Validate(); err != nil {
+		return Manifest{}, fmt.Errorf("%s: %w", strings.Join(m.Files, ", "), err)
+	}
+	return m, nil
+}
+
+// Validate reports settings that cannot be applied.
+func (m Manifest) Validate() error {
+	var errs []error
+	if m.Port < 0 || m.Port > 65535 {
+		errs = append(errs, fmt.Errorf("port %d is invalid; must be between 1 and 65535", m.Port))
+	}
+	if m.Docroot != "" && escapesProject(m.Docroot) {
+		errs = append(errs, fmt.Errorf("docroot %q must be inside the project", m.Docroot))
+	}
+	if m.HealthPath != "" && !strings.HasPrefix(m.HealthPath, "/") {
+		errs = append(errs, fmt.Errorf("health_path %q must start with /", m.HealthPath))
+	}
+	if m.PHP != "" {
+		if _, err := ParseConstraint(m.PHP); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if m.Worker != nil {
+		if m.Worker.File == "" {
+			errs = append(errs, errors.New("worker.file is required"))
+		} else if escapesProject(m.Worker.File) {
+			errs = append(errs, fmt.Errorf("worker.file %q must be inside the project", m.Worker.File))
+		}
+		if m.Worker.Num < 0 {
+			errs = append(errs, fmt.Errorf("worker.num %d must not be negative", m.Worker.Num))
+		}
+	}
+	for _, dep := range m.DependsOn {
+		if strings.TrimSpace(dep) == "" {
+			errs = append(errs, errors.New("depends_on entries must not be empty"))
+		}
+	}
+	errs = append(errs, validateEnv("env", m.Env)...)
+	for _, name := range m.SidecarNames() {
+		sc := m.Sidecars[name]
+		if !sidecarNameRegex.MatchString(name) {
+			errs = append(errs, fmt.Errorf("sidecar name %q may only contain letters, digits, '.', '-' and '_'", name))
+		}
+		if len(sc.Command) == 0 || sc.Command[0] == "" {
+			errs = append(errs, fmt.Errorf("sidecars.%s.command is required", name))
+		}
+		if !slices.Contains(store.RestartPolicies, sc.Restart) {
+			errs = append(errs, fmt.Errorf("sidecars.%s.restart %q is invalid; use %q or %q", name, sc.Restart, store.RestartOnFailure, store.RestartAlways))
+		}
+		errs = append(errs, validateEnv("sidecars."+name+".env", sc.Env)...)
+	}
+	if err := phpini.Validate(m.PHPIniPreset, m.PHPIni); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+func validateEnv(setting string, env map[string]string) []error {
+	var errs []error
+	for key := range env {
+		if key == "" || strings.ContainsAny(key, "=\x00") {
+			errs = append(errs, fmt.Errorf("%s name %q is invalid", setting, key))
+		}
+	}
+	return errs
+}
+
+// SidecarNames returns the names of the sidecars in sorted order.
+func (m Manifest) SidecarNames() []string {
+	names := make([]string, 0, len(m.Sidecars))
+	for name := range m.Sidecars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Dependencies returns DependsOn resolved against the project directory dir.
+func (m Manifest) Dependencies(dir string) []string {
+	deps := make([]string, 0, len(m.DependsOn))
+	for _, dep := range m.DependsOn {
+		dep = filepath.FromSlash(dep)
+		if !filepath.IsAbs(dep) {
+			dep = filepath.Join(dir, dep)
+		}
+		deps = append(deps, filepath.Clean(dep))
+	}
+	return deps
+}
+
+// Environ returns Env as sorted KEY=value pairs.
+func (m Manifest) Environ() []string {
+	env := make([]string, 0, len(m.Env))
+	for key, value := range m.Env {
+		env = append(env, key+"="+value)
+	}
+	sort.Strings(env)
+	return env
+}
+
+// merge applies the settings set in o on top of m. Env and PHPIni are merged
+// per key, and Sidecars per name.
+func (m *Manifest) merge(o Manifest) {
+	if o.Docroot != "" {
+		m.Docroot = o.Docroot
+	}
+	if o.Port != 0 {
+		m.Port = o.Port
+	}
+	if o.PHP != "" {
+		m.PHP = o.PHP
+	}
+	if o.AutoStart != nil {
+		m.AutoStart = o.AutoStart
+	}
+	if o.HealthPath != "" {
+		m.HealthPath = o.HealthPath
+	}
+	if o.Worker != nil {
+		m.Worker = o.Worker
+	}
+	if o.DependsOn != nil {
+		m.DependsOn = o.DependsOn
+	}
+	if len(o.Env) > 0 && m.Env == nil {
+		m.Env = make(map[string]string, len(o.Env))
+	}
+	for key, value := range o.Env {
+		m.Env[key] = value
+	}
+	if o.PHPIniPreset != "" {
+		m.PHPIniPreset = o.PHPIniPreset
+	}
+	if len(o.PHPIni) > 0 && m.PHPIni == nil {
+		m.PHPIni = make(map[string]string, len(o.PHPIni))
+	}
+	for key, value := range o.PHPIni {
+		m.PHPIni[key] = value
+	}
+	if len(o.Sidecars) > 0 && m.Sidecars == nil {
+		m.Sidecars = make(map[string]Sidecar, len(o.Sidecars))
+	}
+	for name, sc := range o.Sidecars {
+		m.Sidecars[name] = sc
+	}
+}
+
+// find returns the one file of names present in dir, or "" if none is.
+func find(dir string, names []string) (string, error) {
+	var found []string
+	for _, name := range names {
+		path := filepath.Join(dir, name)
+		if st, err := os.Stat(path); err == nil && !st.IsDir() {
+			found = append(found, path)
+		} else if err != nil && !os.IsNotExist(err) {
+			return "", err
+		}
+	}
+	switch len(found) {
+	case 0:
+		return "", nil
+	case 1:
+		return found[0], nil
+	default:
+		return "", fmt.Errorf("both %s and %s exist in %s; keep only one", names[0], names[1], dir)
+	}
+}
+
+func readFile(path string) (Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Manifest{}, err
+	}
+
+	var m Manifest
+	if strings.HasSuffix(path, ".toml") {
+		meta, err := toml.Decode(string(data), &m)
+		if err != nil {
+			return Manifest{}, fmt.Errorf("%s: %w", path, err)
+		}
+		if undecoded := meta.Undecoded(); len(undecoded) > 0 {
+			return Manifest{}, fmt.Errorf("%s: unknown setting %q", path, undecoded[0].String())
+		}
+		return m, nil
+	}
+
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&m); err != nil && !errors.Is(err, io.EOF) {
+		return Manifest{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return m, nil
+}
+
+func escapesProject(rel string) bool {
+	if filepath.IsAbs(rel) {
+		return true
+	}
+	clean := filepath.Clean(filepath.FromSlash(rel))
+	return clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator))
+}
@@ -0,0 +1,174 @@
+package manifest
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func writeFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+		t.Fatalf("write %s: %v", name, err)
+	}
+}
+
+func TestLoadWithoutManifest(t *testing.T) {
+	m, err := Load(t.TempDir())
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+	if !reflect.DeepEqual(m, Manifest{}) {
+		t.Fatalf("expected empty manifest, got %+v", m)
+	}
+}
+
+func TestLoadTOMLWithLocalOverride(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "frago.toml", `
+docroot = "public"
+port = 8100
+php = ">=8.2, <8.4"
+auto_start = true
+health_path = "/up"
+
+[env]
+APP_ENV = "dev"
+APP_DEBUG = "1"
+
+[php_ini]
+memory_limit = "256M"
+display_errors = "On"
+
+[worker]
+file = "public/index.php"
+num = 2
+
+[sidecars.queue]
+command = ["php", "artisan", "queue:work"]
+restart = "always"
+
+[sidecars.vite]
+command = ["npm", "run", "dev"]
+`)
+	writeFile(t, dir, ".frago.local.yaml", "port: 8200\nauto_start: false\nenv:\n  APP_DEBUG: \"0\"\nphp_ini_preset: development\nphp_ini:\n  memory_limit: 1G\nsidecars:\n  vite:\n    command: [npm, run, dev]\n    auto_start: false\n")
+
+	m, err := Load(dir)
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+	if m.Docroot != "public" || m.Port != 8200 || m.PHP != ">=8.2, <8.4" || m.HealthPath != "/up" {
+		t.Fatalf("unexpected manifest: %+v", m)
+	}
+	if m.AutoStart == nil || *m.AutoStart {
+		t.Fatalf("expected local auto_start=false, got %v", m.AutoStart)
+	}
+	if m.Worker == nil || m.Worker.File != "public/index.php" || m.Worker.Num != 2 {
+		t.Fatalf("unexpected worker: %+v", m.Worker)
+	}
+	if got, want := m.Environ(), []string{"APP_DEBUG=0", "APP_ENV=dev"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected env %v, got %v", want, got)
+	}
+	if want := map[string]string{"memory_limit": "1G", "display_errors": "On"}; m.PHPIniPreset != "development" || !reflect.DeepEqual(m.PHPIni, want) {
+		t.Fatalf("unexpected php_ini %q %v", m.PHPIniPreset, m.PHPIni)
+	}
+	if got := m.SidecarNames(); !reflect.DeepEqual(got, []string{"queue", "vite"}) {
+		t.Fatalf("unexpected sidecars %v", got)
+	}
+	if queue := m.Sidecars["queue"]; queue.Restart != "always" || !queue.StartsWithProject() || len(queue.Command) != 3 {
+		t.Fatalf("unexpected queue sidecar %+v", queue)
+	}
+	if m.Sidecars["vite"].StartsWithProject() {
+		t.Fatalf("expected local vite sidecar not to start with the project")
+	}
+	if len(m.Files) != 2 {
+		t.Fatalf("expected 2 source files, got %v", m.Files)
+	}
+}
+
+func TestLoadRejectsInvalidManifests(t *testing.T) {
+	cases := []struct {
+		name  string
+		files map[string]string
+		want  string
+	}{
+		{"unknown toml key", map[string]string{"frago.toml": "prot = 8080\n"}, "unknown setting"},
+		{"unknown yaml key", map[string]string{".frago.yaml": "prot: 8080\n"}, "prot"},
+		{"both shared files", map[string]string{"frago.toml": "", ".frago.yaml": ""}, "keep only one"},
+		{"docroot outside project", map[string]string{"frago.toml": "docroot = \"../other\"\n"}, "inside the project"},
+		{"bad constraint", map[string]string{"frago.toml": "php = \"eight\"\n"}, "php constraint"},
+		{"worker without file", map[string]string{"frago.toml": "[worker]\nnum = 2\n"}, "worker.file"},
+		{"sidecar without command", map[string]string{"frago.toml": "[sidecars.queue]\nrestart = \"always\"\n"}, "sidecars.queue.command"},
+		{"bad sidecar name", map[string]string{"frago.toml": "[sidecars.\"queue worker\"]\ncommand = [\"php\"]\n"}, "sidecar name"},
+		{"bad sidecar restart", map[string]string{"frago.toml": "[sidecars.queue]\ncommand = [\"php\"]\nrestart = \"sometimes\"\n"}, "sidecars.queue.restart"},
+		{"unknown php.ini preset", map[string]string{"frago.toml": "php_ini_preset = \"staging\"\n"}, "preset"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tc.files {
+				writeFile(t, dir, name, content)
+			}
+			if _, err := Load(dir); err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Fatalf("expected error containing %q, got %v", tc.want, err)
+			}
+		})
+	}
+}
+
+func TestConstraintMatches(t *testing.T) {
+	cases := []struct {
+		constraint string
+		version    string
+		want       bool
+	}{
+		{"8.3", "8.3.12", true},
+		{"8.3", "8.4.0", false},
+		{"8", "8.1.0", true},
+		{">=8.2", "8.2.0", true},
+		{">=8.2, <8.4", "8.4.1", false},
+		{">=8.2, <8.4", "8.3.9", true},
+		{"=8.3.1", "8.3.1", true},
+		{">8.3", "8.3.0", false},
+		{"8.3", "Unknown", false},
+		{"^8.2", "8.4.1", true},
+		{"^8.2", "9.0.0", false},
+		{"^8.2.5", "8.2.4", false},
+		{"~8.3.1", "8.3.9", true},
+		{"~8.3.1", "8.4.0", false},
+		{"~8.3", "8.4.0", true},
+		{"8.3.*", "8.3.2", true},
+		{"8.*", "7.4.0", false},
+		{"*", "7.4.0", true},
+		{">= 8.2 < 8.4", "8.3.0", true},
+		{"^7.4 || ^8.1", "8.3.0", true},
+		{"^7.4 | ~8.1.0", "8.2.0", false},
+		{"!=8.3.1", "8.3.1", false},
+	}
+	for _, tc := range cases {
+		c, err := ParseConstraint(tc.constraint)
+		if err != nil {
+			t.Fatalf("ParseConstraint(%q): %v", tc.constraint, err)
+		}
+		if got := c.Matches(tc.version); got != tc.want {
+			t.Fatalf("%q matches %q: expected %v, got %v", tc.constraint, tc.version, tc.want, got)
+		}
+	}
+}
+
+func TestComposerPHP(t *testing.T) {
+	dir := t.TempDir()
+	if php, err := ComposerPHP(dir); err != nil || php != "" {
+		t.Fatalf("expected no constraint without composer.json, got %q %v", php, err)
+	}
+	writeFile(t, dir, ComposerFile, `{"require": {"php": "^8.2", "laravel/framework": "^11.0"}}`)
+	if php, err := ComposerPHP(dir); err != nil || php != "^8.2" {
+		t.Fatalf("expected ^8.2, got %q %v", php, err)
+	}
+	writeFile(t, dir, ComposerFile, `{"require": `)
+	if _, err := ComposerPHP(dir); err == nil {
+		t.Fatalf("expected invalid composer.json to fail")
+	}
+}
@@ -0,0 +1,204 @@
+// Package phpini builds the php.ini overrides of a project: a preset such as
+// "development" with per-project settings layered on top, written to an ini
+// file that FrankenPHP loads through PHP_INI_SCAN_DIR.
+package phpini
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// FileName is the name of the generated ini file in a project's scan
+// directory.
+const FileName = "zz-frago.ini"
+
+// Preset names.
+const (
+	PresetNone        = ""
+	PresetDevelopment = "development"
+	PresetProduction  = "production"
+)
+
+// Presets maps preset names to their settings. The production preset mirrors
+// php.ini-production so problems that only show up there can be reproduced
+// locally.
+var Presets = map[string]map[string]string{
+	PresetDevelopment: {
+		"display_errors":              "On",
+		"display_startup_errors":      "On",
+		"error_reporting":             "E_ALL",
+		"log_errors":                  "On",
+		"memory_limit":                "512M",
+		"opcache.validate_timestamps": "1",
+		"opcache.revalidate_freq":     "0",
+		"zend.assertions":             "1",
+	},
+	PresetProduction: {
+		"display_errors":              "Off",
+		"display_startup_errors":      "Off",
+		"error_reporting":             "E_ALL & ~E_DEPRECATED & ~E_STRICT",
+		"log_errors":                  "On",
+		"memory_limit":                "128M",
+		"opcache.enable":              "1",
+		"opcache.validate_timestamps": "0",
+		"expose_php":                  "Off",
+		"zend.assertions":             "-1",
+	},
+}
+
+// PresetNames lists the presets in display order, starting with none.
+var PresetNames = []string{PresetNone, PresetDevelopment, PresetProduction}
+
+// CommonKeys are the settings shown alongside the overrides when the
+// effective values of a project are listed.
+var CommonKeys = []string{
+	"memory_limit", "max_execution_time", "display_errors", "error_reporting",
+	"upload_max_filesize", "post_max_size", "opcache.enable",
+	"opcache.validate_timestamps", "xdebug.mode",
+}
+
+// Extension directives may list several extensions, separated by commas;
+// each one is written on its own line.
+var extensionKeys = map[string]bool{"extension": true, "zend_extension": true}
+
+var keyRegex = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+
+// Setting is one effective php.ini value and the layer that set it.
+type Setting struct {
+	Key   string
+	Value string
+	// Source is "preset <name>" or the name given to Effective for the
+	// layer.
+	Source string
+}
+
+// Layer is a set of overrides with a name shown as their source.
+type Layer struct {
+	Name   string
+	Values map[string]string
+}
+
+// Validate checks that preset exists and that every key and value can be
+// written to an ini file.
+func Validate(preset string, values map[string]string) error {
+	if preset != PresetNone {
+		if _, ok := Presets[preset]; !ok {
+			return fmt.Errorf("php.ini preset %q is unknown; use %q or %q", preset, PresetDevelopment, PresetProduction)
+		}
+	}
+	for key, value := range values {
+		if !keyRegex.MatchString(key) {
+			return fmt.Errorf("php.ini setting %q is not a valid name", key)
+		}
+		if strings.ContainsAny(value, "\r\n") {
+			return fmt.Errorf("php.ini setting %s must fit on one line", key)
+		}
+	}
+	return nil
+}
+
+// Effective returns the settings of preset with layers applied in order,
+// later layers winning, sorted by key.
+func Effective(preset string, layers ...Layer) []Setting {
+	merged := make(map[string]Setting)
+	for key, value := range Presets[preset] {
+		merged[key] = Setting{key, value, "preset " + preset}
+	}
+	for _, l := range layers {
+		for key, value := range l.Values {
+			merged[key] = Setting{key, value, l.Name}
+		}
+	}
+	settings := make([]Setting, 0, len(merged))
+	for _, s := range merged {
+		settings = append(settings, s)
+	}
+	sort.Slice(settings, func(i, j int) bool { return settings[i].Key < settings[j].Key })
+	return settings
+}
+
+// Render returns settings in ini syntax. Values other than plain words and
+// numbers are quoted, except for error_reporting style expressions.
+func Render(settings []Setting) string {
+	var b strings.Builder
+	b.WriteString("; Generated by Frago. Changes are overwritten when the project starts.\n")
+	for _, s := range settings {
+		if extensionKeys[s.Key] {
+			for _, ext := range strings.Split(s.Value, ",") {
+				if ext = strings.TrimSpace(ext); ext != "" {
+					fmt.Fprintf(&b, "%s=%s\n", s.Key, ext)
+				}
+			}
+			continue
+		}
+		fmt.Fprintf(&b, "%s=%s\n", s.Key, quote(s.Value))
+	}
+	return b.String()
+}
+
+var plainValue = regexp.MustCompile(`^[A-Za-z0-9_.~&| -]*$`)
+
+func quote(value string) string {
+	if plainValue.MatchString(value) && !strings.HasPrefix(value, " ") && !strings.HasSuffix(value, " ") {
+		return value
+	}
+	return `"` + strings.ReplaceAll(value, `"`, `\"`) + `"`
+}
+
+// Dir returns the directory holding the generated ini files, one
+// subdirectory per project. FRAGO_PHP_INI_DIR overrides the default in the
+// user cache directory.
+func Dir() (string, error) {
+	if dir := os.Getenv("FRAGO_PHP_INI_DIR"); dir != "" {
+		return dir, nil
+	}
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "frago", "php-ini"), nil
+}
+
+// Write replaces the ini file in scanDir with settings. Without settings the
+// file is removed.
+func Write(scanDir string, settings []Setting) error {
+	path := filepath.Join(scanDir, FileName)
+	if len(settings) == 0 {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	if err := os.MkdirAll(scanDir, 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(Render(settings)), 0644)
+}
+
+// ScanDirEnv returns the PHP_INI_SCAN_DIR entry that adds scanDir to inherited,
+// the value PHP would otherwise see. The directories in inherited, or the one
+// PHP was built with when it is empty, are scanned first, so the settings in
+// scanDir win.
+func ScanDirEnv(inherited, scanDir string) string {
+	return "PHP_INI_SCAN_DIR=" + inherited + string(os.PathListSeparator) + scanDir
+}
+
+// Keys returns the keys of settings followed by the CommonKeys they do not
+// include.
+func Keys(settings []Setting) []string {
+	keys := make([]string, 0, len(settings)+len(CommonKeys))
+	for _, s := range settings {
+		keys = append(keys, s.Key)
+	}
+	for _, key := range CommonKeys {
+		if !slices.Contains(keys, key) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
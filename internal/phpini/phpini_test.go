@@ -0,0 +1,72 @@
+package phpini
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestEffectiveLayersOverPreset(t *testing.T) {
+	settings := Effective(PresetDevelopment,
+		Layer{Name: "manifest", Values: map[string]string{"memory_limit": "1G", "xdebug.mode": "debug"}},
+		Layer{Name: "project settings", Values: map[string]string{"xdebug.mode": "off"}},
+	)
+	got := make(map[string]Setting)
+	for _, s := range settings {
+		got[s.Key] = s
+	}
+	if s := got["memory_limit"]; s.Value != "1G" || s.Source != "manifest" {
+		t.Fatalf("unexpected memory_limit %+v", s)
+	}
+	if s := got["xdebug.mode"]; s.Value != "off" || s.Source != "project settings" {
+		t.Fatalf("unexpected xdebug.mode %+v", s)
+	}
+	if s := got["display_errors"]; s.Value != "On" || s.Source != "preset development" {
+		t.Fatalf("unexpected display_errors %+v", s)
+	}
+	if len(Effective(PresetNone)) != 0 {
+		t.Fatalf("expected no settings without a preset or layers")
+	}
+}
+
+func TestRenderAndWrite(t *testing.T) {
+	settings := []Setting{
+		{Key: "error_reporting", Value: "E_ALL & ~E_DEPRECATED"},
+		{Key: "extension", Value: "redis, imagick"},
+		{Key: "sendmail_path", Value: "/usr/sbin/sendmail -t"},
+	}
+	want := "error_reporting=E_ALL & ~E_DEPRECATED\nextension=redis\nextension=imagick\nsendmail_path=\"/usr/sbin/sendmail -t\"\n"
+	if got := Render(settings); !strings.HasSuffix(got, want) {
+		t.Fatalf("unexpected ini:\n%s", got)
+	}
+
+	dir := filepath.Join(t.TempDir(), "project")
+	if err := Write(dir, settings); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, FileName)); err != nil {
+		t.Fatalf("ini file not written: %v", err)
+	}
+	if err := Write(dir, nil); err != nil {
+		t.Fatalf("clear: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, FileName)); !os.IsNotExist(err) {
+		t.Fatalf("expected the ini file to be removed, got %v", err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	if err := Validate(PresetProduction, map[string]string{"opcache.jit": "tracing"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := Validate("staging", nil); err == nil {
+		t.Fatalf("expected an unknown preset to be rejected")
+	}
+	if err := Validate(PresetNone, map[string]string{"memory limit": "1G"}); err == nil {
+		t.Fatalf("expected an invalid name to be rejected")
+	}
+	if err := Validate(PresetNone, map[string]string{"memory_limit": "1G\nfoo=bar"}); err == nil {
+		t.Fatalf("expected a multi-line value to be rejected")
+	}
+}
@@ -0,0 +1,147 @@
+package runner
+
+import (
+	"bufio"
+	"context"
+	"os/exec"
+	"regexp"
+	"strings"
+)
+
+// BinaryInfo describes a FrankenPHP binary. Fields are empty when the binary
+// does not report them.
+type BinaryInfo struct {
+	Path       string `json:"path"`
+	FrankenPHP string `json:"frankenphp,omitempty"` // e.g. "v1.9.0"
+	PHP        string `json:"php,omitempty"`        // e.g. "8.4.11"
+	Caddy      string `json:"caddy,omitempty"`      // e.g. "v2.10.0"
+	Go         string `json:"go,omitempty"`         // e.g. "go1.24.4"
+	// ZTS reports a thread-safe PHP build, which FrankenPHP's worker mode
+	// needs.
+	ZTS  bool   `json:"zts"`
+	OS   string `json:"os,omitempty"`
+	Arch string `json:"arch,omitempty"`
+	// Extensions lists the compiled PHP and Zend extensions.
+	Extensions []string `json:"extensions,omitempty"`
+}
+
+var (
+	frankenPHPVersionRegex = regexp.MustCompile(`FrankenPHP (v\d+\.\d+\.\d+(?:-[0-9A-Za-z.-]+)?)`)
+	phpVersionRegex        = regexp.MustCompile(`PHP (\d+\.\d+\.\d+)`)
+	caddyVersionRegex      = regexp.MustCompile(`Caddy (v\d+\.\d+\.\d+(?:-[0-9A-Za-z.-]+)?)`)
+)
+
+// InspectBinary runs the FrankenPHP binary at path to collect its versions,
+// build information and PHP extensions. It fails only when "--version" does;
+// the details from "build-info" and "php-cli" are left empty when those
+// commands are unavailable.
+func InspectBinary(path string) (BinaryInfo, error) {
+	out, err := runVersionCommand(path)
+	if err != nil {
+		return BinaryInfo{}, err
+	}
+	info := parseVersionOutput(string(out))
+	info.Path = path
+
+	if out, err := runBinary(path, "build-info"); err == nil {
+		parseBuildInfo(string(out), &info)
+	}
+	if out, err := runBinary(path, "php-cli", "-v"); err == nil {
+		info.ZTS = strings.Contains(string(out), "(ZTS")
+		if info.PHP == "" {
+			info.PHP = firstMatch(phpVersionRegex, string(out))
+		}
+	}
+	if out, err := runBinary(path, "php-cli", "-m"); err == nil {
+		info.Extensions = parseModules(string(out))
+	}
+	return info, nil
+}
+
+// Summary returns the versions on one line, such as
+// "FrankenPHP v1.9.0 · PHP 8.4.11 (ZTS) · Caddy v2.10.0 · go1.24.4 · linux/amd64".
+func (b BinaryInfo) Summary() string {
+	var parts []string
+	if b.FrankenPHP != "" {
+		parts = append(parts, "FrankenPHP "+b.FrankenPHP)
+	}
+	if b.PHP != "" {
+		php := "PHP " + b.PHP
+		if b.ZTS {
+			php += " (ZTS)"
+		}
+		parts = append(parts, php)
+	}
+	if b.Caddy != "" {
+		parts = append(parts, "Caddy "+b.Caddy)
+	}
+	if b.Go != "" {
+		parts = append(parts, b.Go)
+	}
+	if b.OS != "" && b.Arch != "" {
+		parts = append(parts, b.OS+"/"+b.Arch)
+	}
+	return strings.Join(parts, " · ")
+}
+
+// parseVersionOutput reads the output of "frankenphp --version", e.g.
+// "FrankenPHP v1.9.0 PHP 8.4.11 Caddy v2.10.0 h1:...".
+func parseVersionOutput(out string) BinaryInfo {
+	return BinaryInfo{
+		FrankenPHP: firstMatch(frankenPHPVersionRegex, out),
+		PHP:        firstMatch(phpVersionRegex, out),
+		Caddy:      firstMatch(caddyVersionRegex, out),
+	}
+}
+
+// parseBuildInfo reads the Go version and target platform from the output
+// of "frankenphp build-info", which lists Go's debug.BuildInfo.
+func parseBuildInfo(out string, info *BinaryInfo) {
+	scanner := bufio.NewScanner(strings.NewReader(out))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 {
+			continue
+		}
+		switch fields[0] {
+		case "go":
+			info.Go = fields[1]
+		case "build":
+			if v, ok := strings.CutPrefix(fields[1], "GOOS="); ok {
+				info.OS = v
+			} else if v, ok := strings.CutPrefix(fields[1], "GOARCH="); ok {
+				info.Arch = v
+			}
+		}
+	}
+}
+
+// parseModules reads the extension names from the output of "php -m",
+// skipping its "[PHP Modules]" and "[Zend Modules]" headings.
+func parseModules(out string) []string {
+	var modules []string
+	seen := make(map[string]bool)
+	scanner := bufio.NewScanner(strings.NewReader(out))
+	for scanner.Scan() {
+		name := strings.TrimSpace(scanner.Text())
+		if name == "" || strings.HasPrefix(name, "[") || seen[name] {
+			continue
+		}
+		seen[name] = true
+		modules = append(modules, name)
+	}
+	return modules
+}
+
+func firstMatch(re *regexp.Regexp, s string) string {
+	if m := re.FindStringSubmatch(s); len(m) > 1 {
+		return m[1]
+	}
+	return ""
+}
+
+func runBinary(path string, args ...string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), versionCommandTimeout)
+	defer cancel()
+	return exec.CommandContext(ctx, path, args...).Output()
+}
@@ -0,0 +1,86 @@
+package runner
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+)
+
+const fakeFrankenPHP = `#!/bin/sh
+case "$1 $2" in
+"--version ") echo "FrankenPHP v1.9.0 PHP 8.4.11 Caddy v2.10.0 h1:abc=" ;;
+"build-info ") printf 'go\tgo1.24.4\npath\tgithub.com/dunglas/frankenphp/caddy/frankenphp\nbuild\tCGO_ENABLED=1\nbuild\tGOARCH=arm64\nbuild\tGOOS=darwin\n' ;;
+"php-cli -v") echo "PHP 8.4.11 (cli) (built: Aug  1 2025 10:00:00) (ZTS)" ;;
+"php-cli -m") printf '[PHP Modules]\nCore\nmbstring\nZend OPcache\n\n[Zend Modules]\nZend OPcache\n' ;;
+*) exit 1 ;;
+esac
+`
+
+func TestInspectBinary(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell script as the FrankenPHP binary")
+	}
+	path := filepath.Join(t.TempDir(), "frankenphp")
+	if err := os.WriteFile(path, []byte(fakeFrankenPHP), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := InspectBinary(path)
+	if err != nil {
+		t.Fatalf("inspect: %v", err)
+	}
+	want := BinaryInfo{
+		Path: path, FrankenPHP: "v1.9.0", PHP: "8.4.11", Caddy: "v2.10.0", Go: "go1.24.4",
+		ZTS: true, OS: "darwin", Arch: "arm64", Extensions: []string{"Core", "mbstring", "Zend OPcache"},
+	}
+	if !reflect.DeepEqual(info, want) {
+		t.Fatalf("unexpected info:\n got %+v\nwant %+v", info, want)
+	}
+	if got := want.Summary(); got != "FrankenPHP v1.9.0 · PHP 8.4.11 (ZTS) · Caddy v2.10.0 · go1.24.4 · darwin/arm64" {
+		t.Fatalf("unexpected summary %q", got)
+	}
+}
+
+func TestInspectBinaryWithoutPHPCLI(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell script as the FrankenPHP binary")
+	}
+	path := filepath.Join(t.TempDir(), "frankenphp")
+	script := "#!/bin/sh\n[ \"$1\" = --version ] || exit 1\necho 'FrankenPHP v1.0.0 PHP 8.2.7 Caddy v2.7.4'\n"
+	if err := os.WriteFile(path, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	info, err := InspectBinary(path)
+	if err != nil {
+		t.Fatalf("inspect: %v", err)
+	}
+	if info.PHP != "8.2.7" || info.Go != "" || info.ZTS || info.Extensions != nil {
+		t.Fatalf("unexpected info %+v", info)
+	}
+	if got := info.Summary(); got != "FrankenPHP v1.0.0 · PHP 8.2.7 · Caddy v2.7.4" {
+		t.Fatalf("unexpected summary %q", got)
+	}
+	if _, err := InspectBinary(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Fatalf("expected a missing binary to fail")
+	}
+}
+
+func TestQueryINI(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell script as the FrankenPHP binary")
+	}
+	path := filepath.Join(t.TempDir(), "frankenphp")
+	script := "#!/bin/sh\n[ \"$1 $2\" = \"php-cli -r\" ] || exit 1\nprintf 'memory_limit\\t%s\\nxdebug.mode\\n' \"$FRAGO_TEST_LIMIT\"\n"
+	if err := os.WriteFile(path, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	values, err := QueryINI(path, []string{"FRAGO_TEST_LIMIT=256M"}, []string{"memory_limit", "xdebug.mode"})
+	if err != nil {
+		t.Fatalf("query: %v", err)
+	}
+	if want := map[string]string{"memory_limit": "256M"}; !reflect.DeepEqual(values, want) {
+		t.Fatalf("expected %v, got %v", want, values)
+	}
+}
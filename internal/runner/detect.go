@@ -0,0 +1,343 @@
+package runner
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Detector finds FrankenPHP binaries on PATH, next to Frago, among the
+// managed builds and in a list of custom paths. The "--version" output of
+// each binary is cached by path, modification time and size, so a scan only
+// runs binaries that are new or changed.
+type Detector struct {
+	// CachePath is the JSON file holding the results of the last scan; the
+	// cache is kept in memory only when it is empty.
+	CachePath string
+	// CustomPath is the JSON file listing binaries added outside PATH.
+	CustomPath string
+
+	scanMu sync.Mutex // serializes scans
+
+	mu      sync.Mutex // guards entries and loaded
+	entries []detectEntry
+	loaded  bool
+}
+
+// detectEntry is the cached result for one candidate file.
+type detectEntry struct {
+	Name    string    `json:"name"`
+	Path    string    `json:"path"`
+	ModTime time.Time `json:"mod_time"`
+	Size    int64     `json:"size"`
+	// Valid is false for files that did not answer "--version".
+	Valid bool       `json:"valid"`
+	Info  BinaryInfo `json:"info"`
+}
+
+type detectCache struct {
+	Binaries []detectEntry `json:"binaries"`
+}
+
+type customBinaries struct {
+	Paths []string `json:"paths"`
+}
+
+var (
+	defaultDetectorOnce sync.Once
+	defaultDetector     *Detector
+)
+
+// DefaultDetector returns the detector shared by the process, caching in the
+// user cache directory and reading custom binaries from the user config
+// directory.
+func DefaultDetector() *Detector {
+	defaultDetectorOnce.Do(func() {
+		defaultDetector = &Detector{}
+		if dir, err := os.UserCacheDir(); err == nil {
+			defaultDetector.CachePath = filepath.Join(dir, "frago", "binaries.json")
+		}
+		if dir, err := os.UserConfigDir(); err == nil {
+			defaultDetector.CustomPath = filepath.Join(dir, "frago", "custom-binaries.json")
+		}
+	})
+	return defaultDetector
+}
+
+type candidate struct {
+	name, path string
+}
+
+// candidates lists the files to check, in the order they are reported.
+func (d *Detector) candidates() []candidate {
+	var list []candidate
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			name := entry.Name()
+			if strings.HasPrefix(strings.ToLower(name), "frankenphp") {
+				list = append(list, candidate{name, filepath.Join(dir, name)})
+			}
+		}
+	}
+
+	if bundled := DefaultFrankenPHPBinary(); bundled != "" {
+		list = append(list, candidate{filepath.Base(bundled), bundled})
+	}
+
+	if root, err := ManagedDir(); err == nil {
+		for _, build := range ManagedBuilds(root) {
+			list = append(list, candidate{"frankenphp " + build.Version, build.Path})
+		}
+	}
+
+	// Custom binaries are named by their full path so they stay distinct
+	// from a binary with the same name on PATH.
+	for _, path := range d.CustomBinaries() {
+		list = append(list, candidate{path, path})
+	}
+	return list
+}
+
+// Scan checks every candidate and returns the FrankenPHP binaries found.
+// Only binaries that are new or changed since the last scan are run. found,
+// when set, is called with each binary as soon as it is identified.
+func (d *Detector) Scan(found func(PHPVersion)) []PHPVersion {
+	d.scanMu.Lock()
+	defer d.scanMu.Unlock()
+
+	d.mu.Lock()
+	d.loadLocked()
+	cached := make(map[string]detectEntry, len(d.entries))
+	for _, e := range d.entries {
+		cached[e.Path] = e
+	}
+	d.mu.Unlock()
+
+	seen := make(map[string]bool)
+	var entries []detectEntry
+	var versions []PHPVersion
+	for _, c := range d.candidates() {
+		path := c.path
+		if !filepath.IsAbs(path) {
+			lookedUp, err := exec.LookPath(path)
+			if err != nil {
+				continue
+			}
+			path = lookedUp
+		}
+		realPath, err := filepath.EvalSymlinks(path)
+		if err != nil {
+			realPath = path
+		}
+		if seen[realPath] {
+			continue
+		}
+		seen[realPath] = true
+
+		st, err := os.Stat(path)
+		if err != nil || st.IsDir() {
+			continue
+		}
+		entry, ok := cached[path]
+		if !ok || entry.Size != st.Size() || !entry.ModTime.Equal(st.ModTime()) {
+			entry = probe(path, st)
+		}
+		entry.Name = c.name
+		entries = append(entries, entry)
+
+		if entry.Valid {
+			v := entry.version()
+			versions = append(versions, v)
+			if found != nil {
+				found(v)
+			}
+		}
+	}
+
+	d.mu.Lock()
+	d.entries = entries
+	d.saveLocked()
+	d.mu.Unlock()
+	return versions
+}
+
+// Cached returns the binaries found by the last scan that are unchanged on
+// disk, without running anything. It is meant for showing results at once
+// while a scan runs.
+func (d *Detector) Cached() []PHPVersion {
+	d.mu.Lock()
+	d.loadLocked()
+	entries := slices.Clone(d.entries)
+	d.mu.Unlock()
+
+	var versions []PHPVersion
+	for _, e := range entries {
+		st, err := os.Stat(e.Path)
+		if e.Valid && err == nil && st.Size() == e.Size && st.ModTime().Equal(e.ModTime) {
+			versions = append(versions, e.version())
+		}
+	}
+	return versions
+}
+
+// Invalidate forgets the cached results, so the next scan runs every binary
+// again.
+func (d *Detector) Invalidate() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.loadLocked()
+	d.entries = nil
+	d.saveLocked()
+}
+
+// CustomBinaries returns the binaries added with AddCustomBinary.
+func (d *Detector) CustomBinaries() []string {
+	if d.CustomPath == "" {
+		return nil
+	}
+	data, err := os.ReadFile(d.CustomPath)
+	if err != nil {
+		return nil
+	}
+	var custom customBinaries
+	if json.Unmarshal(data, &custom) != nil {
+		return nil
+	}
+	return custom.Paths
+}
+
+// AddCustomBinary checks that path is a FrankenPHP binary and adds it to the
+// binaries every scan reports.
+func (d *Detector) AddCustomBinary(path string) (PHPVersion, error) {
+	if d.CustomPath == "" {
+		return PHPVersion{}, fmt.Errorf("no configuration directory for custom binaries")
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return PHPVersion{}, err
+	}
+	st, err := os.Stat(abs)
+	if err != nil {
+		return PHPVersion{}, err
+	}
+	if st.IsDir() {
+		return PHPVersion{}, fmt.Errorf("%s is a directory", abs)
+	}
+	entry := probe(abs, st)
+	if !entry.Valid || entry.Info.FrankenPHP == "" {
+		return PHPVersion{}, fmt.Errorf("%s is not a FrankenPHP binary", abs)
+	}
+	entry.Name = abs
+
+	paths := d.CustomBinaries()
+	if !slices.Contains(paths, abs) {
+		paths = append(paths, abs)
+	}
+	if err := writeJSON(d.CustomPath, customBinaries{Paths: paths}); err != nil {
+		return PHPVersion{}, err
+	}
+	return entry.version(), nil
+}
+
+// RemoveCustomBinary removes path from the custom binaries.
+func (d *Detector) RemoveCustomBinary(path string) error {
+	paths := d.CustomBinaries()
+	i := slices.Index(paths, path)
+	if i < 0 {
+		return fmt.Errorf("%s is not a custom binary", path)
+	}
+	return writeJSON(d.CustomPath, customBinaries{Paths: slices.Delete(paths, i, i+1)})
+}
+
+// IsCustomBinary reports whether path was added with AddCustomBinary.
+func (d *Detector) IsCustomBinary(path string) bool {
+	return slices.Contains(d.CustomBinaries(), path)
+}
+
+// probe runs "--version" with the binary at path.
+func probe(path string, st os.FileInfo) detectEntry {
+	entry := detectEntry{Path: path, ModTime: st.ModTime(), Size: st.Size()}
+	out, err := runVersionCommand(path)
+	if err != nil {
+		return entry
+	}
+	entry.Valid = true
+	entry.Info = parseVersionOutput(string(out))
+	entry.Info.Path = path
+	return entry
+}
+
+func (e detectEntry) version() PHPVersion {
+	phpVer := e.Info.PHP
+	if phpVer == "" {
+		phpVer = "Unknown"
+	}
+	return PHPVersion{
+		Path:    e.Path,
+		Version: phpVer,
+		Label:   fmt.Sprintf("%s (PHP %s)", e.Name, phpVer),
+		Info:    e.Info,
+	}
+}
+
+func (d *Detector) loadLocked() {
+	if d.loaded {
+		return
+	}
+	d.loaded = true
+	if d.CachePath == "" {
+		return
+	}
+	data, err := os.ReadFile(d.CachePath)
+	if err != nil {
+		return
+	}
+	var cache detectCache
+	if json.Unmarshal(data, &cache) == nil {
+		d.entries = cache.Binaries
+	}
+}
+
+// saveLocked writes the cache. Failures are ignored; the next scan simply
+// runs the binaries again.
+func (d *Detector) saveLocked() {
+	if d.CachePath != "" {
+		writeJSON(d.CachePath, detectCache{Binaries: d.entries})
+	}
+}
+
+// writeJSON replaces path with v encoded as JSON.
+func writeJSON(path string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+"-*")
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), path)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+	}
+	return err
+}
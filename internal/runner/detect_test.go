@@ -0,0 +1,87 @@
+package runner
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// writeCountingBinary writes a fake frankenphp that reports version and
+// appends a line to calls on each run.
+func writeCountingBinary(t *testing.T, path, calls, version string) {
+	t.Helper()
+	script := "#!/bin/sh\necho run >> '" + calls + "'\necho 'FrankenPHP " + version + " PHP 8.4.1 Caddy v2.10.0'\n"
+	if err := os.WriteFile(path, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func runs(t *testing.T, calls string) int {
+	t.Helper()
+	data, _ := os.ReadFile(calls)
+	return strings.Count(string(data), "run")
+}
+
+func TestDetectorCachesByModTimeAndSize(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses shell scripts as FrankenPHP binaries")
+	}
+	t.Setenv("FRAGO_BINARIES_DIR", t.TempDir())
+	t.Setenv("FRANKENPHP_BINARY", filepath.Join(t.TempDir(), "missing"))
+	bin := t.TempDir()
+	t.Setenv("PATH", bin)
+	calls := filepath.Join(t.TempDir(), "calls")
+	writeCountingBinary(t, filepath.Join(bin, "frankenphp"), calls, "v1.9.0")
+
+	config := t.TempDir()
+	d := &Detector{CachePath: filepath.Join(config, "cache.json"), CustomPath: filepath.Join(config, "custom.json")}
+	var found []string
+	versions := d.Scan(func(v PHPVersion) { found = append(found, v.Label) })
+	if len(versions) != 1 || versions[0].Info.FrankenPHP != "v1.9.0" || len(found) != 1 || found[0] != "frankenphp (PHP 8.4.1)" {
+		t.Fatalf("unexpected scan %+v (found %v)", versions, found)
+	}
+
+	// A new detector reads the cache from disk and does not run the binary.
+	d = &Detector{CachePath: d.CachePath, CustomPath: d.CustomPath}
+	if cached := d.Cached(); len(cached) != 1 {
+		t.Fatalf("expected the cached binary, got %+v", cached)
+	}
+	d.Scan(nil)
+	if n := runs(t, calls); n != 1 {
+		t.Fatalf("expected one run with a warm cache, got %d", n)
+	}
+
+	writeCountingBinary(t, filepath.Join(bin, "frankenphp"), calls, "v1.10.0")
+	if v := d.Scan(nil); len(v) != 1 || v[0].Info.FrankenPHP != "v1.10.0" || runs(t, calls) != 2 {
+		t.Fatalf("changed binary was not run again: %+v", v)
+	}
+
+	custom := filepath.Join(t.TempDir(), "frankenphp-custom")
+	writeCountingBinary(t, custom, calls, "v1.8.0")
+	if _, err := d.AddCustomBinary(custom); err != nil {
+		t.Fatalf("add custom: %v", err)
+	}
+	versions = d.Scan(nil)
+	if len(versions) != 2 || versions[1].Path != custom || versions[1].Label != custom+" (PHP 8.4.1)" || !d.IsCustomBinary(custom) {
+		t.Fatalf("custom binary not reported: %+v", versions)
+	}
+	if err := d.RemoveCustomBinary(custom); err != nil {
+		t.Fatalf("remove custom: %v", err)
+	}
+	if versions = d.Scan(nil); len(versions) != 1 {
+		t.Fatalf("removed custom binary still reported: %+v", versions)
+	}
+
+	if _, err := d.AddCustomBinary(calls); err == nil {
+		t.Fatalf("expected a file that is not FrankenPHP to be rejected")
+	}
+
+	before := runs(t, calls)
+	d.Invalidate()
+	d.Scan(nil)
+	if runs(t, calls) != before+1 {
+		t.Fatalf("expected an invalidated cache to run the binary again")
+	}
+}
@@ -12,6 +12,9 @@ type LogBuffer struct {
 	lines   []string
 	max     int
 	partial string
+	// total counts every complete line ever appended; it is the cursor
+	// returned by Since.
+	total int64
 }
 
 func NewLogBuffer(max int) *LogBuffer {
@@ -39,10 +42,10 @@ func (b *LogBuffer) Write(p []byte) (int, error) {
 		return len(p), nil
 	}
 
-	if !strings.HasSuffix(text, "\n") {
-		b.partial = parts[len(parts)-1]
-		parts = parts[:len(parts)-1]
-	}
+	// The last element is either an unterminated line, kept until the rest
+	// arrives, or the empty string after a trailing newline.
+	b.partial = parts[len(parts)-1]
+	parts = parts[:len(parts)-1]
 
 	for _, line := range parts {
 		b.appendLine(line)
@@ -52,6 +55,7 @@ func (b *LogBuffer) Write(p []byte) (int, error) {
 }
 
 func (b *LogBuffer) appendLine(line string) {
+	b.total++
 	b.lines = append(b.lines, line)
 	if len(b.lines) > b.max {
 		b.lines = b.lines[len(b.lines)-b.max:]
@@ -83,6 +87,29 @@ func (b *LogBuffer) TailText(n int) string {
 	return strings.Join(b.Tail(n), "\n")
 }
 
+// Since returns the complete lines appended after cursor, along with the
+// cursor to pass next time. A negative cursor returns the last n lines
+// instead. Lines that have already been dropped from the buffer are skipped,
+// and a cursor from a cleared buffer restarts from the beginning.
+func (b *LogBuffer) Since(cursor int64, n int) ([]string, int64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	oldest := b.total - int64(len(b.lines))
+	start := 0
+	if cursor < 0 {
+		if n > 0 && n < len(b.lines) {
+			start = len(b.lines) - n
+		}
+	} else if cursor > oldest && cursor <= b.total {
+		start = int(min(cursor-oldest, int64(len(b.lines))))
+	}
+
+	out := make([]string, len(b.lines)-start)
+	copy(out, b.lines[start:])
+	return out, b.total
+}
+
 func (m *Manager) getOrCreateLogBufferLocked(dir string) *LogBuffer {
 	if m.logs == nil {
 		m.logs = make(map[string]*LogBuffer)
@@ -105,6 +132,18 @@ func (m *Manager) TailLogs(dir string, n int) string {
 	return buf.TailText(n)
 }
 
+// LogsSince returns the log lines for dir appended after cursor. See
+// LogBuffer.Since.
+func (m *Manager) LogsSince(dir string, cursor int64, n int) ([]string, int64) {
+	m.mu.Lock()
+	buf := m.logs[dir]
+	m.mu.Unlock()
+	if buf == nil {
+		return nil, 0
+	}
+	return buf.Since(cursor, n)
+}
+
 func (m *Manager) ClearLogs(dir string) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
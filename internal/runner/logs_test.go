@@ -0,0 +1,35 @@
+package runner
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestLogBufferSince(t *testing.T) {
+	buf := NewLogBuffer(3)
+	fmt.Fprint(buf, "a\nb\nc\npartial")
+
+	lines, next := buf.Since(-1, 2)
+	if !reflect.DeepEqual(lines, []string{"b", "c"}) || next != 3 {
+		t.Fatalf("tail: got %v, %d", lines, next)
+	}
+
+	fmt.Fprint(buf, "\nd\ne\n")
+	lines, next = buf.Since(next, 0)
+	if !reflect.DeepEqual(lines, []string{"partial", "d", "e"}) || next != 6 {
+		t.Fatalf("since: got %v, %d", lines, next)
+	}
+
+	// Lines dropped from the buffer are skipped rather than repeated.
+	lines, _ = buf.Since(1, 0)
+	if !reflect.DeepEqual(lines, []string{"partial", "d", "e"}) {
+		t.Fatalf("dropped: got %v", lines)
+	}
+
+	// A cursor beyond the buffer, e.g. after the logs were cleared, restarts.
+	lines, _ = NewLogBuffer(3).Since(10, 0)
+	if len(lines) != 0 {
+		t.Fatalf("cleared: got %v", lines)
+	}
+}
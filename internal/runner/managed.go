@@ -0,0 +1,55 @@
+package runner
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// ManagedDir returns the directory holding FrankenPHP builds downloaded by
+// Frago, one subdirectory per release tag. FRAGO_BINARIES_DIR overrides it.
+func ManagedDir() (string, error) {
+	if env := os.Getenv("FRAGO_BINARIES_DIR"); env != "" {
+		return env, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "frago", "frankenphp"), nil
+}
+
+// ManagedBinaryName is the file name of the FrankenPHP executable inside a
+// managed build directory.
+func ManagedBinaryName() string {
+	if runtime.GOOS == "windows" {
+		return "frankenphp.exe"
+	}
+	return "frankenphp"
+}
+
+// ManagedBuild is a FrankenPHP build in the managed directory.
+type ManagedBuild struct {
+	Version string `json:"version"` // release tag, e.g. "v1.9.0"
+	Path    string `json:"path"`
+}
+
+// ManagedBuilds lists the builds in root, sorted by directory name.
+func ManagedBuilds(root string) []ManagedBuild {
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil
+	}
+	var builds []ManagedBuild
+	for _, entry := range entries {
+		// Downloads in progress live in dot directories.
+		if !entry.IsDir() || entry.Name()[0] == '.' {
+			continue
+		}
+		path := filepath.Join(root, entry.Name(), ManagedBinaryName())
+		if st, err := os.Stat(path); err == nil && !st.IsDir() {
+			builds = append(builds, ManagedBuild{Version: entry.Name(), Path: path})
+		}
+	}
+	return builds
+}
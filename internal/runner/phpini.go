@@ -0,0 +1,35 @@
+package runner
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// iniQuery prints each ini setting named in its arguments as "key\tvalue",
+// or just "key" for settings PHP does not know.
+const iniQuery = `foreach (array_slice($argv, 1) as $k) { $v = ini_get($k); echo $v === false ? $k : $k . "\t" . $v, "\n"; }`
+
+// QueryINI asks the FrankenPHP binary at path for the values of the php.ini
+// settings keys, as "php-cli" sees them with env added to Frago's
+// environment. Settings PHP does not know, such as those of a missing
+// extension, are left out of the result.
+func QueryINI(path string, env []string, keys []string) (map[string]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), versionCommandTimeout)
+	defer cancel()
+	args := append([]string{"php-cli", "-r", iniQuery, "--"}, keys...)
+	cmd := exec.CommandContext(ctx, path, args...)
+	cmd.Env = append(os.Environ(), env...)
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[string]string)
+	for _, line := range strings.Split(string(out), "\n") {
+		if key, value, ok := strings.Cut(line, "\t"); ok {
+			values[key] = value
+		}
+	}
+	return values, nil
+}
@@ -26,10 +26,20 @@ type Process struct {
 	StartedAt    time.Time
 }
 
+// PID returns the operating system process ID, or 0 if the process has not started.
+func (p *Process) PID() int {
+	if p.Cmd == nil || p.Cmd.Process == nil {
+		return 0
+	}
+	return p.Cmd.Process.Pid
+}
+
 type ExitInfo struct {
 	When   time.Time
 	Err    string
 	Failed bool
+	// Requested is set when the process exited because Stop was called.
+	Requested bool
 }
 
 // Manager handles multiple FrankenPHP process states.
@@ -39,6 +49,13 @@ type Manager struct {
 	logs      map[string]*LogBuffer
 	exitInfo  map[string]ExitInfo
 	stopReq   map[string]bool
+	onExit    func(dir string, info ExitInfo)
+
+	sidecars       map[sidecarKey]*Sidecar
+	sidecarLogs    map[sidecarKey]*LogBuffer
+	sidecarExits   map[sidecarKey]ExitInfo
+	sidecarStopReq map[sidecarKey]bool
+	onSidecarExit  func(dir, name string, info ExitInfo)
 }
 
 // NewManager creates a new process manager.
@@ -48,6 +65,11 @@ func NewManager() *Manager {
 		logs:      make(map[string]*LogBuffer),
 		exitInfo:  make(map[string]ExitInfo),
 		stopReq:   make(map[string]bool),
+
+		sidecars:       make(map[sidecarKey]*Sidecar),
+		sidecarLogs:    make(map[sidecarKey]*LogBuffer),
+		sidecarExits:   make(map[sidecarKey]ExitInfo),
+		sidecarStopReq: make(map[sidecarKey]bool),
 	}
 }
 
@@ -80,6 +102,12 @@ func DefaultFrankenPHPBinary() string {
 
 // Start launches FrankenPHP in the given directory.
 func (m *Manager) Start(dir string, config *caddy.Config, binaryPath string, versionLabel string) error {
+	return m.StartWithEnv(dir, config, binaryPath, versionLabel, nil)
+}
+
+// StartWithEnv is Start with env, as KEY=value pairs, added to the
+// environment Frago itself runs with.
+func (m *Manager) StartWithEnv(dir string, config *caddy.Config, binaryPath string, versionLabel string, env []string) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
@@ -101,6 +129,9 @@ func (m *Manager) Start(dir string, config *caddy.Config, binaryPath string, ver
 
 	cmd := exec.Command(binaryPath, "run", "--config", config.Path)
 	cmd.Dir = dir
+	if len(env) > 0 {
+		cmd.Env = append(os.Environ(), env...)
+	}
 	cmd.Stdout = io.MultiWriter(os.Stdout, logBuffer)
 	cmd.Stderr = io.MultiWriter(os.Stderr, logBuffer)
 
@@ -126,6 +157,12 @@ func (m *Manager) Start(dir string, config *caddy.Config, binaryPath string, ver
 		err := p.Cmd.Wait()
 		m.recordExit(p.ID, err)
 		m.cleanup(p.ID)
+		m.mu.Lock()
+		info, onExit := m.exitInfo[p.ID], m.onExit
+		m.mu.Unlock()
+		if onExit != nil {
+			onExit(p.ID, info)
+		}
 	}(proc)
 
 	return nil
@@ -170,12 +207,22 @@ func (m *Manager) recordExit(id string, err error) {
 	}
 
 	m.exitInfo[id] = ExitInfo{
-		When:   time.Now(),
-		Err:    msg,
-		Failed: err != nil && !stopRequested,
+		When:      time.Now(),
+		Err:       msg,
+		Failed:    err != nil && !stopRequested,
+		Requested: stopRequested,
 	}
 }
 
+// SetExitHandler registers fn to be called after each process exits and is
+// cleaned up, from the goroutine that waited for it. It replaces any earlier
+// handler.
+func (m *Manager) SetExitHandler(fn func(dir string, info ExitInfo)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.onExit = fn
+}
+
 func (m *Manager) LastExit(dir string) (ExitInfo, bool) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -0,0 +1,239 @@
+package runner
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"time"
+)
+
+// SidecarStopGrace is how long a sidecar may take to exit after Stop asks it
+// to, for example to finish a queue job, before it is killed.
+const SidecarStopGrace = 5 * time.Second
+
+// Sidecar is a companion process of a project, such as a queue worker or a
+// Vite dev server. It runs in its own process group so that the processes it
+// starts are stopped with it.
+type Sidecar struct {
+	ProjectPath string
+	Name        string
+	Args        []string
+	Cmd         *exec.Cmd
+	StartedAt   time.Time
+	done        chan struct{}
+}
+
+// PID returns the operating system process ID, or 0 if the process has not started.
+func (p *Sidecar) PID() int {
+	if p.Cmd == nil || p.Cmd.Process == nil {
+		return 0
+	}
+	return p.Cmd.Process.Pid
+}
+
+type sidecarKey struct {
+	dir  string
+	name string
+}
+
+// StartSidecar runs args in dir as the sidecar name of the project in dir,
+// with env added to the environment Frago itself runs with.
+func (m *Manager) StartSidecar(dir, name string, args []string, env []string) error {
+	if len(args) == 0 {
+		return errors.New("sidecar command is empty")
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := sidecarKey{dir, name}
+	if _, exists := m.sidecars[key]; exists {
+		return fmt.Errorf("sidecar %s already running for directory: %s", name, dir)
+	}
+	delete(m.sidecarExits, key)
+	delete(m.sidecarStopReq, key)
+
+	logBuffer := m.sidecarLogs[key]
+	if logBuffer == nil {
+		logBuffer = NewLogBuffer(defaultLogMaxLines)
+		m.sidecarLogs[key] = logBuffer
+	}
+
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), env...)
+	cmd.Stdout = logBuffer
+	cmd.Stderr = logBuffer
+	setProcessGroup(cmd)
+
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	proc := &Sidecar{
+		ProjectPath: dir,
+		Name:        name,
+		Args:        append([]string(nil), args...),
+		Cmd:         cmd,
+		StartedAt:   time.Now(),
+		done:        make(chan struct{}),
+	}
+	m.sidecars[key] = proc
+
+	go func(p *Sidecar) {
+		err := p.Cmd.Wait()
+		close(p.done)
+		reapProcessGroup(p.Cmd.Process)
+		info, onExit := m.recordSidecarExit(key, err)
+		if onExit != nil {
+			onExit(key.dir, key.name, info)
+		}
+	}(proc)
+
+	return nil
+}
+
+func (m *Manager) recordSidecarExit(key sidecarKey, err error) (ExitInfo, func(dir, name string, info ExitInfo)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stopRequested := m.sidecarStopReq[key]
+	delete(m.sidecarStopReq, key)
+	delete(m.sidecars, key)
+
+	msg := ""
+	if err != nil {
+		msg = err.Error()
+	}
+	info := ExitInfo{
+		When:      time.Now(),
+		Err:       msg,
+		Failed:    err != nil && !stopRequested,
+		Requested: stopRequested,
+	}
+	m.sidecarExits[key] = info
+	return info, m.onSidecarExit
+}
+
+// StopSidecar asks the sidecar name of the project in dir to exit, and kills
+// it with the processes it started if it is still running after
+// SidecarStopGrace.
+func (m *Manager) StopSidecar(dir, name string) error {
+	key := sidecarKey{dir, name}
+	m.mu.Lock()
+	proc, exists := m.sidecars[key]
+	if exists {
+		m.sidecarStopReq[key] = true
+	}
+	m.mu.Unlock()
+
+	if !exists {
+		return fmt.Errorf("no sidecar %s running for directory: %s", name, dir)
+	}
+
+	if err := terminateProcessGroup(proc.Cmd.Process); err != nil {
+		if err := killProcessGroup(proc.Cmd.Process); err != nil {
+			m.mu.Lock()
+			delete(m.sidecarStopReq, key)
+			m.mu.Unlock()
+			return err
+		}
+		return nil
+	}
+	go func() {
+		select {
+		case <-proc.done:
+		case <-time.After(SidecarStopGrace):
+			_ = killProcessGroup(proc.Cmd.Process)
+		}
+	}()
+	return nil
+}
+
+// SetSidecarExitHandler registers fn to be called after each sidecar exits,
+// from the goroutine that waited for it. It replaces any earlier handler.
+func (m *Manager) SetSidecarExitHandler(fn func(dir, name string, info ExitInfo)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.onSidecarExit = fn
+}
+
+// GetSidecar returns a running sidecar of the project in dir.
+func (m *Manager) GetSidecar(dir, name string) (*Sidecar, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	p, ok := m.sidecars[sidecarKey{dir, name}]
+	return p, ok
+}
+
+// Sidecars returns the running sidecars of the project in dir, or of every
+// project when dir is empty, sorted by project and name.
+func (m *Manager) Sidecars(dir string) []*Sidecar {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var list []*Sidecar
+	for key, p := range m.sidecars {
+		if dir == "" || key.dir == dir {
+			list = append(list, p)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].ProjectPath != list[j].ProjectPath {
+			return list[i].ProjectPath < list[j].ProjectPath
+		}
+		return list[i].Name < list[j].Name
+	})
+	return list
+}
+
+// LastSidecarExit returns how the sidecar name of the project in dir last
+// exited.
+func (m *Manager) LastSidecarExit(dir, name string) (ExitInfo, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	info, ok := m.sidecarExits[sidecarKey{dir, name}]
+	return info, ok
+}
+
+// TailSidecarLogs returns the last n lines of output of a sidecar.
+func (m *Manager) TailSidecarLogs(dir, name string, n int) string {
+	m.mu.Lock()
+	buf := m.sidecarLogs[sidecarKey{dir, name}]
+	m.mu.Unlock()
+	if buf == nil {
+		return ""
+	}
+	return buf.TailText(n)
+}
+
+// SidecarLogsSince returns the log lines of a sidecar appended after cursor.
+// See LogBuffer.Since.
+func (m *Manager) SidecarLogsSince(dir, name string, cursor int64, n int) ([]string, int64) {
+	m.mu.Lock()
+	buf := m.sidecarLogs[sidecarKey{dir, name}]
+	m.mu.Unlock()
+	if buf == nil {
+		return nil, 0
+	}
+	return buf.Since(cursor, n)
+}
+
+// ClearSidecars forgets the logs and exit status of the sidecars of the
+// project in dir that are not running.
+func (m *Manager) ClearSidecars(dir string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for key := range m.sidecarLogs {
+		if _, running := m.sidecars[key]; key.dir == dir && !running {
+			delete(m.sidecarLogs, key)
+		}
+	}
+	for key := range m.sidecarExits {
+		if key.dir == dir {
+			delete(m.sidecarExits, key)
+		}
+	}
+}
@@ -0,0 +1,24 @@
+//go:build !unix && !windows
+
+package runner
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+)
+
+func setProcessGroup(cmd *exec.Cmd) {}
+
+// terminateProcessGroup is not supported without process groups;
+// StopSidecar kills the process instead.
+func terminateProcessGroup(p *os.Process) error {
+	return errors.ErrUnsupported
+}
+
+// killProcessGroup kills p only, as there are no process groups here.
+func killProcessGroup(p *os.Process) error {
+	return p.Kill()
+}
+
+func reapProcessGroup(p *os.Process) {}
@@ -0,0 +1,70 @@
+package runner
+
+import (
+	"runtime"
+	"testing"
+	"time"
+)
+
+func waitFor(t *testing.T, what string, done func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(3 * time.Second)
+	for !done() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func TestSidecarLifecycle(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh as the sidecar")
+	}
+	dir := t.TempDir()
+	m := NewManager()
+	exits := make(chan ExitInfo, 2)
+	m.SetSidecarExitHandler(func(d, name string, info ExitInfo) {
+		if d == dir {
+			exits <- info
+		}
+	})
+
+	// The child sleep must be stopped with the sidecar.
+	if err := m.StartSidecar(dir, "worker", []string{"sh", "-c", `echo "started $FRAGO_TEST"; sleep 60 & wait`}, []string{"FRAGO_TEST=ok"}); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	if err := m.StartSidecar(dir, "worker", []string{"sh"}, nil); err == nil {
+		t.Fatalf("expected an error starting a running sidecar again")
+	}
+	waitFor(t, "sidecar output", func() bool { return m.TailSidecarLogs(dir, "worker", 10) == "started ok" })
+	if list := m.Sidecars(dir); len(list) != 1 || list[0].Name != "worker" || list[0].PID() == 0 {
+		t.Fatalf("unexpected sidecars %+v", list)
+	}
+
+	if err := m.StopSidecar(dir, "worker"); err != nil {
+		t.Fatalf("stop: %v", err)
+	}
+	waitFor(t, "sidecar to stop", func() bool { _, running := m.GetSidecar(dir, "worker"); return !running })
+	if info := <-exits; !info.Requested || info.Failed {
+		t.Fatalf("unexpected exit after stop %+v", info)
+	}
+
+	if err := m.StartSidecar(dir, "crash", []string{"sh", "-c", "echo boom; exit 3"}, nil); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	if info := <-exits; !info.Failed || info.Requested {
+		t.Fatalf("unexpected exit after crash %+v", info)
+	}
+	if info, ok := m.LastSidecarExit(dir, "crash"); !ok || !info.Failed {
+		t.Fatalf("expected a failed exit, got %+v (%v)", info, ok)
+	}
+	if lines, _ := m.SidecarLogsSince(dir, "crash", -1, 10); len(lines) != 1 || lines[0] != "boom" {
+		t.Fatalf("unexpected crash logs %v", lines)
+	}
+
+	m.ClearSidecars(dir)
+	if _, ok := m.LastSidecarExit(dir, "crash"); ok || m.TailSidecarLogs(dir, "crash", 10) != "" {
+		t.Fatalf("expected cleared sidecar state")
+	}
+}
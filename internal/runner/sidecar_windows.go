@@ -0,0 +1,30 @@
+//go:build windows
+
+package runner
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"strconv"
+)
+
+func setProcessGroup(cmd *exec.Cmd) {}
+
+// terminateProcessGroup is not supported on Windows, which has no signal to
+// ask a console process to exit; StopSidecar kills the process tree instead.
+func terminateProcessGroup(p *os.Process) error {
+	return errors.ErrUnsupported
+}
+
+// killProcessGroup kills p and every process it started.
+func killProcessGroup(p *os.Process) error {
+	if err := exec.Command("taskkill", "/T", "/F", "/PID", strconv.Itoa(p.Pid)).Run(); err != nil {
+		return p.Kill()
+	}
+	return nil
+}
+
+// reapProcessGroup does nothing on Windows: once p has exited, its ID may
+// belong to another process tree.
+func reapProcessGroup(p *os.Process) {}
@@ -1,13 +1,8 @@
 package runner
 
 import (
-	"context"
 	"fmt"
-	"os"
-	"os/exec"
 	"path/filepath"
-	"regexp"
-	"strings"
 	"time"
 )
 
@@ -15,71 +10,18 @@ type PHPVersion struct {
 	Path    string `json:"path"`
 	Version string `json:"version"` // e.g., "8.2.1"
 	Label   string `json:"label"`   // e.g., "FrankenPHP (PHP 8.2.1)"
+	// Info holds the versions reported by "--version"; InspectBinary
+	// collects the rest.
+	Info BinaryInfo `json:"info"`
 }
 
 const versionCommandTimeout = 2 * time.Second
 
-// DetectVersions scans PATH for frankenphp binaries and extracts their PHP versions.
+// DetectVersions scans PATH, the directory of the Frago executable, the
+// builds downloaded by Frago and the custom binaries for frankenphp binaries
+// and extracts their PHP versions. Results are cached by DefaultDetector.
 func DetectVersions() ([]PHPVersion, error) {
-	pathEnv := os.Getenv("PATH")
-	paths := strings.Split(pathEnv, string(os.PathListSeparator))
-
-	uniquePaths := make(map[string]bool)
-	var versions []PHPVersion
-
-	versionRegex := regexp.MustCompile(`PHP (\d+\.\d+\.\d+)`)
-
-	addCandidate := func(name, fullPath string) {
-		realPath, err := filepath.EvalSymlinks(fullPath)
-		if err != nil {
-			realPath = fullPath
-		}
-
-		if uniquePaths[realPath] {
-			return
-		}
-		uniquePaths[realPath] = true
-
-		out, err := runVersionCommand(fullPath)
-		if err != nil {
-			return
-		}
-
-		matches := versionRegex.FindStringSubmatch(string(out))
-		phpVer := "Unknown"
-		if len(matches) > 1 {
-			phpVer = matches[1]
-		}
-
-		versions = append(versions, PHPVersion{
-			Path:    fullPath,
-			Version: phpVer,
-			Label:   fmt.Sprintf("%s (PHP %s)", name, phpVer),
-		})
-	}
-
-	for _, dir := range paths {
-		entries, err := os.ReadDir(dir)
-		if err != nil {
-			continue
-		}
-
-		for _, entry := range entries {
-			name := entry.Name()
-			if strings.HasPrefix(strings.ToLower(name), "frankenphp") {
-				fullPath := filepath.Join(dir, name)
-				addCandidate(name, fullPath)
-			}
-		}
-	}
-
-	bundled := DefaultFrankenPHPBinary()
-	if bundled != "" {
-		_, name := filepath.Split(bundled)
-		addCandidate(name, bundled)
-	}
-
-	return versions, nil
+	return DefaultDetector().Scan(nil), nil
 }
 
 // GetBinaryVersion returns the PHP version string for a given FrankenPHP binary path.
@@ -89,10 +31,8 @@ func GetBinaryVersion(path string) (string, error) {
 		return "", err
 	}
 
-	versionRegex := regexp.MustCompile(`PHP (\d+\.\d+\.\d+)`)
-	matches := versionRegex.FindStringSubmatch(string(out))
-	if len(matches) > 1 {
-		return matches[1], nil
+	if v := parseVersionOutput(string(out)).PHP; v != "" {
+		return v, nil
 	}
 	return "Unknown", fmt.Errorf("could not parse version")
 }
@@ -123,7 +63,8 @@ func FormatVersionLabel(binaryPath string, fallbackLabel string) string {
 	return fmt.Sprintf("%s (PHP %s)", label, phpVer)
 }
 
-// GetFrankenPHPVersion returns the FrankenPHP version string (e.g. "v1.1.0").
+// GetFrankenPHPVersion returns the FrankenPHP version string (e.g. "v1.1.0"
+// or "v1.10.0-rc.1").
 func GetFrankenPHPVersion(path string) (string, error) {
 	out, err := runVersionCommand(path)
 	if err != nil {
@@ -131,18 +72,12 @@ func GetFrankenPHPVersion(path string) (string, error) {
 	}
 
 	// Example output: "FrankenPHP v1.1.0 PHP 8.3.3 Caddy v2.7.6 ..."
-	versionRegex := regexp.MustCompile(`FrankenPHP (v\d+\.\d+\.\d+)`)
-	matches := versionRegex.FindStringSubmatch(string(out))
-	if len(matches) > 1 {
-		return matches[1], nil
+	if v := parseVersionOutput(string(out)).FrankenPHP; v != "" {
+		return v, nil
 	}
 	return "", fmt.Errorf("could not parse FrankenPHP version")
 }
 
 func runVersionCommand(path string) ([]byte, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), versionCommandTimeout)
-	defer cancel()
-
-	cmd := exec.CommandContext(ctx, path, "--version")
-	return cmd.Output()
+	return runBinary(path, "--version")
 }
@@ -0,0 +1,435 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/devmarvs/bebo"
+	"github.com/devmarvs/bebo/openapi"
+	"github.com/devmarvs/frago/pkg/fragoclient"
+)
+
+// APIVersion is the version reported in the OpenAPI document.
+const APIVersion = "1.0.0"
+
+// apiOperation documents one route registered by New. TestOpenAPICoversRoutes
+// fails when a route is added without an entry here, or the other way round.
+type apiOperation struct {
+	method    string
+	path      string
+	id        string
+	summary   string
+	tag       string
+	public    bool
+	params    []openapi.Parameter
+	request   any
+	responses map[int]any
+}
+
+// idParam describes the :id path parameter of the projects or workspaces
+// endpoints, depending on tag.
+func idParam(tag string) openapi.Parameter {
+	return openapi.Parameter{
+		Name:        "id",
+		In:          "path",
+		Required:    true,
+		Description: fmt.Sprintf("ID as returned by the %s endpoints.", tag),
+		Schema:      &openapi.Schema{Type: "string"},
+	}
+}
+
+// sidecarParam describes the :name path parameter of the sidecar endpoints.
+var sidecarParam = openapi.Parameter{
+	Name:        "name",
+	In:          "path",
+	Required:    true,
+	Description: "Sidecar name from the project manifest.",
+	Schema:      &openapi.Schema{Type: "string"},
+}
+
+var apiOperations = []apiOperation{
+	{
+		method: http.MethodGet, path: "/health", id: "health", tag: "system", public: true,
+		summary:   "Check that the API is up",
+		responses: map[int]any{http.StatusOK: fragoclient.HealthResponse{}},
+	},
+	{
+		method: http.MethodGet, path: "/api/openapi.json", id: "openapi", tag: "system", public: true,
+		summary:   "Fetch this OpenAPI document",
+		responses: map[int]any{http.StatusOK: nil},
+	},
+	{
+		method: http.MethodGet, path: "/api/status", id: "getStatus", tag: "processes",
+		summary:   "List running and saved projects with health and stats",
+		responses: map[int]any{http.StatusOK: fragoclient.StatusResponse{}},
+	},
+	{
+		method: http.MethodPost, path: "/api/run", id: "run", tag: "processes",
+		summary: "Start FrankenPHP for a project directory",
+		request: fragoclient.RunRequest{},
+		responses: map[int]any{
+			http.StatusOK:                  fragoclient.RunResponse{},
+			http.StatusBadRequest:          fragoclient.ErrorResponse{},
+			http.StatusInternalServerError: fragoclient.ErrorResponse{},
+		},
+	},
+	{
+		method: http.MethodPost, path: "/api/stop", id: "stop", tag: "processes",
+		summary: "Stop a running project",
+		request: fragoclient.RunRequest{},
+		responses: map[int]any{
+			http.StatusOK:                  fragoclient.StopResponse{},
+			http.StatusBadRequest:          fragoclient.ErrorResponse{},
+			http.StatusInternalServerError: fragoclient.ErrorResponse{},
+		},
+	},
+	{
+		method: http.MethodPost, path: "/api/restart", id: "restart", tag: "processes",
+		summary: "Restart a saved project with its saved settings",
+		request: fragoclient.RunRequest{},
+		responses: map[int]any{
+			http.StatusOK:                  fragoclient.RunResponse{},
+			http.StatusBadRequest:          fragoclient.ErrorResponse{},
+			http.StatusNotFound:            fragoclient.ErrorResponse{},
+			http.StatusInternalServerError: fragoclient.ErrorResponse{},
+		},
+	},
+	{
+		method: http.MethodGet, path: "/api/logs", id: "getLogs", tag: "processes",
+		summary: "Read a project's log output",
+		params: []openapi.Parameter{
+			{Name: "project_path", In: "query", Required: true, Schema: &openapi.Schema{Type: "string"}},
+			{Name: "since", In: "query", Description: "Cursor from a previous response; only newer lines are returned.", Schema: &openapi.Schema{Type: "integer", Format: "int64"}},
+			{Name: "lines", In: "query", Description: "Number of lines to return when since is omitted. Defaults to 200.", Schema: &openapi.Schema{Type: "integer"}},
+			{Name: "sidecar", In: "query", Description: "Return the output of this sidecar of the project instead.", Schema: &openapi.Schema{Type: "string"}},
+		},
+		responses: map[int]any{
+			http.StatusOK:         fragoclient.LogsResponse{},
+			http.StatusBadRequest: fragoclient.ErrorResponse{},
+		},
+	},
+	{
+		method: http.MethodPost, path: "/api/start-all", id: "startAll", tag: "processes",
+		summary:   "Start every saved project that is not running",
+		responses: map[int]any{http.StatusOK: fragoclient.BulkResponse{}},
+	},
+	{
+		method: http.MethodPost, path: "/api/stop-all", id: "stopAll", tag: "processes",
+		summary:   "Stop every running project",
+		responses: map[int]any{http.StatusOK: fragoclient.BulkResponse{}},
+	},
+	{
+		method: http.MethodGet, path: "/api/projects", id: "listProjects", tag: "projects",
+		summary:   "List saved projects",
+		responses: map[int]any{http.StatusOK: fragoclient.ProjectList{}},
+	},
+	{
+		method: http.MethodPost, path: "/api/projects", id: "createProject", tag: "projects",
+		summary: "Save a project directory",
+		request: fragoclient.ProjectRequest{},
+		responses: map[int]any{
+			http.StatusCreated:    fragoclient.Project{},
+			http.StatusBadRequest: fragoclient.ErrorResponse{},
+			http.StatusConflict:   fragoclient.ErrorResponse{},
+		},
+	},
+	{
+		method: http.MethodGet, path: "/api/projects/:id", id: "getProject", tag: "projects",
+		summary: "Fetch a saved project",
+		responses: map[int]any{
+			http.StatusOK:       fragoclient.Project{},
+			http.StatusNotFound: fragoclient.ErrorResponse{},
+		},
+	},
+	{
+		method: http.MethodGet, path: "/api/projects/:id/php-ini", id: "getProjectPHPIni", tag: "projects",
+		summary: "List the php.ini values Frago applies to a project",
+		responses: map[int]any{
+			http.StatusOK:                  fragoclient.PHPIniResponse{},
+			http.StatusNotFound:            fragoclient.ErrorResponse{},
+			http.StatusInternalServerError: fragoclient.ErrorResponse{},
+		},
+	},
+	{
+		method: http.MethodGet, path: "/api/projects/:id/debug", id: "getProjectDebug", tag: "projects",
+		summary: "Fetch a project's Xdebug settings",
+		responses: map[int]any{
+			http.StatusOK:       fragoclient.DebugResponse{},
+			http.StatusNotFound: fragoclient.ErrorResponse{},
+		},
+	},
+	{
+		method: http.MethodPost, path: "/api/projects/:id/debug", id: "setProjectDebug", tag: "projects",
+		summary: "Turn Xdebug on or off for a project, restarting it when running",
+		request: fragoclient.DebugRequest{},
+		responses: map[int]any{
+			http.StatusOK:                  fragoclient.DebugResponse{},
+			http.StatusBadRequest:          fragoclient.ErrorResponse{},
+			http.StatusNotFound:            fragoclient.ErrorResponse{},
+			http.StatusInternalServerError: fragoclient.ErrorResponse{},
+		},
+	},
+	{
+		method: http.MethodGet, path: "/api/projects/:id/sidecars", id: "listSidecars", tag: "projects",
+		summary: "List the sidecars of a project with their state and stats",
+		responses: map[int]any{
+			http.StatusOK:                  fragoclient.SidecarsResponse{},
+			http.StatusNotFound:            fragoclient.ErrorResponse{},
+			http.StatusInternalServerError: fragoclient.ErrorResponse{},
+		},
+	},
+	{
+		method: http.MethodPost, path: "/api/projects/:id/sidecars/:name/start", id: "startSidecar", tag: "projects",
+		summary: "Start a sidecar of a project",
+		params:  []openapi.Parameter{sidecarParam},
+		responses: map[int]any{
+			http.StatusOK:                  fragoclient.SidecarStatus{},
+			http.StatusNotFound:            fragoclient.ErrorResponse{},
+			http.StatusInternalServerError: fragoclient.ErrorResponse{},
+		},
+	},
+	{
+		method: http.MethodPost, path: "/api/projects/:id/sidecars/:name/stop", id: "stopSidecar", tag: "projects",
+		summary: "Ask a running sidecar of a project to exit",
+		params:  []openapi.Parameter{sidecarParam},
+		responses: map[int]any{
+			http.StatusOK:                  fragoclient.SidecarStatus{},
+			http.StatusNotFound:            fragoclient.ErrorResponse{},
+			http.StatusInternalServerError: fragoclient.ErrorResponse{},
+		},
+	},
+	{
+		method: http.MethodPost, path: "/api/projects/:id/sidecars/:name/restart", id: "restartSidecar", tag: "projects",
+		summary: "Restart a sidecar of a project",
+		params:  []openapi.Parameter{sidecarParam},
+		responses: map[int]any{
+			http.StatusOK:                  fragoclient.SidecarStatus{},
+			http.StatusNotFound:            fragoclient.ErrorResponse{},
+			http.StatusInternalServerError: fragoclient.ErrorResponse{},
+		},
+	},
+	{
+		method: http.MethodPatch, path: "/api/projects/:id", id: "updateProject", tag: "projects",
+		summary: "Update a saved project's settings",
+		request: fragoclient.ProjectRequest{},
+		responses: map[int]any{
+			http.StatusOK:         fragoclient.Project{},
+			http.StatusBadRequest: fragoclient.ErrorResponse{},
+			http.StatusNotFound:   fragoclient.ErrorResponse{},
+		},
+	},
+	{
+		method: http.MethodDelete, path: "/api/projects/:id", id: "deleteProject", tag: "projects",
+		summary: "Remove a stopped project",
+		responses: map[int]any{
+			http.StatusOK:       fragoclient.DeleteResponse{},
+			http.StatusNotFound: fragoclient.ErrorResponse{},
+			http.StatusConflict: fragoclient.ErrorResponse{},
+		},
+	},
+	{
+		method: http.MethodGet, path: "/api/workspaces", id: "listWorkspaces", tag: "workspaces",
+		summary:   "List workspaces",
+		responses: map[int]any{http.StatusOK: fragoclient.WorkspaceList{}},
+	},
+	{
+		method: http.MethodPost, path: "/api/workspaces", id: "createWorkspace", tag: "workspaces",
+		summary: "Create a workspace",
+		request: fragoclient.WorkspaceRequest{},
+		responses: map[int]any{
+			http.StatusCreated:    fragoclient.Workspace{},
+			http.StatusBadRequest: fragoclient.ErrorResponse{},
+			http.StatusConflict:   fragoclient.ErrorResponse{},
+		},
+	},
+	{
+		method: http.MethodGet, path: "/api/workspaces/:id", id: "getWorkspace", tag: "workspaces",
+		summary: "Fetch a workspace",
+		responses: map[int]any{
+			http.StatusOK:       fragoclient.Workspace{},
+			http.StatusNotFound: fragoclient.ErrorResponse{},
+		},
+	},
+	{
+		method: http.MethodPatch, path: "/api/workspaces/:id", id: "updateWorkspace", tag: "workspaces",
+		summary: "Rename a workspace or change its projects and start order",
+		request: fragoclient.WorkspaceRequest{},
+		responses: map[int]any{
+			http.StatusOK:         fragoclient.Workspace{},
+			http.StatusBadRequest: fragoclient.ErrorResponse{},
+			http.StatusNotFound:   fragoclient.ErrorResponse{},
+			http.StatusConflict:   fragoclient.ErrorResponse{},
+		},
+	},
+	{
+		method: http.MethodDelete, path: "/api/workspaces/:id", id: "deleteWorkspace", tag: "workspaces",
+		summary: "Remove a workspace; its projects stay saved",
+		responses: map[int]any{
+			http.StatusOK:       fragoclient.DeleteResponse{},
+			http.StatusNotFound: fragoclient.ErrorResponse{},
+		},
+	},
+	{
+		method: http.MethodPost, path: "/api/workspaces/:id/start", id: "startWorkspace", tag: "workspaces",
+		summary: "Start a workspace's projects in start order",
+		responses: map[int]any{
+			http.StatusOK:       fragoclient.BulkResponse{},
+			http.StatusNotFound: fragoclient.ErrorResponse{},
+		},
+	},
+	{
+		method: http.MethodPost, path: "/api/workspaces/:id/stop", id: "stopWorkspace", tag: "workspaces",
+		summary: "Stop a workspace's projects in reverse start order",
+		responses: map[int]any{
+			http.StatusOK:       fragoclient.BulkResponse{},
+			http.StatusNotFound: fragoclient.ErrorResponse{},
+		},
+	},
+}
+
+// OpenAPI returns the OpenAPI 3 document describing the API.
+func OpenAPI() *openapi.Document {
+	b := openapi.New(openapi.Info{
+		Title:       "Frago API",
+		Version:     APIVersion,
+		Description: "Control FrankenPHP projects managed by Frago.",
+	})
+	b.AddServer(openapi.Server{URL: "http://127.0.0.1:8080", Description: "Default TCP address"})
+	b.AddTag(openapi.Tag{Name: "processes", Description: "Start and stop projects"})
+	b.AddTag(openapi.Tag{Name: "projects", Description: "Saved project settings"})
+	b.AddTag(openapi.Tag{Name: "workspaces", Description: "Groups of projects started and stopped together"})
+	b.AddTag(openapi.Tag{Name: "system"})
+	b.AddSecurityScheme("bearerAuth", openapi.SecurityScheme{
+		Type:        "http",
+		Scheme:      "bearer",
+		Description: "API token from the discovery file. Not required over the local socket.",
+	})
+	b.AddSecurityScheme("tokenHeader", openapi.SecurityScheme{
+		Type: "apiKey",
+		Name: "X-Frago-Token",
+		In:   "header",
+	})
+
+	for _, op := range apiOperations {
+		doc := openapi.Operation{
+			Summary:     op.summary,
+			OperationID: op.id,
+			Tags:        []string{op.tag},
+			Responses:   map[string]openapi.Response{},
+		}
+		if !op.public {
+			doc.Security = []map[string][]string{{"bearerAuth": {}}, {"tokenHeader": {}}}
+			doc.Responses["401"] = jsonResponse(b, http.StatusUnauthorized, fragoclient.ErrorResponse{})
+		}
+		doc.Parameters = op.params
+		if strings.Contains(op.path, ":id") {
+			doc.Parameters = append([]openapi.Parameter{idParam(op.tag)}, doc.Parameters...)
+		}
+		if op.request != nil {
+			doc.RequestBody = &openapi.RequestBody{
+				Required: true,
+				Content: map[string]openapi.MediaType{
+					"application/json": {Schema: schemaRef(b, reflect.TypeOf(op.request))},
+				},
+			}
+		}
+		for code, body := range op.responses {
+			doc.Responses[strconv.Itoa(code)] = jsonResponse(b, code, body)
+		}
+		_ = b.AddRoute(op.method, openAPIPath(op.path), doc)
+	}
+	return b.Document()
+}
+
+func openAPIHandler() bebo.Handler {
+	doc := OpenAPI()
+	return func(ctx *bebo.Context) error {
+		return ctx.JSON(http.StatusOK, doc)
+	}
+}
+
+func jsonResponse(b *openapi.Builder, code int, body any) openapi.Response {
+	resp := openapi.Response{Description: http.StatusText(code)}
+	if body == nil {
+		return resp
+	}
+	resp.Content = map[string]openapi.MediaType{
+		"application/json": {Schema: schemaRef(b, reflect.TypeOf(body))},
+	}
+	return resp
+}
+
+// openAPIPath converts a bebo route pattern such as /projects/:id to the
+// OpenAPI form /projects/{id}.
+func openAPIPath(pattern string) string {
+	parts := strings.Split(pattern, "/")
+	for i, part := range parts {
+		if strings.HasPrefix(part, ":") {
+			parts[i] = "{" + part[1:] + "}"
+		}
+	}
+	return strings.Join(parts, "/")
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// schemaRef returns a schema for t, registering named structs as components
+// so they are referenced instead of inlined.
+func schemaRef(b *openapi.Builder, t reflect.Type) *openapi.Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch {
+	case t == timeType:
+		return &openapi.Schema{Type: "string", Format: "date-time"}
+	case t.Kind() == reflect.Struct:
+		components := b.Document().Components
+		if components == nil || components.Schemas == nil || components.Schemas[t.Name()].Type == "" {
+			// Register a placeholder first so recursive types terminate.
+			b.AddSchema(t.Name(), openapi.Schema{Type: "object"})
+			b.AddSchema(t.Name(), structSchema(b, t))
+		}
+		return &openapi.Schema{Ref: "#/components/schemas/" + t.Name()}
+	case t.Kind() == reflect.Slice:
+		return &openapi.Schema{Type: "array", Items: schemaRef(b, t.Elem())}
+	case t.Kind() == reflect.String:
+		return &openapi.Schema{Type: "string"}
+	case t.Kind() == reflect.Bool:
+		return &openapi.Schema{Type: "boolean"}
+	case t.Kind() == reflect.Float32 || t.Kind() == reflect.Float64:
+		return &openapi.Schema{Type: "number", Format: "double"}
+	case t.Kind() == reflect.Int64:
+		return &openapi.Schema{Type: "integer", Format: "int64"}
+	case t.Kind() >= reflect.Int && t.Kind() <= reflect.Uint64:
+		return &openapi.Schema{Type: "integer"}
+	default:
+		return &openapi.Schema{Type: "object"}
+	}
+}
+
+func structSchema(b *openapi.Builder, t reflect.Type) openapi.Schema {
+	schema := openapi.Schema{Type: "object", Properties: map[string]openapi.Schema{}}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, opts, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		schema.Properties[name] = *schemaRef(b, field.Type)
+		optional := strings.Contains(opts, "omitempty") || strings.Contains(opts, "omitzero")
+		if !optional && field.Type.Kind() != reflect.Pointer {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+	return schema
+}
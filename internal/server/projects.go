@@ -0,0 +1,318 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/devmarvs/bebo"
+	"github.com/devmarvs/frago/internal/manifest"
+	"github.com/devmarvs/frago/internal/phpini"
+	"github.com/devmarvs/frago/internal/service"
+	"github.com/devmarvs/frago/internal/store"
+	"github.com/devmarvs/frago/pkg/fragoclient"
+)
+
+func registerProjectRoutes(api *bebo.Group, svc *service.Service) {
+	mgr := svc.Manager()
+	projects := svc.Projects()
+
+	view := func(p store.Project) fragoclient.Project {
+		_, running := mgr.Get(p.Path)
+		return fragoclient.Project{
+			ID:               p.ID,
+			Path:             p.Path,
+			PreferredPort:    p.PreferredPort,
+			LastPort:         p.LastPort,
+			LastURL:          p.LastURL,
+			LastVersionLabel: p.LastVersionLabel,
+			LastBinaryPath:   p.LastBinaryPath,
+			Pinned:           p.Pinned,
+			AutoStart:        p.AutoStart,
+			LastUsed:         p.LastUsed,
+			DependsOn:        p.DependsOn,
+			Name:             p.Name,
+			Tags:             p.Tags,
+			PHP:              p.PHP,
+			PHPIniPreset:     p.PHPIniPreset,
+			PHPIni:           p.PHPIni,
+			Debug:            p.Xdebug.Enabled,
+			Running:          running,
+		}
+	}
+
+	// checkRequest validates the fields req sets against p and returns req
+	// normalized: the binary path resolved, dependencies as saved paths, and
+	// names, tags and settings trimmed. It runs outside the store lock, as it
+	// looks up other projects and runs the binary.
+	checkRequest := func(req fragoclient.ProjectRequest, p store.Project) (fragoclient.ProjectRequest, string) {
+		if req.PreferredPort != nil && (*req.PreferredPort < 0 || *req.PreferredPort > 65535) {
+			return req, "preferred_port must be between 1 and 65535, or 0 to clear"
+		}
+		if req.BinaryPath != nil && *req.BinaryPath != "" {
+			resolved, msg := validateBinaryPath(*req.BinaryPath, svc)
+			if msg != "" {
+				return req, msg
+			}
+			req.BinaryPath = &resolved
+		}
+		if req.DependsOn != nil {
+			var deps []string
+			for _, ref := range *req.DependsOn {
+				dep, ok := projects.Get(ref)
+				if !ok {
+					if dep, ok = projects.GetByID(ref); !ok {
+						return req, "depends_on: unknown project: " + ref
+					}
+				}
+				if dep.Path == p.Path {
+					return req, "depends_on: a project cannot depend on itself"
+				}
+				if !slices.Contains(deps, dep.Path) {
+					deps = append(deps, dep.Path)
+				}
+			}
+			req.DependsOn = &deps
+		}
+		if req.Name != nil {
+			name := strings.TrimSpace(*req.Name)
+			req.Name = &name
+		}
+		if req.Tags != nil {
+			tags := store.NormalizeTags(*req.Tags)
+			req.Tags = &tags
+		}
+		if req.PHP != nil {
+			php := strings.TrimSpace(*req.PHP)
+			if php != "" {
+				if _, err := manifest.ParseConstraint(php); err != nil {
+					return req, err.Error()
+				}
+			}
+			req.PHP = &php
+		}
+		if req.PHPIniPreset != nil || req.PHPIni != nil {
+			preset, values := p.PHPIniPreset, p.PHPIni
+			if req.PHPIniPreset != nil {
+				preset = strings.TrimSpace(*req.PHPIniPreset)
+				req.PHPIniPreset = &preset
+			}
+			if req.PHPIni != nil {
+				values = *req.PHPIni
+			}
+			if err := phpini.Validate(preset, values); err != nil {
+				return req, err.Error()
+			}
+		}
+		return req, ""
+	}
+
+	// applyRequest copies the fields a checked req sets onto p, leaving the
+	// others as they are.
+	applyRequest := func(req fragoclient.ProjectRequest, p *store.Project) {
+		if req.PreferredPort != nil {
+			p.PreferredPort = *req.PreferredPort
+		}
+		if req.BinaryPath != nil {
+			p.LastBinaryPath = *req.BinaryPath
+			p.LastVersionLabel = ""
+		}
+		if req.Pinned != nil {
+			p.Pinned = *req.Pinned
+		}
+		if req.AutoStart != nil {
+			p.AutoStart = *req.AutoStart
+		}
+		if req.DependsOn != nil {
+			p.DependsOn = *req.DependsOn
+		}
+		if req.Name != nil {
+			p.Name = *req.Name
+		}
+		if req.Tags != nil {
+			p.Tags = *req.Tags
+		}
+		if req.PHP != nil {
+			p.PHP = *req.PHP
+		}
+		if req.PHPIniPreset != nil {
+			p.PHPIniPreset = *req.PHPIniPreset
+		}
+		if req.PHPIni != nil {
+			p.PHPIni = *req.PHPIni
+		}
+	}
+
+	api.GET("/projects", func(ctx *bebo.Context) error {
+		list := projects.Sorted()
+		out := fragoclient.ProjectList{Projects: make([]fragoclient.Project, 0, len(list))}
+		for _, p := range list {
+			out.Projects = append(out.Projects, view(p))
+		}
+		out.Count = len(out.Projects)
+		return ctx.JSON(http.StatusOK, out)
+	})
+
+	api.POST("/projects", func(ctx *bebo.Context) error {
+		var req fragoclient.ProjectRequest
+		if err := ctx.BindJSON(&req); err != nil {
+			return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+		}
+		if req.Path == "" {
+			return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "path is required"})
+		}
+		if !filepath.IsAbs(req.Path) {
+			return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "path must be absolute"})
+		}
+		path := filepath.Clean(req.Path)
+
+		if st, err := os.Stat(path); os.IsNotExist(err) {
+			return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "Directory does not exist"})
+		} else if err != nil {
+			return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to access directory"})
+		} else if !st.IsDir() {
+			return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "path is not a directory"})
+		}
+
+		project := store.Project{Path: path}
+		req, msg := checkRequest(req, project)
+		if msg != "" {
+			return ctx.JSON(http.StatusBadRequest, map[string]string{"error": msg})
+		}
+		applyRequest(req, &project)
+
+		created, err := projects.Create(project)
+		if errors.Is(err, store.ErrExists) {
+			return ctx.JSON(http.StatusConflict, map[string]string{"error": "project already exists"})
+		} else if err != nil {
+			return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		}
+		return ctx.JSON(http.StatusCreated, view(created))
+	})
+
+	api.GET("/projects/:id", func(ctx *bebo.Context) error {
+		project, ok := projects.GetByID(ctx.Param("id"))
+		if !ok {
+			return ctx.JSON(http.StatusNotFound, map[string]string{"error": "project not found"})
+		}
+		return ctx.JSON(http.StatusOK, view(project))
+	})
+
+	api.GET("/projects/:id/php-ini", func(ctx *bebo.Context) error {
+		project, ok := projects.GetByID(ctx.Param("id"))
+		if !ok {
+			return ctx.JSON(http.StatusNotFound, map[string]string{"error": "project not found"})
+		}
+		settings, err := svc.PHPIni(project.Path)
+		if err != nil {
+			return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		}
+		out := fragoclient.PHPIniResponse{ID: project.ID, Settings: make([]fragoclient.PHPIniSetting, 0, len(settings))}
+		for _, s := range settings {
+			out.Settings = append(out.Settings, fragoclient.PHPIniSetting{Key: s.Key, Value: s.Value, Source: s.Source})
+		}
+		return ctx.JSON(http.StatusOK, out)
+	})
+
+	debugView := func(p store.Project, restarted bool) fragoclient.DebugResponse {
+		return fragoclient.DebugResponse{
+			ID:         p.ID,
+			Enabled:    p.Xdebug.Enabled,
+			ClientHost: p.Xdebug.ClientHost,
+			ClientPort: p.Xdebug.ClientPort,
+			Trigger:    p.Xdebug.Trigger,
+			Summary:    service.XdebugSummary(p.Xdebug),
+			Restarted:  restarted,
+		}
+	}
+
+	api.GET("/projects/:id/debug", func(ctx *bebo.Context) error {
+		project, ok := projects.GetByID(ctx.Param("id"))
+		if !ok {
+			return ctx.JSON(http.StatusNotFound, map[string]string{"error": "project not found"})
+		}
+		return ctx.JSON(http.StatusOK, debugView(project, false))
+	})
+
+	api.POST("/projects/:id/debug", func(ctx *bebo.Context) error {
+		project, ok := projects.GetByID(ctx.Param("id"))
+		if !ok {
+			return ctx.JSON(http.StatusNotFound, map[string]string{"error": "project not found"})
+		}
+		var req fragoclient.DebugRequest
+		if err := ctx.BindJSON(&req); err != nil {
+			return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+		}
+		x := project.Xdebug
+		if req.Enabled != nil {
+			x.Enabled = *req.Enabled
+		}
+		if req.ClientHost != nil {
+			x.ClientHost = strings.TrimSpace(*req.ClientHost)
+		}
+		if req.ClientPort != nil {
+			x.ClientPort = *req.ClientPort
+		}
+		if req.Trigger != nil {
+			x.Trigger = *req.Trigger
+		}
+		if err := service.ValidateXdebug(x); err != nil {
+			return ctx.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+		restarted, err := svc.SetXdebug(project.Path, x)
+		if errors.Is(err, store.ErrNotFound) {
+			return ctx.JSON(http.StatusNotFound, map[string]string{"error": "project not found"})
+		} else if errors.Is(err, service.ErrNoXdebug) {
+			return ctx.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+		} else if err != nil {
+			return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		}
+		project, _ = projects.Get(project.Path)
+		return ctx.JSON(http.StatusOK, debugView(project, restarted))
+	})
+
+	api.PATCH("/projects/:id", func(ctx *bebo.Context) error {
+		project, ok := projects.GetByID(ctx.Param("id"))
+		if !ok {
+			return ctx.JSON(http.StatusNotFound, map[string]string{"error": "project not found"})
+		}
+
+		var req fragoclient.ProjectRequest
+		if err := ctx.BindJSON(&req); err != nil {
+			return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+		}
+
+		// A bad field leaves the project untouched. Only the fields in the
+		// request are written, so concurrent changes to others are kept.
+		req, msg := checkRequest(req, project)
+		if msg != "" {
+			return ctx.JSON(http.StatusBadRequest, map[string]string{"error": msg})
+		}
+		updated, err := projects.Update(project.Path, func(p *store.Project) {
+			applyRequest(req, p)
+		})
+		if errors.Is(err, store.ErrNotFound) {
+			return ctx.JSON(http.StatusNotFound, map[string]string{"error": "project not found"})
+		} else if err != nil {
+			return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		}
+		return ctx.JSON(http.StatusOK, view(updated))
+	})
+
+	api.DELETE("/projects/:id", func(ctx *bebo.Context) error {
+		project, ok := projects.GetByID(ctx.Param("id"))
+		if !ok {
+			return ctx.JSON(http.StatusNotFound, map[string]string{"error": "project not found"})
+		}
+		if err := svc.Delete(project.Path); errors.Is(err, service.ErrRunning) {
+			return ctx.JSON(http.StatusConflict, map[string]string{"error": "project is running; stop it first"})
+		} else if err != nil {
+			return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		}
+
+		return ctx.JSON(http.StatusOK, fragoclient.DeleteResponse{Status: "deleted", ID: project.ID})
+	})
+}
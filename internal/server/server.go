@@ -1,67 +1,64 @@
 package server
 
 import (
+	"context"
+	"crypto/subtle"
+	"errors"
 	"fmt"
+	"net"
 	"net/http"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/devmarvs/bebo"
 	"github.com/devmarvs/bebo/middleware"
-	"github.com/devmarvs/frago/internal/caddy"
 	"github.com/devmarvs/frago/internal/runner"
+	"github.com/devmarvs/frago/internal/service"
+	"github.com/devmarvs/frago/internal/store"
+	"github.com/devmarvs/frago/pkg/fragoclient"
 )
 
-type RunRequest struct {
-	ProjectPath string `json:"project_path"`
-	BinaryPath  string `json:"binary_path,omitempty"`
-	Port        int    `json:"port,omitempty"`
+// Config controls how the API is exposed.
+type Config struct {
+	// Port is the TCP port bound on 127.0.0.1.
+	Port int
+	// Token, when set, must accompany every /api request made over TCP,
+	// either as "Authorization: Bearer <token>" or in the X-Frago-Token header.
+	// Requests over the local socket are not checked.
+	Token string
 }
 
-type RunResponse struct {
-	Status string `json:"status"`
-	URL    string `json:"url,omitempty"`
-	Port   int    `json:"port,omitempty"`
-}
+type localRequestKey struct{}
+
+const defaultLogLines = 200
 
-func New(mgr *runner.Manager, port int) *bebo.App {
+func New(svc *service.Service, srvCfg Config) *bebo.App {
+	mgr := svc.Manager()
 	cfg := bebo.DefaultConfig()
-	cfg.Address = fmt.Sprintf("127.0.0.1:%d", port)
+	cfg.Address = fmt.Sprintf("127.0.0.1:%d", srvCfg.Port)
 	app := bebo.New(bebo.WithConfig(cfg))
 
-	allowedBinaries := buildAllowedBinaries()
-
 	// Middleware
 	app.Use(middleware.RequestID(), middleware.Recover(), middleware.Logger())
+	api := app.Group("/api", requireToken(srvCfg.Token))
 
 	// Health check
 	app.GET("/health", func(ctx *bebo.Context) error {
-		return ctx.JSON(http.StatusOK, map[string]string{"status": "ok"})
+		return ctx.JSON(http.StatusOK, fragoclient.HealthResponse{Status: "ok"})
 	})
 
 	// Status endpoint
-	app.GET("/api/status", func(ctx *bebo.Context) error {
-		processes := mgr.List()
-		var active []map[string]interface{}
-
-		for _, p := range processes {
-			active = append(active, map[string]interface{}{
-				"project_path": p.ProjectPath,
-				"url":          p.URL,
-				"port":         p.Port,
-			})
-		}
-
-		return ctx.JSON(http.StatusOK, map[string]interface{}{
-			"processes": active,
-			"count":     len(active),
-		})
+	api.GET("/status", func(ctx *bebo.Context) error {
+		return ctx.JSON(http.StatusOK, buildStatus(svc))
 	})
 
 	// Run endpoint
-	app.POST("/api/run", func(ctx *bebo.Context) error {
-		var req RunRequest
+	api.POST("/run", func(ctx *bebo.Context) error {
+		var req fragoclient.RunRequest
 		if err := ctx.BindJSON(&req); err != nil {
 			return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
 		}
@@ -81,40 +78,24 @@ func New(mgr *runner.Manager, port int) *bebo.App {
 		}
 
 		if req.BinaryPath != "" {
-			resolved, err := resolveBinaryPath(req.BinaryPath)
-			if err != nil {
-				return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "binary_path not found or not executable"})
-			}
-			if _, ok := allowedBinaries[resolved]; !ok {
-				return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "binary_path is not a known FrankenPHP binary"})
-			}
-			if _, err := runner.GetFrankenPHPVersion(resolved); err != nil {
-				return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "binary_path does not appear to be FrankenPHP"})
+			resolved, msg := validateBinaryPath(req.BinaryPath, svc)
+			if msg != "" {
+				return ctx.JSON(http.StatusBadRequest, map[string]string{"error": msg})
 			}
 			req.BinaryPath = resolved
 		}
 
-		// Ensure Caddyfile, avoiding ports already used by managed processes
-		config, err := caddy.EnsureCaddyfileAutoPort(req.ProjectPath, mgr.UsedPorts(), req.Port)
-		if err != nil {
-			return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": fmt.Sprintf("Caddyfile error: %v", err)})
-		}
-
-		// Start Process
-		if err := mgr.Start(req.ProjectPath, config, req.BinaryPath, ""); err != nil {
+		// Start Process, avoiding ports already used by managed processes
+		if err := svc.Start(req.ProjectPath, req.BinaryPath, "", req.Port); err != nil {
 			return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": fmt.Sprintf("Failed to start: %v", err)})
 		}
 
-		return ctx.JSON(http.StatusOK, RunResponse{
-			Status: "running",
-			URL:    fmt.Sprintf("http://localhost:%d", config.Port),
-			Port:   config.Port,
-		})
+		return runningResponse(ctx, mgr, req.ProjectPath)
 	})
 
 	// Stop endpoint
-	app.POST("/api/stop", func(ctx *bebo.Context) error {
-		var req RunRequest
+	api.POST("/stop", func(ctx *bebo.Context) error {
+		var req fragoclient.RunRequest
 		if err := ctx.BindJSON(&req); err != nil {
 			return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
 		}
@@ -123,36 +104,217 @@ func New(mgr *runner.Manager, port int) *bebo.App {
 			return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "project_path is required"})
 		}
 
-		if err := mgr.Stop(req.ProjectPath); err != nil {
+		if err := svc.Stop(req.ProjectPath); err != nil {
 			return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 		}
-		return ctx.JSON(http.StatusOK, map[string]string{"status": "stopped", "project_path": req.ProjectPath})
+		return ctx.JSON(http.StatusOK, fragoclient.StopResponse{Status: "stopped", ProjectPath: req.ProjectPath})
+	})
+
+	// Restart endpoint
+	api.POST("/restart", func(ctx *bebo.Context) error {
+		var req fragoclient.RunRequest
+		if err := ctx.BindJSON(&req); err != nil {
+			return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+		}
+
+		if req.ProjectPath == "" {
+			return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "project_path is required"})
+		}
+
+		if err := svc.Restart(req.ProjectPath); errors.Is(err, store.ErrNotFound) {
+			return ctx.JSON(http.StatusNotFound, map[string]string{"error": "project not found"})
+		} else if err != nil {
+			return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": fmt.Sprintf("Failed to restart: %v", err)})
+		}
+		return runningResponse(ctx, mgr, req.ProjectPath)
+	})
+
+	// Logs endpoint. Without since it returns the last lines; with since it
+	// returns only lines written after that cursor, so clients can follow.
+	// With sidecar it returns the output of that sidecar of the project.
+	api.GET("/logs", func(ctx *bebo.Context) error {
+		query := ctx.Request.URL.Query()
+		path := query.Get("project_path")
+		if path == "" {
+			return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "project_path is required"})
+		}
+		since := int64(-1)
+		if raw := query.Get("since"); raw != "" {
+			v, err := strconv.ParseInt(raw, 10, 64)
+			if err != nil || v < 0 {
+				return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "since must be a non-negative integer"})
+			}
+			since = v
+		}
+		lines := defaultLogLines
+		if raw := query.Get("lines"); raw != "" {
+			v, err := strconv.Atoi(raw)
+			if err != nil || v < 1 {
+				return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "lines must be a positive integer"})
+			}
+			lines = v
+		}
+
+		sidecar := query.Get("sidecar")
+		out, next := mgr.LogsSince(path, since, lines)
+		_, running := mgr.Get(path)
+		if sidecar != "" {
+			out, next = mgr.SidecarLogsSince(path, sidecar, since, lines)
+			_, running = mgr.GetSidecar(path, sidecar)
+		}
+		if out == nil {
+			out = []string{}
+		}
+		return ctx.JSON(http.StatusOK, fragoclient.LogsResponse{
+			ProjectPath: path,
+			Lines:       out,
+			Next:        next,
+			Running:     running,
+			Sidecar:     sidecar,
+		})
 	})
 
+	// Bulk endpoints
+	api.POST("/start-all", func(ctx *bebo.Context) error {
+		return ctx.JSON(http.StatusOK, newBulkResponse(svc.StartAll()))
+	})
+
+	api.POST("/stop-all", func(ctx *bebo.Context) error {
+		return ctx.JSON(http.StatusOK, newBulkResponse(svc.StopAll()))
+	})
+
+	registerProjectRoutes(api, svc)
+	registerSidecarRoutes(api, svc)
+	registerWorkspaceRoutes(api, svc)
+
+	// The spec is public so clients can discover the API before they have a token.
+	app.GET("/api/openapi.json", openAPIHandler())
+
 	return app
 }
 
-func buildAllowedBinaries() map[string]struct{} {
-	allowed := make(map[string]struct{})
+func runningResponse(ctx *bebo.Context, mgr *runner.Manager, path string) error {
+	resp := fragoclient.RunResponse{Status: "running"}
+	if proc, ok := mgr.Get(path); ok {
+		resp.URL = proc.URL
+		resp.Port = proc.Port
+	}
+	return ctx.JSON(http.StatusOK, resp)
+}
 
-	versions, err := runner.DetectVersions()
-	if err == nil {
-		for _, v := range versions {
-			if resolved, err := resolveBinaryPath(v.Path); err == nil {
-				allowed[resolved] = struct{}{}
+func newBulkResponse(results []service.Result) fragoclient.BulkResponse {
+	resp := fragoclient.BulkResponse{Results: make([]fragoclient.Result, 0, len(results))}
+	for _, r := range results {
+		resp.Results = append(resp.Results, fragoclient.Result{
+			ID:     r.ID,
+			Path:   r.Path,
+			OK:     r.OK,
+			Status: r.Status,
+			Error:  r.Error,
+		})
+		if r.OK {
+			resp.OK++
+		} else {
+			resp.Failed++
+		}
+	}
+	return resp
+}
+
+// ServeLocal serves app on ln until ctx is canceled. Requests accepted here
+// skip the token check because access to the socket is already limited by
+// filesystem permissions.
+func ServeLocal(ctx context.Context, app *bebo.App, ln net.Listener) error {
+	srv := &http.Server{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			app.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), localRequestKey{}, true)))
+		}),
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- srv.Serve(ln)
+	}()
+
+	select {
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), app.ShutdownTimeout())
+		defer cancel()
+		_ = srv.Shutdown(shutdownCtx)
+		<-errCh
+		return nil
+	case err := <-errCh:
+		if err != nil && !errors.Is(err, http.ErrServerClosed) && !errors.Is(err, net.ErrClosed) {
+			return err
+		}
+		return nil
+	}
+}
+
+func requireToken(token string) bebo.Middleware {
+	return func(next bebo.Handler) bebo.Handler {
+		return func(ctx *bebo.Context) error {
+			if token == "" || ctx.Request.Context().Value(localRequestKey{}) != nil {
+				return next(ctx)
+			}
+
+			got := ctx.Request.Header.Get("X-Frago-Token")
+			if auth := ctx.Request.Header.Get("Authorization"); strings.HasPrefix(auth, "Bearer ") {
+				got = strings.TrimPrefix(auth, "Bearer ")
+			}
+			if subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+				return ctx.JSON(http.StatusUnauthorized, map[string]string{"error": "missing or invalid API token"})
 			}
+			return next(ctx)
 		}
 	}
+}
 
-	if def := runner.DefaultFrankenPHPBinary(); def != "" {
-		if resolved, err := resolveBinaryPath(def); err == nil {
-			allowed[resolved] = struct{}{}
+// allowedBinaries returns the resolved paths of the binaries svc currently
+// knows about, the default binary, the builds downloaded by Frago and the
+// binaries the user added outside PATH. It is built on every call, so
+// binaries found, installed or added since the last scan are accepted too.
+func allowedBinaries(svc *service.Service) map[string]struct{} {
+	candidates := []string{runner.DefaultFrankenPHPBinary()}
+	for _, v := range svc.Versions() {
+		candidates = append(candidates, v.Path)
+	}
+	if root, err := runner.ManagedDir(); err == nil {
+		for _, build := range runner.ManagedBuilds(root) {
+			candidates = append(candidates, build.Path)
 		}
 	}
+	candidates = append(candidates, runner.DefaultDetector().CustomBinaries()...)
 
+	allowed := make(map[string]struct{})
+	for _, path := range candidates {
+		if path == "" {
+			continue
+		}
+		if resolved, err := resolveBinaryPath(path); err == nil {
+			allowed[resolved] = struct{}{}
+		}
+	}
 	return allowed
 }
 
+// validateBinaryPath resolves path and checks that it is a detected FrankenPHP
+// binary. On failure it returns a client-facing error message.
+func validateBinaryPath(path string, svc *service.Service) (string, string) {
+	resolved, err := resolveBinaryPath(path)
+	if err != nil {
+		return "", "binary_path not found or not executable"
+	}
+	if _, ok := allowedBinaries(svc)[resolved]; !ok {
+		return "", "binary_path is not a known FrankenPHP binary"
+	}
+	if _, err := runner.GetFrankenPHPVersion(resolved); err != nil {
+		return "", "binary_path does not appear to be FrankenPHP"
+	}
+	return resolved, ""
+}
+
 func resolveBinaryPath(path string) (string, error) {
 	resolved := path
 	if !filepath.IsAbs(path) {
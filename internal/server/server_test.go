@@ -0,0 +1,417 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/devmarvs/frago/internal/ipc"
+	"github.com/devmarvs/frago/internal/runner"
+	"github.com/devmarvs/frago/internal/service"
+	"github.com/devmarvs/frago/internal/store"
+	"github.com/devmarvs/frago/pkg/fragoclient"
+)
+
+type memoryBackend struct {
+	data []byte
+}
+
+func (b *memoryBackend) Load() ([]byte, error) { return b.data, nil }
+
+func (b *memoryBackend) Save(data []byte) error {
+	b.data = append([]byte(nil), data...)
+	return nil
+}
+
+func TestAPIRequiresTokenOverTCP(t *testing.T) {
+	app := New(service.New(runner.NewManager(), store.New(&memoryBackend{}), nil), Config{Token: "secret"})
+
+	cases := []struct {
+		name   string
+		header string
+		value  string
+		want   int
+	}{
+		{"missing", "", "", http.StatusUnauthorized},
+		{"wrong", "Authorization", "Bearer nope", http.StatusUnauthorized},
+		{"bearer", "Authorization", "Bearer secret", http.StatusOK},
+		{"header", "X-Frago-Token", "secret", http.StatusOK},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/status", nil)
+			if tc.header != "" {
+				req.Header.Set(tc.header, tc.value)
+			}
+			rec := httptest.NewRecorder()
+			app.ServeHTTP(rec, req)
+			if rec.Code != tc.want {
+				t.Fatalf("expected status %d, got %d", tc.want, rec.Code)
+			}
+		})
+	}
+}
+
+func TestServeLocalSkipsToken(t *testing.T) {
+	t.Setenv("XDG_RUNTIME_DIR", t.TempDir())
+
+	ln, err := ipc.Listen()
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- ServeLocal(ctx, New(service.New(runner.NewManager(), store.New(&memoryBackend{}), nil), Config{Token: "secret"}), ln)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		if err := <-done; err != nil {
+			t.Errorf("ServeLocal: %v", err)
+		}
+	})
+
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return ipc.Dial(ctx)
+		},
+	}}
+	resp, err := client.Get("http://frago/api/status")
+	if err != nil {
+		t.Fatalf("request over socket: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status 200 over socket, got %d", resp.StatusCode)
+	}
+}
+
+func TestProjectCRUD(t *testing.T) {
+	projects := store.New(&memoryBackend{})
+	app := New(service.New(runner.NewManager(), projects, nil), Config{})
+	dir := t.TempDir()
+
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		app.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := do(http.MethodPost, "/api/projects", fmt.Sprintf(`{"path":%q,"preferred_port":8123,"auto_start":true}`, dir))
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create: expected 201, got %d: %s", rec.Code, rec.Body)
+	}
+	var created fragoclient.Project
+	if err := json.Unmarshal(rec.Body.Bytes(), &created); err != nil {
+		t.Fatalf("decode create: %v", err)
+	}
+	if created.ID == "" || created.PreferredPort != 8123 || !created.AutoStart {
+		t.Fatalf("unexpected created project: %+v", created)
+	}
+
+	if rec := do(http.MethodPost, "/api/projects", fmt.Sprintf(`{"path":%q}`, dir)); rec.Code != http.StatusConflict {
+		t.Fatalf("duplicate create: expected 409, got %d", rec.Code)
+	}
+
+	rec = do(http.MethodPatch, "/api/projects/"+created.ID, `{"pinned":true,"preferred_port":0,"name":" Shop ","tags":["laravel, client-a","Laravel"],"php":"^8.3","php_ini_preset":"development","php_ini":{"memory_limit":"1G"}}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("update: expected 200, got %d: %s", rec.Code, rec.Body)
+	}
+	got, ok := projects.Get(dir)
+	if !ok || !got.Pinned || got.PreferredPort != 0 || !got.AutoStart || got.Name != "Shop" || strings.Join(got.Tags, ",") != "laravel,client-a" || got.PHP != "^8.3" || got.PHPIniPreset != "development" || got.PHPIni["memory_limit"] != "1G" {
+		t.Fatalf("unexpected stored project after update: %+v", got)
+	}
+
+	if rec := do(http.MethodPatch, "/api/projects/"+created.ID, `{"preferred_port":70000}`); rec.Code != http.StatusBadRequest {
+		t.Fatalf("invalid update: expected 400, got %d", rec.Code)
+	}
+	if rec := do(http.MethodPatch, "/api/projects/"+created.ID, `{"php":"^eight"}`); rec.Code != http.StatusBadRequest {
+		t.Fatalf("invalid php constraint: expected 400, got %d", rec.Code)
+	}
+	if rec := do(http.MethodPatch, "/api/projects/"+created.ID, `{"php_ini_preset":"staging"}`); rec.Code != http.StatusBadRequest {
+		t.Fatalf("invalid php.ini preset: expected 400, got %d", rec.Code)
+	}
+	rec = do(http.MethodPost, "/api/projects/"+created.ID+"/debug", `{"enabled":true,"trigger":true,"client_port":9010}`)
+	var debug fragoclient.DebugResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &debug); rec.Code != http.StatusOK || err != nil {
+		t.Fatalf("debug: expected 200, got %d: %s", rec.Code, rec.Body)
+	}
+	if !debug.Enabled || !debug.Trigger || debug.Restarted || debug.Summary != "On (trigger, localhost:9010)" {
+		t.Fatalf("unexpected debug response %+v", debug)
+	}
+	if rec := do(http.MethodPost, "/api/projects/"+created.ID+"/debug", `{"client_port":-1}`); rec.Code != http.StatusBadRequest {
+		t.Fatalf("invalid debug port: expected 400, got %d", rec.Code)
+	}
+	if rec := do(http.MethodGet, "/api/projects/"+created.ID+"/debug", ""); !strings.Contains(rec.Body.String(), `"client_port":9010`) {
+		t.Fatalf("debug settings not saved: %s", rec.Body)
+	}
+
+	rec = do(http.MethodGet, "/api/projects/"+created.ID+"/php-ini", "")
+	var ini fragoclient.PHPIniResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &ini); rec.Code != http.StatusOK || err != nil {
+		t.Fatalf("php-ini: expected 200, got %d: %s", rec.Code, rec.Body)
+	}
+	if !slices.Contains(ini.Settings, fragoclient.PHPIniSetting{Key: "memory_limit", Value: "1G", Source: "project settings"}) {
+		t.Fatalf("unexpected php.ini settings %+v", ini.Settings)
+	}
+
+	if rec := do(http.MethodGet, "/api/projects", ""); !strings.Contains(rec.Body.String(), created.ID) {
+		t.Fatalf("list does not include project: %s", rec.Body)
+	}
+
+	if rec := do(http.MethodDelete, "/api/projects/"+created.ID, ""); rec.Code != http.StatusOK {
+		t.Fatalf("delete: expected 200, got %d: %s", rec.Code, rec.Body)
+	}
+	if rec := do(http.MethodGet, "/api/projects/"+created.ID, ""); rec.Code != http.StatusNotFound {
+		t.Fatalf("get after delete: expected 404, got %d", rec.Code)
+	}
+}
+
+func TestPatchKeepsConcurrentChanges(t *testing.T) {
+	backend := &memoryBackend{}
+	projects := store.New(backend)
+	app := New(service.New(runner.NewManager(), projects, nil), Config{})
+	dir := t.TempDir()
+	created, err := projects.Create(store.Project{Path: dir})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	// Another process records a start after this one last read the state.
+	other := store.New(backend)
+	if err := other.Load(); err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if _, err := other.Update(dir, func(p *store.Project) { p.LastPort = 8123 }); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodPatch, "/api/projects/"+created.ID, strings.NewReader(`{"name":"Shop"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	app.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("patch: expected 200, got %d: %s", rec.Code, rec.Body)
+	}
+	if got, _ := projects.Get(dir); got.Name != "Shop" || got.LastPort != 8123 {
+		t.Fatalf("expected the name and the concurrent change, got %+v", got)
+	}
+}
+
+func TestWorkspaceRoutes(t *testing.T) {
+	projects := store.New(&memoryBackend{})
+	app := New(service.New(runner.NewManager(), projects, nil), Config{})
+	for _, path := range []string{"/srv/api", "/srv/shop"} {
+		if _, err := projects.Create(store.Project{Path: path}); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+	}
+
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		app.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := do(http.MethodPost, "/api/workspaces", fmt.Sprintf(`{"name":"Shop","projects":["/srv/api",%q]}`, store.ProjectID("/srv/shop")))
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create: expected 201, got %d: %s", rec.Code, rec.Body)
+	}
+	var created fragoclient.Workspace
+	if err := json.Unmarshal(rec.Body.Bytes(), &created); err != nil {
+		t.Fatalf("decode create: %v", err)
+	}
+	if created.ID == "" || strings.Join(created.Projects, ",") != "/srv/api,/srv/shop" {
+		t.Fatalf("unexpected workspace: %+v", created)
+	}
+
+	if rec := do(http.MethodPost, "/api/workspaces", `{"name":"shop"}`); rec.Code != http.StatusConflict {
+		t.Fatalf("duplicate name: expected 409, got %d", rec.Code)
+	}
+	if rec := do(http.MethodPost, "/api/workspaces", `{"name":"Other","projects":["/nowhere"]}`); rec.Code != http.StatusBadRequest {
+		t.Fatalf("unknown project: expected 400, got %d", rec.Code)
+	}
+
+	rec = do(http.MethodPatch, "/api/workspaces/"+created.ID, `{"projects":["/srv/shop","/srv/api"]}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("update: expected 200, got %d: %s", rec.Code, rec.Body)
+	}
+	if w, _ := projects.GetWorkspace(created.ID); w.Name != "Shop" || w.Projects[0] != "/srv/shop" {
+		t.Fatalf("unexpected workspace after update: %+v", w)
+	}
+
+	// Nothing is running, so stop reports every project as skipped, last first.
+	rec = do(http.MethodPost, "/api/workspaces/"+created.ID+"/stop", "")
+	var stopped fragoclient.BulkResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &stopped); err != nil {
+		t.Fatalf("decode stop: %v", err)
+	}
+	if len(stopped.Results) != 2 || stopped.Results[0].Path != "/srv/api" || stopped.Results[0].Status != fragoclient.ResultSkipped {
+		t.Fatalf("unexpected stop results: %+v", stopped)
+	}
+
+	if rec := do(http.MethodDelete, "/api/workspaces/"+created.ID, ""); rec.Code != http.StatusOK {
+		t.Fatalf("delete: expected 200, got %d", rec.Code)
+	}
+	if rec := do(http.MethodPost, "/api/workspaces/"+created.ID+"/start", ""); rec.Code != http.StatusNotFound {
+		t.Fatalf("start after delete: expected 404, got %d", rec.Code)
+	}
+}
+
+func TestStatusIncludesStoppedProjects(t *testing.T) {
+	projects := store.New(&memoryBackend{})
+	if _, err := projects.Create(store.Project{Path: "/srv/api", LastPort: 8088}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	app := New(service.New(runner.NewManager(), projects, nil), Config{})
+
+	rec := httptest.NewRecorder()
+	app.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/status", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+
+	var status fragoclient.StatusResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &status); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if status.SchemaVersion != fragoclient.StatusSchemaVersion || status.Count != 0 || len(status.Processes) != 0 {
+		t.Fatalf("unexpected running section: %+v", status)
+	}
+	if len(status.Stopped) != 1 {
+		t.Fatalf("expected 1 stopped project, got %d", len(status.Stopped))
+	}
+	got := status.Stopped[0]
+	if got.ProjectPath != "/srv/api" || got.State != fragoclient.StateStopped || got.Port != 8088 || got.LastExit != nil {
+		t.Fatalf("unexpected stopped project: %+v", got)
+	}
+}
+
+func TestSidecarRoutes(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sleep as the sidecar")
+	}
+	dir := t.TempDir()
+	manifest := "[sidecars.queue]\ncommand = [\"sh\", \"-c\", \"echo working; exec sleep 60\"]\nauto_start = false\n"
+	if err := os.WriteFile(filepath.Join(dir, "frago.toml"), []byte(manifest), 0644); err != nil {
+		t.Fatalf("write manifest: %v", err)
+	}
+	projects := store.New(&memoryBackend{})
+	created, err := projects.Create(store.Project{Path: dir})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	svc := service.New(runner.NewManager(), projects, nil)
+	t.Cleanup(func() { svc.StopSidecars(dir) })
+	app := New(svc, Config{})
+
+	do := func(method, path string) *httptest.ResponseRecorder {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		app.ServeHTTP(rec, httptest.NewRequest(method, path, nil))
+		return rec
+	}
+
+	rec := do(http.MethodPost, "/api/projects/"+created.ID+"/sidecars/queue/start")
+	var started fragoclient.SidecarStatus
+	if err := json.Unmarshal(rec.Body.Bytes(), &started); rec.Code != http.StatusOK || err != nil {
+		t.Fatalf("start: expected 200, got %d: %s", rec.Code, rec.Body)
+	}
+	if started.State != fragoclient.StateRunning || started.PID == 0 || started.AutoStart {
+		t.Fatalf("unexpected sidecar %+v", started)
+	}
+	if rec := do(http.MethodPost, "/api/projects/"+created.ID+"/sidecars/missing/start"); rec.Code != http.StatusNotFound {
+		t.Fatalf("unknown sidecar: expected 404, got %d", rec.Code)
+	}
+
+	var list fragoclient.SidecarsResponse
+	rec = do(http.MethodGet, "/api/projects/"+created.ID+"/sidecars")
+	if err := json.Unmarshal(rec.Body.Bytes(), &list); err != nil || len(list.Sidecars) != 1 || list.Sidecars[0].Name != "queue" {
+		t.Fatalf("unexpected sidecar list: %s", rec.Body)
+	}
+	if rec := do(http.MethodGet, "/api/status"); !strings.Contains(rec.Body.String(), `"sidecars":[{"name":"queue"`) {
+		t.Fatalf("status does not list sidecars: %s", rec.Body)
+	}
+
+	deadline := time.Now().Add(3 * time.Second)
+	for {
+		rec := do(http.MethodGet, "/api/logs?sidecar=queue&project_path="+url.QueryEscape(dir))
+		if strings.Contains(rec.Body.String(), `"lines":["working"]`) {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("unexpected sidecar logs: %s", rec.Body)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	if rec := do(http.MethodPost, "/api/projects/"+created.ID+"/sidecars/queue/stop"); rec.Code != http.StatusOK {
+		t.Fatalf("stop: expected 200, got %d: %s", rec.Code, rec.Body)
+	}
+}
+
+func TestOpenAPICoversRoutes(t *testing.T) {
+	app := New(service.New(runner.NewManager(), store.New(&memoryBackend{}), nil), Config{})
+
+	routes := make(map[string]bool)
+	for _, r := range app.RoutesAll() {
+		routes[r.Method+" "+r.Pattern] = true
+	}
+	documented := make(map[string]bool)
+	for _, op := range apiOperations {
+		documented[op.method+" "+op.path] = true
+	}
+
+	for route := range routes {
+		if !documented[route] {
+			t.Errorf("route %s is missing from the OpenAPI document", route)
+		}
+	}
+	for route := range documented {
+		if !routes[route] {
+			t.Errorf("OpenAPI documents %s, which is not registered", route)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	app.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/openapi.json", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	var doc struct {
+		Paths      map[string]map[string]json.RawMessage `json:"paths"`
+		Components struct {
+			Schemas map[string]json.RawMessage `json:"schemas"`
+		} `json:"components"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &doc); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if _, ok := doc.Paths["/api/projects/{id}"]["patch"]; !ok {
+		t.Fatalf("expected PATCH /api/projects/{id} in paths, got %v", doc.Paths)
+	}
+	for _, name := range []string{"StatusResponse", "ProjectStatus", "Health", "Project", "ErrorResponse"} {
+		if _, ok := doc.Components.Schemas[name]; !ok {
+			t.Errorf("schema %s missing", name)
+		}
+	}
+}
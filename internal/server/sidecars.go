@@ -0,0 +1,97 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/devmarvs/bebo"
+	"github.com/devmarvs/frago/internal/service"
+	"github.com/devmarvs/frago/internal/store"
+	"github.com/devmarvs/frago/pkg/fragoclient"
+)
+
+func registerSidecarRoutes(api *bebo.Group, svc *service.Service) {
+	projects := svc.Projects()
+
+	api.GET("/projects/:id/sidecars", func(ctx *bebo.Context) error {
+		project, ok := projects.GetByID(ctx.Param("id"))
+		if !ok {
+			return ctx.JSON(http.StatusNotFound, map[string]string{"error": "project not found"})
+		}
+		sidecars, err := svc.Sidecars(project.Path)
+		if err != nil {
+			return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		}
+		resp := fragoclient.SidecarsResponse{
+			ID:          project.ID,
+			ProjectPath: project.Path,
+			Sidecars:    make([]fragoclient.SidecarStatus, 0, len(sidecars)),
+		}
+		for _, sc := range sidecars {
+			resp.Sidecars = append(resp.Sidecars, sidecarView(sc))
+		}
+		return ctx.JSON(http.StatusOK, resp)
+	})
+
+	actions := map[string]func(path, name string) error{
+		"start":   svc.StartSidecar,
+		"stop":    svc.StopSidecar,
+		"restart": svc.RestartSidecar,
+	}
+	for action, run := range actions {
+		api.POST("/projects/:id/sidecars/:name/"+action, func(ctx *bebo.Context) error {
+			project, ok := projects.GetByID(ctx.Param("id"))
+			if !ok {
+				return ctx.JSON(http.StatusNotFound, map[string]string{"error": "project not found"})
+			}
+			name := ctx.Param("name")
+			if err := run(project.Path, name); errors.Is(err, store.ErrNotFound) {
+				return ctx.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+			} else if err != nil {
+				return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+			}
+			sidecars, err := svc.Sidecars(project.Path)
+			if err != nil {
+				return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+			}
+			for _, sc := range sidecars {
+				if sc.Name == name {
+					return ctx.JSON(http.StatusOK, sidecarView(sc))
+				}
+			}
+			return ctx.JSON(http.StatusNotFound, map[string]string{"error": "sidecar not found"})
+		})
+	}
+}
+
+func sidecarView(sc service.SidecarStatus) fragoclient.SidecarStatus {
+	status := fragoclient.SidecarStatus{
+		Name:      sc.Name,
+		Command:   sc.Command,
+		Restart:   sc.Restart,
+		AutoStart: sc.AutoStart,
+		State:     fragoclient.StateStopped,
+	}
+	if sc.Running {
+		status.State = fragoclient.StateRunning
+		status.PID = sc.PID
+		status.StartedAt = sc.StartedAt
+		status.UptimeSeconds = int64(time.Since(sc.StartedAt).Seconds())
+	}
+	if sc.Stats != nil {
+		status.Stats = &fragoclient.Stats{
+			CPUPercent: sc.Stats.CPUPercent,
+			RSSBytes:   sc.Stats.RSSBytes,
+			CheckedAt:  sc.Stats.CheckedAt,
+			LastError:  sc.Stats.LastError,
+		}
+	}
+	if exit := sc.LastExit; exit != nil {
+		status.LastExit = &fragoclient.ExitStatus{At: exit.When, Error: exit.Err, Failed: exit.Failed}
+		if exit.Failed {
+			status.State = fragoclient.StateFailed
+		}
+	}
+	return status
+}
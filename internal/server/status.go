@@ -0,0 +1,89 @@
+package server
+
+import (
+	"time"
+
+	"github.com/devmarvs/frago/internal/service"
+	"github.com/devmarvs/frago/internal/store"
+	"github.com/devmarvs/frago/pkg/fragoclient"
+)
+
+func buildStatus(svc *service.Service) fragoclient.StatusResponse {
+	mgr := svc.Manager()
+	resp := fragoclient.StatusResponse{
+		SchemaVersion: fragoclient.StatusSchemaVersion,
+		Processes:     []fragoclient.ProjectStatus{},
+		Stopped:       []fragoclient.ProjectStatus{},
+	}
+
+	running := make(map[string]bool)
+	for _, p := range mgr.List() {
+		running[p.ProjectPath] = true
+		status := fragoclient.ProjectStatus{
+			ID:            store.ProjectID(p.ProjectPath),
+			ProjectPath:   p.ProjectPath,
+			State:         fragoclient.StateRunning,
+			URL:           p.URL,
+			Port:          p.Port,
+			PID:           p.PID(),
+			BinaryPath:    p.BinaryPath,
+			VersionLabel:  p.VersionLabel,
+			StartedAt:     p.StartedAt,
+			UptimeSeconds: int64(time.Since(p.StartedAt).Seconds()),
+		}
+		if health, ok := svc.Health(p.ProjectPath); ok {
+			status.Health = &fragoclient.Health{
+				Healthy:   health.Healthy,
+				CheckedAt: health.CheckedAt,
+				LastError: health.LastError,
+			}
+		}
+		if stats, ok := svc.Stats(p.ProjectPath); ok {
+			status.Stats = &fragoclient.Stats{
+				CPUPercent: stats.CPUPercent,
+				RSSBytes:   stats.RSSBytes,
+				CheckedAt:  stats.CheckedAt,
+				LastError:  stats.LastError,
+			}
+		}
+		status.Sidecars = sidecarViews(svc, p.ProjectPath)
+		resp.Processes = append(resp.Processes, status)
+	}
+	resp.Count = len(resp.Processes)
+
+	for _, info := range svc.Projects().Sorted() {
+		if running[info.Path] {
+			continue
+		}
+		status := fragoclient.ProjectStatus{
+			ID:           info.ID,
+			ProjectPath:  info.Path,
+			State:        fragoclient.StateStopped,
+			URL:          info.LastURL,
+			Port:         info.LastPort,
+			BinaryPath:   info.LastBinaryPath,
+			VersionLabel: info.LastVersionLabel,
+		}
+		if exit, ok := mgr.LastExit(info.Path); ok {
+			status.LastExit = &fragoclient.ExitStatus{At: exit.When, Error: exit.Err, Failed: exit.Failed}
+			if exit.Failed {
+				status.State = fragoclient.StateFailed
+			}
+		}
+		status.Sidecars = sidecarViews(svc, info.Path)
+		resp.Stopped = append(resp.Stopped, status)
+	}
+
+	return resp
+}
+
+// sidecarViews returns the sidecars of the project in path, or none when its
+// manifest cannot be read.
+func sidecarViews(svc *service.Service, path string) []fragoclient.SidecarStatus {
+	sidecars, _ := svc.Sidecars(path)
+	var views []fragoclient.SidecarStatus
+	for _, sc := range sidecars {
+		views = append(views, sidecarView(sc))
+	}
+	return views
+}
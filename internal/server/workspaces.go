@@ -0,0 +1,149 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/devmarvs/bebo"
+	"github.com/devmarvs/frago/internal/service"
+	"github.com/devmarvs/frago/internal/store"
+	"github.com/devmarvs/frago/pkg/fragoclient"
+)
+
+func registerWorkspaceRoutes(api *bebo.Group, svc *service.Service) {
+	projects := svc.Projects()
+
+	view := func(w store.Workspace) fragoclient.Workspace {
+		members := w.Projects
+		if members == nil {
+			members = []string{}
+		}
+		return fragoclient.Workspace{ID: w.ID, Name: w.Name, Projects: members}
+	}
+
+	// resolveProjects maps saved project paths or IDs to paths.
+	resolveProjects := func(refs []string) ([]string, string) {
+		paths := make([]string, 0, len(refs))
+		for _, ref := range refs {
+			if p, ok := projects.Get(ref); ok {
+				paths = append(paths, p.Path)
+			} else if p, ok := projects.GetByID(ref); ok {
+				paths = append(paths, p.Path)
+			} else {
+				return nil, "unknown project: " + ref
+			}
+		}
+		return paths, ""
+	}
+
+	// storeError maps a workspace store error to a response.
+	storeError := func(ctx *bebo.Context, err error) error {
+		switch {
+		case errors.Is(err, store.ErrExists):
+			return ctx.JSON(http.StatusConflict, map[string]string{"error": "a workspace with this name already exists"})
+		case errors.Is(err, store.ErrNotFound):
+			return ctx.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+		default:
+			return ctx.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+	}
+
+	bulk := func(run func(string) ([]service.Result, error)) bebo.Handler {
+		return func(ctx *bebo.Context) error {
+			results, err := run(ctx.Param("id"))
+			if errors.Is(err, store.ErrNotFound) {
+				return ctx.JSON(http.StatusNotFound, map[string]string{"error": "workspace not found"})
+			} else if err != nil {
+				return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+			}
+			return ctx.JSON(http.StatusOK, newBulkResponse(results))
+		}
+	}
+
+	api.GET("/workspaces", func(ctx *bebo.Context) error {
+		list := projects.Workspaces()
+		out := fragoclient.WorkspaceList{Workspaces: make([]fragoclient.Workspace, 0, len(list))}
+		for _, w := range list {
+			out.Workspaces = append(out.Workspaces, view(w))
+		}
+		out.Count = len(out.Workspaces)
+		return ctx.JSON(http.StatusOK, out)
+	})
+
+	api.POST("/workspaces", func(ctx *bebo.Context) error {
+		var req fragoclient.WorkspaceRequest
+		if err := ctx.BindJSON(&req); err != nil {
+			return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+		}
+		if req.Name == nil || strings.TrimSpace(*req.Name) == "" {
+			return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "name is required"})
+		}
+		var members []string
+		if req.Projects != nil {
+			var msg string
+			if members, msg = resolveProjects(*req.Projects); msg != "" {
+				return ctx.JSON(http.StatusBadRequest, map[string]string{"error": msg})
+			}
+		}
+
+		created, err := projects.CreateWorkspace(*req.Name, members)
+		if err != nil {
+			return storeError(ctx, err)
+		}
+		return ctx.JSON(http.StatusCreated, view(created))
+	})
+
+	api.GET("/workspaces/:id", func(ctx *bebo.Context) error {
+		w, ok := projects.GetWorkspace(ctx.Param("id"))
+		if !ok {
+			return ctx.JSON(http.StatusNotFound, map[string]string{"error": "workspace not found"})
+		}
+		return ctx.JSON(http.StatusOK, view(w))
+	})
+
+	api.PATCH("/workspaces/:id", func(ctx *bebo.Context) error {
+		id := ctx.Param("id")
+		if _, ok := projects.GetWorkspace(id); !ok {
+			return ctx.JSON(http.StatusNotFound, map[string]string{"error": "workspace not found"})
+		}
+
+		var req fragoclient.WorkspaceRequest
+		if err := ctx.BindJSON(&req); err != nil {
+			return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+		}
+		var members []string
+		if req.Projects != nil {
+			var msg string
+			if members, msg = resolveProjects(*req.Projects); msg != "" {
+				return ctx.JSON(http.StatusBadRequest, map[string]string{"error": msg})
+			}
+		}
+
+		updated, err := projects.UpdateWorkspace(id, func(w *store.Workspace) {
+			if req.Name != nil {
+				w.Name = *req.Name
+			}
+			if req.Projects != nil {
+				w.Projects = members
+			}
+		})
+		if err != nil {
+			return storeError(ctx, err)
+		}
+		return ctx.JSON(http.StatusOK, view(updated))
+	})
+
+	api.DELETE("/workspaces/:id", func(ctx *bebo.Context) error {
+		id := ctx.Param("id")
+		if err := projects.DeleteWorkspace(id); errors.Is(err, store.ErrNotFound) {
+			return ctx.JSON(http.StatusNotFound, map[string]string{"error": "workspace not found"})
+		} else if err != nil {
+			return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		}
+		return ctx.JSON(http.StatusOK, fragoclient.DeleteResponse{Status: "deleted", ID: id})
+	})
+
+	api.POST("/workspaces/:id/start", bulk(svc.StartGroup))
+	api.POST("/workspaces/:id/stop", bulk(svc.StopGroup))
+}
@@ -0,0 +1,133 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/devmarvs/frago/internal/phpini"
+	"github.com/devmarvs/frago/internal/runner"
+	"github.com/devmarvs/frago/internal/store"
+)
+
+// PHPFromDebug is the source of the php.ini settings set by the Xdebug
+// toggle. They win over every other layer.
+const PHPFromDebug = "debug toggle"
+
+// ErrNoXdebug indicates that PHP in a project's FrankenPHP binary does not
+// load the Xdebug extension, so turning debugging on would have no effect.
+var ErrNoXdebug = errors.New("the FrankenPHP binary does not load the xdebug extension")
+
+// ValidateXdebug checks the Xdebug client settings.
+func ValidateXdebug(x store.Xdebug) error {
+	var errs []error
+	if x.ClientPort < 0 || x.ClientPort > 65535 {
+		errs = append(errs, fmt.Errorf("xdebug client port %d is invalid; must be between 1 and 65535", x.ClientPort))
+	}
+	if strings.ContainsAny(x.ClientHost, " \t\r\n\"") {
+		errs = append(errs, fmt.Errorf("xdebug client host %q is invalid", x.ClientHost))
+	}
+	return errors.Join(errs...)
+}
+
+// XdebugLayer returns the php.ini settings that turn step debugging on. The
+// layer is empty when x is disabled.
+func XdebugLayer(x store.Xdebug) phpini.Layer {
+	layer := phpini.Layer{Name: PHPFromDebug}
+	if !x.Enabled {
+		return layer
+	}
+	layer.Values = map[string]string{
+		"xdebug.mode":               "debug",
+		"xdebug.start_with_request": "yes",
+	}
+	if x.Trigger {
+		layer.Values["xdebug.start_with_request"] = "trigger"
+	}
+	if x.ClientHost != "" {
+		layer.Values["xdebug.client_host"] = x.ClientHost
+	}
+	if x.ClientPort > 0 {
+		layer.Values["xdebug.client_port"] = strconv.Itoa(x.ClientPort)
+	}
+	return layer
+}
+
+// xdebugEnv returns the environment that turns step debugging on, or nil
+// when x is disabled. XDEBUG_MODE takes precedence over xdebug.mode in any
+// php.ini file.
+func xdebugEnv(x store.Xdebug) []string {
+	if !x.Enabled {
+		return nil
+	}
+	return []string{"XDEBUG_MODE=debug"}
+}
+
+// SetXdebug saves the Xdebug settings of the saved project in path. A
+// running project is restarted so they apply at once; restarted reports
+// whether it was. Turning debugging on fails with ErrNoXdebug when the
+// project's binary does not load the extension.
+func (s *Service) SetXdebug(path string, x store.Xdebug) (restarted bool, err error) {
+	if err := ValidateXdebug(x); err != nil {
+		return false, err
+	}
+	if saved, ok := s.projects.Get(path); ok && x.Enabled {
+		if err := s.checkXdebug(saved); err != nil {
+			return false, err
+		}
+	}
+	if _, err := s.projects.Update(path, func(p *store.Project) {
+		p.Xdebug = x
+	}); err != nil {
+		return false, err
+	}
+	if _, running := s.mgr.Get(path); !running {
+		return false, nil
+	}
+	return true, s.Restart(path)
+}
+
+// checkXdebug fails with ErrNoXdebug when PHP in the binary the saved
+// project starts with, given its php.ini settings, does not know
+// "xdebug.mode". A project whose binary cannot be run is let through;
+// starting it reports the problem.
+func (s *Service) checkXdebug(saved store.Project) error {
+	binary := s.StartBinary(saved)
+	if binary == "" {
+		binary = runner.DefaultFrankenPHPBinary()
+		if found, err := exec.LookPath(binary); err == nil {
+			binary = found
+		}
+	}
+	values, err := s.queryPHPIni(saved.Path, binary, []string{"xdebug.mode"})
+	if err != nil {
+		return nil
+	}
+	if _, ok := values["xdebug.mode"]; !ok {
+		return fmt.Errorf("%w: %s", ErrNoXdebug, binary)
+	}
+	return nil
+}
+
+// XdebugSummary describes the Xdebug settings of a project, such as
+// "On (trigger, 127.0.0.1:9003)" or "Off".
+func XdebugSummary(x store.Xdebug) string {
+	if !x.Enabled {
+		return "Off"
+	}
+	details := []string{"every request"}
+	if x.Trigger {
+		details[0] = "trigger"
+	}
+	host, port := x.ClientHost, x.ClientPort
+	if host == "" {
+		host = "localhost"
+	}
+	if port == 0 {
+		port = 9003
+	}
+	details = append(details, fmt.Sprintf("%s:%d", host, port))
+	return "On (" + strings.Join(details, ", ") + ")"
+}
@@ -0,0 +1,196 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/devmarvs/frago/internal/manifest"
+	"github.com/devmarvs/frago/internal/store"
+)
+
+// ReadyTimeout is how long a dependency may take to answer health checks
+// before the projects depending on it are given up on.
+const ReadyTimeout = 30 * time.Second
+
+const readyPollInterval = 250 * time.Millisecond
+
+// ErrDependencyCycle indicates projects that depend on each other.
+var ErrDependencyCycle = errors.New("dependency cycle")
+
+// Dependencies returns the saved paths info depends on, from its saved
+// settings and its manifest.
+func (s *Service) Dependencies(info store.Project) ([]string, error) {
+	deps := append([]string(nil), info.DependsOn...)
+	m, err := manifest.Load(info.Path)
+	if err != nil {
+		return nil, fmt.Errorf("manifest error: %w", err)
+	}
+	for _, dep := range m.Dependencies(info.Path) {
+		if !slices.Contains(deps, dep) {
+			deps = append(deps, dep)
+		}
+	}
+	return deps, nil
+}
+
+// startPlan orders paths and their dependencies so that every project comes
+// after the projects it depends on, keeping the given order otherwise.
+// Projects that cannot be started because of a missing dependency or a cycle
+// are returned in errs.
+func (s *Service) startPlan(paths []string) (order []string, errs map[string]error) {
+	const (
+		visiting = 1
+		done     = 2
+	)
+	state := make(map[string]int)
+	errs = make(map[string]error)
+	var stack []string
+
+	var visit func(path string) error
+	visit = func(path string) error {
+		switch state[path] {
+		case done:
+			return errs[path]
+		case visiting:
+			i := slices.Index(stack, path)
+			cycle := append(append([]string(nil), stack[i:]...), path)
+			return fmt.Errorf("%w: %s", ErrDependencyCycle, strings.Join(cycle, " -> "))
+		}
+		state[path] = visiting
+		stack = append(stack, path)
+
+		var err error
+		if info, ok := s.projects.Get(path); !ok {
+			err = fmt.Errorf("%w: %s", store.ErrNotFound, path)
+		} else if deps, depErr := s.Dependencies(info); depErr != nil {
+			err = depErr
+		} else {
+			for _, dep := range deps {
+				depErr := visit(dep)
+				if depErr != nil && err == nil {
+					if errors.Is(depErr, ErrDependencyCycle) {
+						err = depErr
+					} else {
+						err = fmt.Errorf("dependency %s: %w", dep, depErr)
+					}
+				}
+			}
+		}
+
+		stack = stack[:len(stack)-1]
+		state[path] = done
+		if err != nil {
+			errs[path] = err
+		}
+		order = append(order, path)
+		return err
+	}
+
+	for _, path := range paths {
+		_ = visit(path)
+	}
+	return order, errs
+}
+
+// startOrdered starts paths and their dependencies, dependencies first. Before
+// a project starts, each of its dependencies must be running and answer
+// health checks; otherwise the project is reported as failed.
+func (s *Service) startOrdered(paths []string) []Result {
+	order, errs := s.startPlan(paths)
+	client := &http.Client{Timeout: healthCheckTimeout}
+	ready := make(map[string]error)
+
+	results := make([]Result, 0, len(order))
+	for _, path := range order {
+		if err := errs[path]; err != nil {
+			results = append(results, Result{ID: store.ProjectID(path), Path: path, Status: StatusError, Error: err.Error()})
+			continue
+		}
+
+		var err error
+		if info, ok := s.projects.Get(path); ok {
+			deps, _ := s.Dependencies(info)
+			for _, dep := range deps {
+				depErr, checked := ready[dep]
+				if !checked {
+					depErr = s.waitReady(client, dep, ReadyTimeout)
+					ready[dep] = depErr
+				}
+				if depErr != nil {
+					err = fmt.Errorf("dependency %s is not ready: %w", dep, depErr)
+					break
+				}
+			}
+		}
+		if err != nil {
+			results = append(results, Result{ID: store.ProjectID(path), Path: path, Status: StatusError, Error: err.Error()})
+			continue
+		}
+		results = append(results, s.startOne(path))
+	}
+	return results
+}
+
+// stopOrdered stops the running projects among paths, dependents before the
+// projects they depend on. A project that has dependencies is waited for
+// until it exits, so its dependencies are never stopped under it.
+func (s *Service) stopOrdered(paths []string) []Result {
+	order, _ := s.startPlan(paths)
+	var results []Result
+	for i := len(order) - 1; i >= 0; i-- {
+		path := order[i]
+		if !slices.Contains(paths, path) {
+			continue
+		}
+		if _, exists := s.mgr.Get(path); !exists {
+			results = append(results, Result{ID: store.ProjectID(path), Path: path, OK: true, Status: StatusSkipped})
+			continue
+		}
+		result := s.stopOne(path)
+		if result.OK && s.hasDependencies(path) {
+			if err := s.waitStopped(path, stopTimeout); err != nil {
+				result.OK = false
+				result.Status = StatusError
+				result.Error = err.Error()
+			}
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+func (s *Service) hasDependencies(path string) bool {
+	info, ok := s.projects.Get(path)
+	if !ok {
+		return false
+	}
+	deps, err := s.Dependencies(info)
+	return err == nil && len(deps) > 0
+}
+
+// waitReady waits until the project at path is running and passes a health
+// check.
+func (s *Service) waitReady(client *http.Client, path string, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	lastErr := "not checked"
+	for {
+		proc, ok := s.mgr.Get(path)
+		if !ok {
+			return fmt.Errorf("not running")
+		}
+		healthy, errText := checkHealth(client, proc.URL+s.healthPath(path))
+		s.setHealth(path, healthy, errText)
+		if healthy {
+			return nil
+		}
+		lastErr = errText
+		if time.Now().After(deadline) {
+			return fmt.Errorf("no healthy response after %s: %s", timeout, lastErr)
+		}
+		time.Sleep(readyPollInterval)
+	}
+}
@@ -0,0 +1,124 @@
+package service
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/devmarvs/frago/internal/runner"
+)
+
+// Event kinds reported to OnEvent handlers.
+const (
+	// EventCrashed is sent when a process exits with an error without being
+	// stopped.
+	EventCrashed = "crashed"
+	// EventUnhealthy is sent when a healthy project fails a health check.
+	EventUnhealthy = "unhealthy"
+	// EventPHPMismatch is sent when a project starts with a binary that does
+	// not satisfy its PHP requirement.
+	EventPHPMismatch = "php-mismatch"
+	// EventSidecarFailed is sent when a sidecar fails to start with its
+	// project or exits with an error without being stopped.
+	EventSidecarFailed = "sidecar-failed"
+)
+
+// eventLogLines is how many recent log lines are searched for LastLine.
+const eventLogLines = 50
+
+// Event reports a project that crashed, turned unhealthy or started with
+// the wrong PHP version, or a sidecar that failed.
+type Event struct {
+	Kind string
+	Path string
+	// Sidecar names the sidecar of the project that failed, if any.
+	Sidecar string
+	// Error is the exit error, the failed health check or the PHP mismatch.
+	Error string
+	// LastLine is the last error the project or sidecar logged, or its last
+	// log line.
+	LastLine string
+}
+
+// OnEvent registers fn to be called for every Event. fn runs on the
+// goroutine that noticed the change. The returned function unregisters it.
+func (s *Service) OnEvent(fn func(Event)) func() {
+	s.eventMu.Lock()
+	defer s.eventMu.Unlock()
+	id := s.nextHandler
+	s.nextHandler++
+	s.handlers[id] = fn
+	return func() {
+		s.eventMu.Lock()
+		defer s.eventMu.Unlock()
+		delete(s.handlers, id)
+	}
+}
+
+func (s *Service) emit(kind, path, errText string) {
+	s.emitEvent(Event{Kind: kind, Path: path, Error: errText})
+}
+
+func (s *Service) emitEvent(e Event) {
+	s.eventMu.Lock()
+	handlers := make([]func(Event), 0, len(s.handlers))
+	for _, fn := range s.handlers {
+		handlers = append(handlers, fn)
+	}
+	s.eventMu.Unlock()
+	if len(handlers) == 0 {
+		return
+	}
+
+	logs := s.mgr.TailLogs(e.Path, eventLogLines)
+	if e.Sidecar != "" {
+		logs = s.mgr.TailSidecarLogs(e.Path, e.Sidecar, eventLogLines)
+	}
+	e.LastLine = lastErrorLine(strings.Split(logs, "\n"))
+	for _, fn := range handlers {
+		fn(e)
+	}
+}
+
+// handleExit forgets the health of an exited process and reports crashes.
+func (s *Service) handleExit(path string, info runner.ExitInfo) {
+	s.monitorMu.Lock()
+	delete(s.health, path)
+	s.monitorMu.Unlock()
+	if info.Failed {
+		s.emit(EventCrashed, path, info.Err)
+	}
+}
+
+// lastErrorLine returns the last line that mentions an error, or else the
+// last non-empty line. For JSON log lines, as Caddy writes them, only the
+// message and error fields are returned.
+func lastErrorLine(lines []string) string {
+	last := ""
+	for i := len(lines) - 1; i >= 0; i-- {
+		line := strings.TrimSpace(lines[i])
+		if line == "" {
+			continue
+		}
+		if last == "" {
+			last = line
+		}
+		if strings.Contains(strings.ToLower(line), "error") {
+			return logMessage(line)
+		}
+	}
+	return logMessage(last)
+}
+
+func logMessage(line string) string {
+	var entry struct {
+		Msg   string `json:"msg"`
+		Error string `json:"error"`
+	}
+	if !strings.HasPrefix(line, "{") || json.Unmarshal([]byte(line), &entry) != nil || entry.Msg == "" {
+		return line
+	}
+	if entry.Error != "" {
+		return entry.Msg + ": " + entry.Error
+	}
+	return entry.Msg
+}
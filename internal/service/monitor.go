@@ -0,0 +1,144 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/devmarvs/frago/internal/runner"
+)
+
+const healthCheckTimeout = 2 * time.Second
+
+// Health is the result of the last HTTP health check for a running project.
+type Health struct {
+	Healthy   bool      `json:"healthy"`
+	CheckedAt time.Time `json:"checked_at"`
+	LastError string    `json:"last_error,omitempty"`
+}
+
+// Stats is the last CPU and memory sample for a running project.
+// CPUPercent is negative when the platform could not report it.
+type Stats struct {
+	CPUPercent float64   `json:"cpu_percent"`
+	RSSBytes   int64     `json:"rss_bytes"`
+	CheckedAt  time.Time `json:"checked_at"`
+	LastError  string    `json:"last_error,omitempty"`
+}
+
+// Health returns the last health check result for path.
+func (s *Service) Health(path string) (Health, bool) {
+	s.monitorMu.Lock()
+	defer s.monitorMu.Unlock()
+	info, ok := s.health[path]
+	return info, ok
+}
+
+// Stats returns the last process stats sample for path.
+func (s *Service) Stats(path string) (Stats, bool) {
+	s.monitorMu.Lock()
+	defer s.monitorMu.Unlock()
+	info, ok := s.stats[path]
+	return info, ok
+}
+
+// RunMonitor checks health and samples stats for every running process and
+// sidecar and applies restart policies each interval until ctx is canceled.
+func (s *Service) RunMonitor(ctx context.Context, interval time.Duration) {
+	client := &http.Client{Timeout: healthCheckTimeout}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		for _, proc := range s.mgr.List() {
+			healthy, errText := checkHealth(client, proc.URL+s.healthPath(proc.ProjectPath))
+			s.setHealth(proc.ProjectPath, healthy, errText)
+
+			if pid := proc.PID(); pid > 0 {
+				stats, err := runner.GetProcessStats(pid)
+				statsErr := ""
+				if err != nil {
+					statsErr = err.Error()
+				}
+				s.setStats(proc.ProjectPath, stats, statsErr)
+			}
+		}
+		for _, proc := range s.mgr.Sidecars("") {
+			if pid := proc.PID(); pid > 0 {
+				stats, err := runner.GetProcessStats(pid)
+				statsErr := ""
+				if err != nil {
+					statsErr = err.Error()
+				}
+				s.setStats(sidecarID(proc.ProjectPath, proc.Name), stats, statsErr)
+			}
+		}
+		s.applyRestartPolicies()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// setHealth records a health check and reports a project that was healthy
+// and no longer is.
+func (s *Service) setHealth(path string, healthy bool, errText string) {
+	s.monitorMu.Lock()
+	prev, checked := s.health[path]
+	s.health[path] = Health{
+		Healthy:   healthy,
+		CheckedAt: time.Now(),
+		LastError: errText,
+	}
+	s.monitorMu.Unlock()
+
+	if checked && prev.Healthy && !healthy {
+		s.emit(EventUnhealthy, path, errText)
+	}
+}
+
+func (s *Service) setHealthPath(path, healthPath string) {
+	s.monitorMu.Lock()
+	defer s.monitorMu.Unlock()
+	if healthPath == "" {
+		delete(s.healthPaths, path)
+	} else {
+		s.healthPaths[path] = healthPath
+	}
+}
+
+func (s *Service) healthPath(path string) string {
+	s.monitorMu.Lock()
+	defer s.monitorMu.Unlock()
+	return s.healthPaths[path]
+}
+
+func (s *Service) setStats(path string, stats runner.ProcessStats, errText string) {
+	s.monitorMu.Lock()
+	defer s.monitorMu.Unlock()
+	s.stats[path] = Stats{
+		CPUPercent: stats.CPUPercent,
+		RSSBytes:   stats.RSSBytes,
+		CheckedAt:  time.Now(),
+		LastError:  errText,
+	}
+}
+
+func checkHealth(client *http.Client, url string) (bool, string) {
+	if url == "" {
+		return false, "missing url"
+	}
+	resp, err := client.Get(url)
+	if err != nil {
+		return false, err.Error()
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 200 && resp.StatusCode < 400 {
+		return true, ""
+	}
+	return false, fmt.Sprintf("http %d", resp.StatusCode)
+}
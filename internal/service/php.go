@@ -0,0 +1,152 @@
+package service
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/devmarvs/frago/internal/manifest"
+	"github.com/devmarvs/frago/internal/runner"
+	"github.com/devmarvs/frago/internal/store"
+)
+
+// Sources of a PHP requirement, in order of precedence.
+const (
+	PHPFromSettings = "project settings"
+	PHPFromManifest = "manifest"
+	PHPFromComposer = manifest.ComposerFile
+)
+
+// PHPRequirement is the PHP version constraint a project asks for.
+type PHPRequirement struct {
+	// Constraint is empty when the project sets none.
+	Constraint string
+	// Source is one of the PHPFrom constants.
+	Source string
+}
+
+func (r PHPRequirement) String() string {
+	return fmt.Sprintf("PHP %s (from %s)", r.Constraint, r.Source)
+}
+
+// PHPRequirement returns the PHP constraint of the project in path: the one
+// saved in its settings, else the one in its manifest, else "require.php"
+// from its composer.json.
+func (s *Service) PHPRequirement(path string) (PHPRequirement, error) {
+	m, err := manifest.Load(path)
+	if err != nil {
+		return PHPRequirement{}, fmt.Errorf("manifest error: %w", err)
+	}
+	saved, _ := s.projects.Get(path)
+	return phpRequirement(path, m, saved)
+}
+
+func phpRequirement(path string, m manifest.Manifest, saved store.Project) (PHPRequirement, error) {
+	if saved.PHP != "" {
+		return PHPRequirement{saved.PHP, PHPFromSettings}, nil
+	}
+	if m.PHP != "" {
+		return PHPRequirement{m.PHP, PHPFromManifest}, nil
+	}
+	php, err := manifest.ComposerPHP(path)
+	if err != nil || php == "" {
+		return PHPRequirement{}, err
+	}
+	return PHPRequirement{php, PHPFromComposer}, nil
+}
+
+// MatchPHP returns the detected binary with the newest PHP version that
+// satisfies constraint. It fails with ErrNoMatchingPHP when none does.
+func (s *Service) MatchPHP(constraint string) (runner.PHPVersion, error) {
+	c, err := manifest.ParseConstraint(constraint)
+	if err != nil {
+		return runner.PHPVersion{}, err
+	}
+	var best runner.PHPVersion
+	found := false
+	for _, v := range s.Versions() {
+		if c.Matches(v.Version) && (!found || manifest.CompareVersions(v.Version, best.Version) > 0) {
+			best, found = v, true
+		}
+	}
+	if !found {
+		return runner.PHPVersion{}, fmt.Errorf("%w: %s", ErrNoMatchingPHP, constraint)
+	}
+	return best, nil
+}
+
+// satisfiesPHP reports whether the binary chosen by binaryPath or
+// versionLabel satisfies constraint. ok is false when its PHP version is not
+// known, such as for a binary that was never detected.
+func (s *Service) satisfiesPHP(binaryPath, versionLabel, constraint string) (satisfies, ok bool) {
+	c, err := manifest.ParseConstraint(constraint)
+	if err != nil {
+		return false, false
+	}
+	for _, v := range s.Versions() {
+		if (binaryPath != "" && v.Path == binaryPath) || (binaryPath == "" && versionLabel != "" && v.Label == versionLabel) {
+			return c.Matches(v.Version), true
+		}
+	}
+	return false, false
+}
+
+// resolvePHP applies the project's PHP requirement to the binary chosen for
+// it. Without a chosen binary, the best match is used, or the default binary
+// when no detected binary matches or the constraint cannot be parsed. A
+// binary that does not satisfy the requirement is still used, but reported
+// with an EventPHPMismatch.
+func (s *Service) resolvePHP(path string, req PHPRequirement, binaryPath, versionLabel string) (string, string) {
+	if req.Constraint == "" {
+		return binaryPath, versionLabel
+	}
+	if binaryPath == "" && versionLabel == "" {
+		match, err := s.MatchPHP(req.Constraint)
+		if err != nil {
+			s.emit(EventPHPMismatch, path, fmt.Sprintf("%v (required by %s); using the default binary.", err, req.Source))
+			return "", ""
+		}
+		return match.Path, match.Label
+	}
+	if satisfies, ok := s.satisfiesPHP(binaryPath, versionLabel, req.Constraint); ok && !satisfies {
+		s.emit(EventPHPMismatch, path, fmt.Sprintf("The selected binary does not satisfy %s.", req))
+	}
+	return binaryPath, versionLabel
+}
+
+// staleBinary reports whether the binary saved for a project should give way
+// to the best match for its PHP requirement: it was moved or deleted, or it
+// no longer satisfies the requirement, for example after an upgrade, and a
+// detected binary does.
+func (s *Service) staleBinary(req PHPRequirement, binaryPath, versionLabel string) bool {
+	if req.Constraint == "" || (binaryPath == "" && versionLabel == "") {
+		return false
+	}
+	if _, err := s.MatchPHP(req.Constraint); err != nil {
+		return false
+	}
+	if binaryPath != "" {
+		if _, err := os.Stat(binaryPath); err != nil {
+			return true
+		}
+	}
+	satisfies, ok := s.satisfiesPHP(binaryPath, versionLabel, req.Constraint)
+	return ok && !satisfies
+}
+
+// StartBinary returns the binary the saved project info would be started
+// with, or "" for the default binary.
+func (s *Service) StartBinary(info store.Project) string {
+	binaryPath, versionLabel := s.ResolveStartOptions(info)
+	if binaryPath != "" || versionLabel != "" {
+		return binaryPath
+	}
+	req, err := s.PHPRequirement(info.Path)
+	if err != nil || req.Constraint == "" {
+		return ""
+	}
+	match, err := s.MatchPHP(req.Constraint)
+	if err != nil {
+		return ""
+	}
+	return match.Path
+}
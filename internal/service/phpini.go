@@ -0,0 +1,115 @@
+package service
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/devmarvs/frago/internal/manifest"
+	"github.com/devmarvs/frago/internal/phpini"
+	"github.com/devmarvs/frago/internal/runner"
+	"github.com/devmarvs/frago/internal/store"
+)
+
+// PHPIni returns the php.ini settings Frago applies to the project in path,
+// with the layer each one comes from: the preset, the manifest, the project
+// settings or the debug toggle.
+func (s *Service) PHPIni(path string) ([]phpini.Setting, error) {
+	m, err := manifest.Load(path)
+	if err != nil {
+		return nil, fmt.Errorf("manifest error: %w", err)
+	}
+	saved, _ := s.projects.Get(path)
+	return phpIniSettings(m, saved), nil
+}
+
+// phpIniSettings layers the php.ini settings saved for a project over those
+// of its manifest, and the Xdebug settings over both. A saved preset
+// replaces the manifest's.
+func phpIniSettings(m manifest.Manifest, saved store.Project) []phpini.Setting {
+	preset := m.PHPIniPreset
+	if saved.PHPIniPreset != "" {
+		preset = saved.PHPIniPreset
+	}
+	return phpini.Effective(preset,
+		phpini.Layer{Name: PHPFromManifest, Values: m.PHPIni},
+		phpini.Layer{Name: PHPFromSettings, Values: saved.PHPIni},
+		XdebugLayer(saved.Xdebug),
+	)
+}
+
+// phpIniScanDir returns the directory holding the generated ini file of the
+// project in path.
+func phpIniScanDir(path string) (string, error) {
+	dir, err := phpini.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, store.ProjectID(path)), nil
+}
+
+// phpIniEnv writes the php.ini settings of the project in path and returns
+// the environment entry that makes FrankenPHP load them. It returns "" when
+// there are no settings. env is the project environment, which may already
+// set PHP_INI_SCAN_DIR.
+func phpIniEnv(path string, settings []phpini.Setting, env map[string]string) (string, error) {
+	scanDir, err := phpIniScanDir(path)
+	if err != nil {
+		return "", err
+	}
+	if err := phpini.Write(scanDir, settings); err != nil {
+		return "", fmt.Errorf("php.ini error: %w", err)
+	}
+	if len(settings) == 0 {
+		return "", nil
+	}
+	inherited, ok := env["PHP_INI_SCAN_DIR"]
+	if !ok {
+		inherited = os.Getenv("PHP_INI_SCAN_DIR")
+	}
+	return phpini.ScanDirEnv(inherited, scanDir), nil
+}
+
+// projectEnv returns the environment PHP runs with in the project in path:
+// its env vars, the Xdebug toggle and the php.ini settings, which it writes.
+// m must already have the saved settings applied.
+func projectEnv(path string, m manifest.Manifest, saved store.Project) ([]string, error) {
+	env := append(m.Environ(), xdebugEnv(saved.Xdebug)...)
+	entry, err := phpIniEnv(path, phpIniSettings(m, saved), m.Env)
+	if err != nil {
+		return nil, err
+	}
+	if entry != "" {
+		env = append(env, entry)
+	}
+	return env, nil
+}
+
+// QueryPHPIni writes the php.ini settings of the project in path and asks
+// the binary at binaryPath for the values PHP ends up with, including the
+// ones set by php.ini files Frago did not write. Settings PHP does not know
+// are left out.
+func (s *Service) QueryPHPIni(path, binaryPath string) (map[string]string, error) {
+	return s.queryPHPIni(path, binaryPath, nil)
+}
+
+// queryPHPIni is QueryPHPIni for keys, or for the settings Frago applies
+// when keys is nil.
+func (s *Service) queryPHPIni(path, binaryPath string, keys []string) (map[string]string, error) {
+	m, err := manifest.Load(path)
+	if err != nil {
+		return nil, fmt.Errorf("manifest error: %w", err)
+	}
+	saved, ok := s.projects.Get(path)
+	if ok {
+		applySettings(&m, saved)
+	}
+	env, err := projectEnv(path, m, saved)
+	if err != nil {
+		return nil, err
+	}
+	if keys == nil {
+		keys = phpini.Keys(phpIniSettings(m, saved))
+	}
+	return runner.QueryINI(binaryPath, env, keys)
+}
@@ -0,0 +1,436 @@
+// Package service implements project lifecycle operations on top of the
+// process manager and the project store, so the GUI and the API behave alike.
+package service
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/devmarvs/frago/internal/caddy"
+	"github.com/devmarvs/frago/internal/manifest"
+	"github.com/devmarvs/frago/internal/runner"
+	"github.com/devmarvs/frago/internal/store"
+)
+
+// DefaultVersionLabel is the label of the FrankenPHP binary found on PATH.
+const DefaultVersionLabel = "Default (System Path)"
+
+const stopTimeout = 3 * time.Second
+
+// ErrRunning indicates an operation that requires a stopped project.
+var ErrRunning = errors.New("project is running")
+
+// ErrNoMatchingPHP indicates that no detected FrankenPHP binary satisfies
+// the PHP constraint of a project.
+var ErrNoMatchingPHP = errors.New("no FrankenPHP binary matches the required PHP version")
+
+// Result statuses reported by bulk operations.
+const (
+	StatusStarted = "started"
+	StatusStopped = "stopped"
+	StatusSkipped = "skipped"
+	StatusError   = "error"
+)
+
+// Result is the outcome of a bulk operation for a single project.
+type Result struct {
+	ID     string `json:"id"`
+	Path   string `json:"path"`
+	OK     bool   `json:"ok"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Service starts, stops and restarts saved projects.
+type Service struct {
+	mgr      *runner.Manager
+	projects *store.Store
+
+	versionsMu sync.Mutex
+	versions   map[string]string
+	detected   []runner.PHPVersion
+	detector   *runner.Detector
+
+	monitorMu   sync.Mutex
+	health      map[string]Health
+	stats       map[string]Stats
+	healthPaths map[string]string
+	restarts    map[string]restartState
+	sidecars    map[string]sidecarPolicy
+
+	eventMu         sync.Mutex
+	handlers        map[int]func(Event)
+	versionHandlers map[int]func([]runner.PHPVersion)
+	nextHandler     int
+}
+
+// New creates a service. versions are the detected FrankenPHP binaries used to
+// resolve a project's remembered version label back to a binary path.
+func New(mgr *runner.Manager, projects *store.Store, versions []runner.PHPVersion) *Service {
+	s := &Service{
+		mgr:             mgr,
+		projects:        projects,
+		health:          make(map[string]Health),
+		stats:           make(map[string]Stats),
+		healthPaths:     make(map[string]string),
+		restarts:        make(map[string]restartState),
+		sidecars:        make(map[string]sidecarPolicy),
+		handlers:        make(map[int]func(Event)),
+		detector:        runner.DefaultDetector(),
+		versionHandlers: make(map[int]func([]runner.PHPVersion)),
+	}
+	s.SetVersions(versions)
+	mgr.SetExitHandler(s.handleExit)
+	mgr.SetSidecarExitHandler(s.handleSidecarExit)
+	return s
+}
+
+// SetVersions replaces the detected FrankenPHP binaries, for example after
+// one was downloaded, and passes them to the OnVersions handlers when they
+// changed.
+func (s *Service) SetVersions(versions []runner.PHPVersion) {
+	versionMap := make(map[string]string, len(versions))
+	for _, v := range versions {
+		if _, exists := versionMap[v.Label]; !exists {
+			versionMap[v.Label] = v.Path
+		}
+	}
+	s.versionsMu.Lock()
+	changed := !reflect.DeepEqual(s.detected, versions)
+	s.versions = versionMap
+	s.detected = append([]runner.PHPVersion(nil), versions...)
+	s.versionsMu.Unlock()
+	if !changed {
+		return
+	}
+
+	s.eventMu.Lock()
+	handlers := make([]func([]runner.PHPVersion), 0, len(s.versionHandlers))
+	for _, fn := range s.versionHandlers {
+		handlers = append(handlers, fn)
+	}
+	s.eventMu.Unlock()
+	for _, fn := range handlers {
+		fn(append([]runner.PHPVersion(nil), versions...))
+	}
+}
+
+// Versions returns the detected FrankenPHP binaries.
+func (s *Service) Versions() []runner.PHPVersion {
+	s.versionsMu.Lock()
+	defer s.versionsMu.Unlock()
+	return append([]runner.PHPVersion(nil), s.detected...)
+}
+
+// Manager returns the underlying process manager.
+func (s *Service) Manager() *runner.Manager {
+	return s.mgr
+}
+
+// Projects returns the underlying project store.
+func (s *Service) Projects() *store.Store {
+	return s.projects
+}
+
+// ResolveStartOptions returns the binary path and version label a saved
+// project should be started with. Both are empty when the saved binary is
+// stale and the project's PHP requirement should pick one instead.
+func (s *Service) ResolveStartOptions(info store.Project) (string, string) {
+	binaryPath := info.LastBinaryPath
+	versionLabel := info.LastVersionLabel
+	if strings.HasPrefix(versionLabel, DefaultVersionLabel) {
+		versionLabel = ""
+	}
+	if binaryPath == "" {
+		s.versionsMu.Lock()
+		mapped, ok := s.versions[versionLabel]
+		s.versionsMu.Unlock()
+		if ok {
+			binaryPath = mapped
+		} else if versionLabel != "" {
+			versionLabel = ""
+		}
+	}
+	if req, err := s.PHPRequirement(info.Path); err == nil && s.staleBinary(req, binaryPath, versionLabel) {
+		return "", ""
+	}
+	return binaryPath, versionLabel
+}
+
+// Start launches FrankenPHP for path and records the project in the store.
+// Settings from the project manifest fill in whatever the caller leaves
+// unset: the port when desiredPort is 0 and the binary when neither
+// binaryPath nor versionLabel is given, picked by the project's PHP
+// requirement. The docroot, health path and env vars saved for the project
+// override the manifest. Sidecars that start with the project are started
+// after it.
+func (s *Service) Start(path string, binaryPath string, versionLabel string, desiredPort int) error {
+	m, err := manifest.Load(path)
+	if err != nil {
+		return fmt.Errorf("manifest error: %w", err)
+	}
+	saved, ok := s.projects.Get(path)
+	if ok {
+		applySettings(&m, saved)
+	}
+
+	port := desiredPort
+	if port == 0 {
+		port = m.Port
+	}
+	req, err := phpRequirement(path, m, saved)
+	if err != nil {
+		return err
+	}
+	binary, label := s.resolvePHP(path, req, binaryPath, versionLabel)
+	site := caddy.Site{Root: m.Docroot}
+	if m.Worker != nil {
+		site.WorkerFile = m.Worker.File
+		site.WorkerNum = m.Worker.Num
+	}
+
+	caddyConfig, err := caddy.EnsureSiteCaddyfileAutoPort(path, site, s.mgr.UsedPorts(), port)
+	if err != nil {
+		return fmt.Errorf("caddyfile error: %w", err)
+	}
+
+	env, err := projectEnv(path, m, saved)
+	if err != nil {
+		return err
+	}
+
+	if err := s.mgr.StartWithEnv(path, caddyConfig, binary, label, env); err != nil {
+		return fmt.Errorf("start error: %w", err)
+	}
+	s.setHealthPath(path, m.HealthPath)
+
+	_, _, err = s.projects.Ensure(path)
+	if err == nil {
+		_, err = s.projects.Update(path, func(info *store.Project) {
+			info.PreferredPort = desiredPort
+			info.LastPort = caddyConfig.Port
+			info.LastURL = fmt.Sprintf("http://localhost:%d", caddyConfig.Port)
+			info.LastUsed = time.Now()
+			info.LastVersionLabel = versionLabel
+			info.LastBinaryPath = binaryPath
+		})
+	}
+	if err != nil {
+		// A project missing from the store could not be stopped or
+		// listed, so it must not keep running.
+		if stopErr := s.mgr.Stop(path); stopErr != nil {
+			return fmt.Errorf("save project: %w (stop failed: %v)", err, stopErr)
+		}
+		return fmt.Errorf("save project: %w", err)
+	}
+	s.startSidecars(path, m, saved, binary)
+	return nil
+}
+
+// StartSaved starts a saved project with its remembered binary and port.
+func (s *Service) StartSaved(path string) error {
+	info, ok := s.projects.Get(path)
+	if !ok {
+		return fmt.Errorf("%w: %s", store.ErrNotFound, path)
+	}
+	binaryPath, versionLabel := s.ResolveStartOptions(info)
+	return s.Start(info.Path, binaryPath, versionLabel, info.PreferredPort)
+}
+
+// Stop terminates the process running for path and its sidecars.
+func (s *Service) Stop(path string) error {
+	err := s.mgr.Stop(path)
+	s.StopSidecars(path)
+	return err
+}
+
+// Restart stops the project and its sidecars if it is running, waits for
+// them to exit, and starts it again with its saved settings.
+func (s *Service) Restart(path string) error {
+	if _, exists := s.mgr.Get(path); exists {
+		if _, _, err := s.projects.Ensure(path); err != nil {
+			return err
+		}
+		if err := s.Stop(path); err != nil {
+			return err
+		}
+		if err := s.waitStopped(path, stopTimeout); err != nil {
+			return err
+		}
+		if err := s.waitSidecarsStopped(path, sidecarStopTimeout); err != nil {
+			return err
+		}
+	}
+	return s.StartSaved(path)
+}
+
+// StartAll starts every saved project that is not already running, each
+// after the projects it depends on.
+func (s *Service) StartAll() []Result {
+	return s.startEach(func(store.Project) bool { return true })
+}
+
+// AutoStart starts every saved project that has auto-start enabled, either
+// in its saved settings or in its manifest, along with its dependencies.
+func (s *Service) AutoStart() []Result {
+	return s.startEach(func(info store.Project) bool {
+		if info.AutoStart {
+			return true
+		}
+		m, err := manifest.Load(info.Path)
+		return err == nil && m.AutoStart != nil && *m.AutoStart
+	})
+}
+
+func (s *Service) startEach(include func(store.Project) bool) []Result {
+	var paths []string
+	for _, info := range s.projects.List() {
+		if include(info) {
+			paths = append(paths, info.Path)
+		}
+	}
+	return s.startOrdered(paths)
+}
+
+// StartGroup starts the projects of a workspace in the workspace's start
+// order, moving dependencies ahead of the projects that need them.
+func (s *Service) StartGroup(id string) ([]Result, error) {
+	w, ok := s.projects.GetWorkspace(id)
+	if !ok {
+		return nil, fmt.Errorf("%w: workspace %s", store.ErrNotFound, id)
+	}
+	return s.startOrdered(w.Projects), nil
+}
+
+// StopGroup stops the running projects of a workspace in reverse start
+// order. Projects that are not running are reported as skipped.
+func (s *Service) StopGroup(id string) ([]Result, error) {
+	w, ok := s.projects.GetWorkspace(id)
+	if !ok {
+		return nil, fmt.Errorf("%w: workspace %s", store.ErrNotFound, id)
+	}
+	return s.stopOrdered(w.Projects), nil
+}
+
+// StopAll stops every running project, dependents before their
+// dependencies, and every sidecar, including those of stopped projects.
+func (s *Service) StopAll() []Result {
+	var paths []string
+	for _, proc := range s.mgr.List() {
+		paths = append(paths, proc.ProjectPath)
+	}
+	results := s.stopOrdered(paths)
+	for _, proc := range s.mgr.Sidecars("") {
+		_ = s.mgr.StopSidecar(proc.ProjectPath, proc.Name)
+	}
+	return results
+}
+
+// startOne starts a saved project unless it is already running.
+func (s *Service) startOne(path string) Result {
+	result := Result{ID: store.ProjectID(path), Path: path}
+	if _, exists := s.mgr.Get(path); exists {
+		result.OK = true
+		result.Status = StatusSkipped
+	} else if err := s.StartSaved(path); err != nil {
+		result.Status = StatusError
+		result.Error = err.Error()
+	} else {
+		result.OK = true
+		result.Status = StatusStarted
+	}
+	return result
+}
+
+func (s *Service) stopOne(path string) Result {
+	result := Result{ID: store.ProjectID(path), Path: path}
+	if err := s.Stop(path); err != nil {
+		result.Status = StatusError
+		result.Error = err.Error()
+	} else {
+		result.OK = true
+		result.Status = StatusStopped
+	}
+	return result
+}
+
+// Delete removes a stopped project, its generated Caddyfile, logs and exit
+// status, stopping its sidecars.
+func (s *Service) Delete(path string) error {
+	if _, exists := s.mgr.Get(path); exists {
+		return fmt.Errorf("%w: %s", ErrRunning, path)
+	}
+	if err := caddy.RemoveCaddyfile(path); err != nil {
+		return fmt.Errorf("remove caddyfile: %w", err)
+	}
+	if err := s.projects.Delete(path); err != nil && !errors.Is(err, store.ErrNotFound) {
+		return err
+	}
+	s.StopSidecars(path)
+	s.forgetSidecars(path)
+	s.mgr.ClearLogs(path)
+	s.mgr.ClearExit(path)
+	s.mgr.ClearSidecars(path)
+	return nil
+}
+
+// Sync records running processes in the store, including ones started
+// outside the saved project list, and refreshes their last known port, URL
+// and binary.
+func (s *Service) Sync() error {
+	var errs []error
+	for _, p := range s.mgr.List() {
+		info, _, err := s.projects.Ensure(p.ProjectPath)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if info.LastPort == p.Port && info.LastURL == p.URL && info.LastVersionLabel == p.VersionLabel &&
+			info.LastBinaryPath == p.BinaryPath && !p.StartedAt.After(info.LastUsed) {
+			continue
+		}
+		proc := p
+		_, err = s.projects.Update(p.ProjectPath, func(info *store.Project) {
+			info.LastPort = proc.Port
+			info.LastURL = proc.URL
+			info.LastVersionLabel = proc.VersionLabel
+			info.LastBinaryPath = proc.BinaryPath
+			if proc.StartedAt.After(info.LastUsed) {
+				info.LastUsed = proc.StartedAt
+			}
+		})
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// FormatFailures renders the failed results as "path: error" lines.
+func FormatFailures(results []Result) string {
+	var lines []string
+	for _, r := range results {
+		if !r.OK {
+			lines = append(lines, fmt.Sprintf("%s: %s", r.Path, r.Error))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (s *Service) waitStopped(path string, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		if _, exists := s.mgr.Get(path); !exists {
+			return nil
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	if _, exists := s.mgr.Get(path); exists {
+		return fmt.Errorf("timeout waiting for process to stop")
+	}
+	return nil
+}
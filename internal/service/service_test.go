@@ -0,0 +1,699 @@
+package service
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/devmarvs/frago/internal/runner"
+	"github.com/devmarvs/frago/internal/store"
+)
+
+type memoryBackend struct {
+	data []byte
+}
+
+func (b *memoryBackend) Load() ([]byte, error) { return b.data, nil }
+
+func (b *memoryBackend) Save(data []byte) error {
+	b.data = append([]byte(nil), data...)
+	return nil
+}
+
+// stopAndWait stops the project in dir and waits for it to exit, so its
+// Caddyfile is cleaned up before the test's directories are removed.
+func stopAndWait(svc *Service, dir string) {
+	_ = svc.Stop(dir)
+	_ = svc.waitStopped(dir, stopTimeout)
+}
+
+// failingBackend loads fine but refuses every save.
+type failingBackend struct{}
+
+func (failingBackend) Load() ([]byte, error) { return nil, nil }
+func (failingBackend) Save([]byte) error     { return errors.New("disk full") }
+
+func TestResolveStartOptions(t *testing.T) {
+	svc := New(runner.NewManager(), store.New(&memoryBackend{}), []runner.PHPVersion{
+		{Path: "/opt/frankenphp-8.3", Label: "frankenphp-8.3 (PHP 8.3.1)"},
+	})
+
+	cases := []struct {
+		name       string
+		project    store.Project
+		wantBinary string
+		wantLabel  string
+	}{
+		{"explicit binary", store.Project{LastBinaryPath: "/bin/fp", LastVersionLabel: "fp (PHP 8.2.0)"}, "/bin/fp", "fp (PHP 8.2.0)"},
+		{"known label", store.Project{LastVersionLabel: "frankenphp-8.3 (PHP 8.3.1)"}, "/opt/frankenphp-8.3", "frankenphp-8.3 (PHP 8.3.1)"},
+		{"unknown label", store.Project{LastVersionLabel: "gone (PHP 7.4.0)"}, "", ""},
+		{"default label", store.Project{LastVersionLabel: DefaultVersionLabel + " (PHP 8.4.0)"}, "", ""},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			binary, label := svc.ResolveStartOptions(tc.project)
+			if binary != tc.wantBinary || label != tc.wantLabel {
+				t.Fatalf("expected (%q, %q), got (%q, %q)", tc.wantBinary, tc.wantLabel, binary, label)
+			}
+		})
+	}
+}
+
+func TestStartAllReportsFailuresPerProject(t *testing.T) {
+	projects := store.New(&memoryBackend{})
+	svc := New(runner.NewManager(), projects, nil)
+	for _, path := range []string{"/does/not/exist/a", "/does/not/exist/b"} {
+		if _, err := projects.Create(store.Project{Path: path}); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+	}
+
+	results := svc.StartAll()
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(results))
+	}
+	for _, r := range results {
+		if r.OK || r.Status != StatusError || r.Error == "" || r.ID != store.ProjectID(r.Path) {
+			t.Fatalf("unexpected result: %+v", r)
+		}
+	}
+	if got := FormatFailures(results); got == "" {
+		t.Fatalf("expected failures to be formatted")
+	}
+}
+
+func TestRestartUnknownProject(t *testing.T) {
+	svc := New(runner.NewManager(), store.New(&memoryBackend{}), nil)
+	if err := svc.Restart("/nowhere"); !errors.Is(err, store.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestStartFallsBackOnUnmatchedPHPConstraint(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell script as the FrankenPHP binary")
+	}
+	t.Setenv("FRAGO_PHP_INI_DIR", t.TempDir())
+	binary := filepath.Join(t.TempDir(), "frankenphp")
+	if err := os.WriteFile(binary, []byte("#!/bin/sh\nexec sleep 60\n"), 0755); err != nil {
+		t.Fatalf("write binary: %v", err)
+	}
+	t.Setenv("FRANKENPHP_BINARY", binary)
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "frago.toml"), []byte("php = \"8.4\"\n"), 0644); err != nil {
+		t.Fatalf("write manifest: %v", err)
+	}
+	mgr := runner.NewManager()
+	svc := New(mgr, store.New(&memoryBackend{}), []runner.PHPVersion{
+		{Path: "/opt/frankenphp-8.3", Version: "8.3.1", Label: "frankenphp-8.3 (PHP 8.3.1)"},
+	})
+	var events []Event
+	svc.OnEvent(func(e Event) { events = append(events, e) })
+
+	if err := svc.Start(dir, "", "", 0); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	t.Cleanup(func() { stopAndWait(svc, dir) })
+	if proc, ok := mgr.Get(dir); !ok || proc.BinaryPath != "" || proc.VersionLabel != DefaultVersionLabel {
+		t.Fatalf("expected the default binary to run, got %+v", proc)
+	}
+	if len(events) != 1 || events[0].Kind != EventPHPMismatch || !strings.Contains(events[0].Error, "default binary") {
+		t.Fatalf("expected a mismatch warning, got %+v", events)
+	}
+
+	// Constraints Frago cannot parse fall back the same way.
+	events = nil
+	if binary, label := svc.resolvePHP(dir, PHPRequirement{"8.1 - 8.3", PHPFromComposer}, "", ""); binary != "" || label != "" || len(events) != 1 {
+		t.Fatalf("expected the default binary with a warning, got (%q, %q) %+v", binary, label, events)
+	}
+}
+
+func TestPHPRequirementAndMatch(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "composer.json"), []byte(`{"require": {"php": "^8.2"}}`), 0644); err != nil {
+		t.Fatalf("write composer.json: %v", err)
+	}
+	bin := t.TempDir()
+	old, php83 := filepath.Join(bin, "frankenphp-8.1"), filepath.Join(bin, "frankenphp-8.3")
+	for _, path := range []string{old, php83} {
+		if err := os.WriteFile(path, nil, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	projects := store.New(&memoryBackend{})
+	svc := New(runner.NewManager(), projects, []runner.PHPVersion{
+		{Path: old, Version: "8.1.2", Label: "frankenphp-8.1 (PHP 8.1.2)"},
+		{Path: "/opt/frankenphp-8.4", Version: "8.4.1", Label: "frankenphp-8.4 (PHP 8.4.1)"},
+		{Path: php83, Version: "8.3.9", Label: "frankenphp-8.3 (PHP 8.3.9)"},
+	})
+
+	req, err := svc.PHPRequirement(dir)
+	if err != nil || req != (PHPRequirement{"^8.2", PHPFromComposer}) {
+		t.Fatalf("unexpected requirement %+v (%v)", req, err)
+	}
+	if match, err := svc.MatchPHP(req.Constraint); err != nil || match.Path != "/opt/frankenphp-8.4" {
+		t.Fatalf("expected the newest matching binary, got %+v (%v)", match, err)
+	}
+
+	if _, err := projects.Create(store.Project{Path: dir, PHP: "~8.3.0", LastBinaryPath: old}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if req, _ := svc.PHPRequirement(dir); req != (PHPRequirement{"~8.3.0", PHPFromSettings}) {
+		t.Fatalf("expected the saved constraint to win, got %+v", req)
+	}
+	info, _ := projects.Get(dir)
+	if binary, label := svc.ResolveStartOptions(info); binary != "" || label != "" {
+		t.Fatalf("expected a binary that no longer matches to be dropped, got (%q, %q)", binary, label)
+	}
+	info.LastBinaryPath = php83
+	if binary, _ := svc.ResolveStartOptions(info); binary != php83 {
+		t.Fatalf("expected a matching binary to be kept, got %q", binary)
+	}
+
+	var events []Event
+	svc.OnEvent(func(e Event) { events = append(events, e) })
+	binary, _ := svc.resolvePHP(dir, PHPRequirement{"^8.2", PHPFromComposer}, old, "")
+	if binary != old || len(events) != 1 || events[0].Kind != EventPHPMismatch {
+		t.Fatalf("expected the chosen binary with a mismatch warning, got %q %+v", binary, events)
+	}
+}
+
+func TestPHPIniSettingsAndEnv(t *testing.T) {
+	t.Setenv("FRAGO_PHP_INI_DIR", t.TempDir())
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "frago.toml"), []byte("php_ini_preset = \"production\"\n[php_ini]\nmemory_limit = \"1G\"\n"), 0644); err != nil {
+		t.Fatalf("write manifest: %v", err)
+	}
+	projects := store.New(&memoryBackend{})
+	svc := New(runner.NewManager(), projects, nil)
+	if _, err := projects.Create(store.Project{Path: dir, PHPIniPreset: "development", PHPIni: map[string]string{"xdebug.mode": "debug"}}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	settings, err := svc.PHPIni(dir)
+	if err != nil {
+		t.Fatalf("php.ini: %v", err)
+	}
+	sources := make(map[string]string)
+	for _, s := range settings {
+		sources[s.Key] = s.Value + " from " + s.Source
+	}
+	if sources["memory_limit"] != "1G from "+PHPFromManifest || sources["xdebug.mode"] != "debug from "+PHPFromSettings || sources["display_errors"] != "On from preset development" {
+		t.Fatalf("unexpected settings %v", sources)
+	}
+
+	entry, err := phpIniEnv(dir, settings, map[string]string{"PHP_INI_SCAN_DIR": "/etc/php.d"})
+	if err != nil {
+		t.Fatalf("env: %v", err)
+	}
+	scanDir, _ := phpIniScanDir(dir)
+	if entry != "PHP_INI_SCAN_DIR=/etc/php.d"+string(os.PathListSeparator)+scanDir {
+		t.Fatalf("unexpected env entry %q", entry)
+	}
+	data, err := os.ReadFile(filepath.Join(scanDir, "zz-frago.ini"))
+	if err != nil || !strings.Contains(string(data), "xdebug.mode=debug\n") {
+		t.Fatalf("unexpected ini file %q (%v)", data, err)
+	}
+}
+
+func TestSetXdebug(t *testing.T) {
+	dir := t.TempDir()
+	projects := store.New(&memoryBackend{})
+	svc := New(runner.NewManager(), projects, nil)
+	if _, err := svc.SetXdebug(dir, store.Xdebug{Enabled: true}); !errors.Is(err, store.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound for an unsaved project, got %v", err)
+	}
+	if _, err := projects.Create(store.Project{Path: dir, PHPIni: map[string]string{"xdebug.mode": "off"}}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if _, err := svc.SetXdebug(dir, store.Xdebug{Enabled: true, ClientPort: 70000}); err == nil {
+		t.Fatalf("expected an invalid port to be rejected")
+	}
+
+	x := store.Xdebug{Enabled: true, ClientHost: "host.docker.internal", Trigger: true}
+	if restarted, err := svc.SetXdebug(dir, x); err != nil || restarted {
+		t.Fatalf("expected a stopped project to be saved without a restart, got %v %v", restarted, err)
+	}
+	settings, err := svc.PHPIni(dir)
+	if err != nil {
+		t.Fatalf("php.ini: %v", err)
+	}
+	values := make(map[string]string)
+	for _, s := range settings {
+		values[s.Key] = s.Value + " from " + s.Source
+	}
+	if values["xdebug.mode"] != "debug from "+PHPFromDebug || values["xdebug.start_with_request"] != "trigger from "+PHPFromDebug || values["xdebug.client_host"] != "host.docker.internal from "+PHPFromDebug {
+		t.Fatalf("unexpected settings %v", values)
+	}
+	if got := XdebugSummary(x); got != "On (trigger, host.docker.internal:9003)" {
+		t.Fatalf("unexpected summary %q", got)
+	}
+
+	if _, err := svc.SetXdebug(dir, store.Xdebug{}); err != nil {
+		t.Fatalf("disable: %v", err)
+	}
+	settings, _ = svc.PHPIni(dir)
+	if len(settings) != 1 || settings[0].Value != "off" || XdebugSummary(store.Xdebug{}) != "Off" {
+		t.Fatalf("expected only the saved override once debugging is off, got %+v", settings)
+	}
+}
+
+func TestSetXdebugRequiresExtension(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell script as the FrankenPHP binary")
+	}
+	t.Setenv("FRAGO_PHP_INI_DIR", t.TempDir())
+	binary := filepath.Join(t.TempDir(), "frankenphp")
+	t.Setenv("FRANKENPHP_BINARY", binary)
+	dir := t.TempDir()
+	projects := store.New(&memoryBackend{})
+	svc := New(runner.NewManager(), projects, nil)
+	if _, err := projects.Create(store.Project{Path: dir}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	// Without the extension PHP does not know xdebug.mode.
+	if err := os.WriteFile(binary, []byte("#!/bin/sh\nexit 0\n"), 0755); err != nil {
+		t.Fatalf("write binary: %v", err)
+	}
+	if _, err := svc.SetXdebug(dir, store.Xdebug{Enabled: true}); !errors.Is(err, ErrNoXdebug) {
+		t.Fatalf("expected ErrNoXdebug, got %v", err)
+	}
+	if p, _ := projects.Get(dir); p.Xdebug.Enabled {
+		t.Fatalf("expected debugging to stay off")
+	}
+	if _, err := svc.SetXdebug(dir, store.Xdebug{}); err != nil {
+		t.Fatalf("turning debugging off needs no extension: %v", err)
+	}
+
+	if err := os.WriteFile(binary, []byte("#!/bin/sh\nprintf 'xdebug.mode\\tdevelop\\n'\n"), 0755); err != nil {
+		t.Fatalf("write binary: %v", err)
+	}
+	if _, err := svc.SetXdebug(dir, store.Xdebug{Enabled: true}); err != nil {
+		t.Fatalf("expected a binary with the extension to be accepted, got %v", err)
+	}
+}
+
+func TestStartPlanOrdersDependencies(t *testing.T) {
+	projects := store.New(&memoryBackend{})
+	svc := New(runner.NewManager(), projects, nil)
+	for _, p := range []store.Project{
+		{Path: "/srv/front", DependsOn: []string{"/srv/api"}},
+		{Path: "/srv/db"},
+		{Path: "/srv/api", DependsOn: []string{"/srv/db"}},
+		{Path: "/srv/a", DependsOn: []string{"/srv/b"}},
+		{Path: "/srv/b", DependsOn: []string{"/srv/a"}},
+		{Path: "/srv/c", DependsOn: []string{"/srv/a"}},
+	} {
+		if _, err := projects.Create(p); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+	}
+
+	order, errs := svc.startPlan([]string{"/srv/front", "/srv/db", "/srv/api"})
+	if got := strings.Join(order, ","); got != "/srv/db,/srv/api,/srv/front" {
+		t.Fatalf("unexpected start order: %s", got)
+	}
+	if len(errs) != 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+
+	_, errs = svc.startPlan([]string{"/srv/c"})
+	for _, path := range []string{"/srv/a", "/srv/b", "/srv/c"} {
+		if !errors.Is(errs[path], ErrDependencyCycle) {
+			t.Fatalf("expected a cycle error for %s, got %v", path, errs[path])
+		}
+	}
+	if !strings.Contains(errs["/srv/c"].Error(), "/srv/a -> /srv/b -> /srv/a") {
+		t.Fatalf("cycle error does not name the cycle: %v", errs["/srv/c"])
+	}
+}
+
+func TestStartAllSkipsProjectsWithFailedDependencies(t *testing.T) {
+	projects := store.New(&memoryBackend{})
+	svc := New(runner.NewManager(), projects, nil)
+	for _, p := range []store.Project{
+		{Path: "/does/not/exist/front", DependsOn: []string{"/does/not/exist/api"}},
+		{Path: "/does/not/exist/api"},
+	} {
+		if _, err := projects.Create(p); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+	}
+
+	results := svc.StartAll()
+	if len(results) != 2 || results[0].Path != "/does/not/exist/api" || results[1].Path != "/does/not/exist/front" {
+		t.Fatalf("expected the dependency to be started first, got %+v", results)
+	}
+	if results[1].OK || !strings.Contains(results[1].Error, "is not ready") {
+		t.Fatalf("expected the dependent project to fail on its dependency, got %+v", results[1])
+	}
+}
+
+func TestDependenciesIncludeManifest(t *testing.T) {
+	root := t.TempDir()
+	front := filepath.Join(root, "front")
+	if err := os.Mkdir(front, 0755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(front, "frago.toml"), []byte("depends_on = [\"../api\"]\n"), 0644); err != nil {
+		t.Fatalf("write manifest: %v", err)
+	}
+	svc := New(runner.NewManager(), store.New(&memoryBackend{}), nil)
+
+	deps, err := svc.Dependencies(store.Project{Path: front, DependsOn: []string{"/srv/db"}})
+	if err != nil {
+		t.Fatalf("Dependencies returned error: %v", err)
+	}
+	if got := strings.Join(deps, ","); got != "/srv/db,"+filepath.Join(root, "api") {
+		t.Fatalf("unexpected dependencies: %s", got)
+	}
+}
+
+func TestValidateSettings(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "public"), 0755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	svc := New(runner.NewManager(), store.New(&memoryBackend{}), nil)
+
+	valid := store.Project{Path: dir, PreferredPort: 8080, Docroot: "public", HealthPath: "/up", Env: map[string]string{"APP_ENV": "dev"}, RestartPolicy: store.RestartOnFailure}
+	if err := svc.ValidateSettings(valid); err != nil {
+		t.Fatalf("expected valid settings, got %v", err)
+	}
+
+	invalid := store.Project{Path: dir, PreferredPort: 70000, HealthPath: "up", LastBinaryPath: filepath.Join(dir, "missing"), RestartPolicy: "sometimes"}
+	err := svc.ValidateSettings(invalid)
+	if err == nil {
+		t.Fatalf("expected invalid settings to be rejected")
+	}
+	for _, want := range []string{"port 70000", "health_path", "missing does not exist", "sometimes"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not mention %q", err, want)
+		}
+	}
+	if err := svc.ValidateSettings(store.Project{Path: dir, Docroot: "web"}); err == nil {
+		t.Fatalf("expected a missing docroot to be rejected")
+	}
+}
+
+func TestRestartPolicyRestartsFailedProjects(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell script as the FrankenPHP binary")
+	}
+	binary := filepath.Join(t.TempDir(), "frankenphp")
+	if err := os.WriteFile(binary, []byte("#!/bin/sh\nexit 1\n"), 0755); err != nil {
+		t.Fatalf("write binary: %v", err)
+	}
+	projects := store.New(&memoryBackend{})
+	mgr := runner.NewManager()
+	svc := New(mgr, projects, nil)
+
+	crashing, stopped := t.TempDir(), t.TempDir()
+	for _, p := range []store.Project{
+		{Path: crashing, LastBinaryPath: binary, RestartPolicy: store.RestartOnFailure},
+		{Path: stopped, LastBinaryPath: binary},
+	} {
+		if _, err := projects.Create(p); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+	}
+
+	waitExit := func(path string) {
+		t.Helper()
+		deadline := time.Now().Add(5 * time.Second)
+		for {
+			if _, ok := mgr.LastExit(path); ok {
+				if _, running := mgr.Get(path); !running {
+					return
+				}
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("%s did not exit", path)
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+
+	for _, path := range []string{crashing, stopped} {
+		if err := svc.StartSaved(path); err != nil {
+			t.Fatalf("start %s: %v", path, err)
+		}
+		waitExit(path)
+	}
+	for i := 0; i < maxRestarts+2; i++ {
+		svc.applyRestartPolicies()
+		waitExit(crashing)
+	}
+
+	if got := svc.restarts[crashing].attempts; got != maxRestarts {
+		t.Fatalf("expected %d restarts, got %d", maxRestarts, got)
+	}
+	if _, ok := svc.restarts[stopped]; ok {
+		t.Fatalf("a project without a restart policy must not be restarted")
+	}
+}
+
+func TestCrashSendsEvent(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell script as the FrankenPHP binary")
+	}
+	binary := filepath.Join(t.TempDir(), "frankenphp")
+	if err := os.WriteFile(binary, []byte("#!/bin/sh\necho 'Error: listen tcp: address already in use' >&2\nexit 1\n"), 0755); err != nil {
+		t.Fatalf("write binary: %v", err)
+	}
+	svc := New(runner.NewManager(), store.New(&memoryBackend{}), nil)
+	events := make(chan Event, 1)
+	svc.OnEvent(func(e Event) { events <- e })
+
+	dir := t.TempDir()
+	if err := svc.Start(dir, binary, "", 0); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	select {
+	case e := <-events:
+		if e.Kind != EventCrashed || e.Path != dir || e.Error == "" || !strings.Contains(e.LastLine, "address already in use") {
+			t.Fatalf("unexpected event: %+v", e)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("no crash event")
+	}
+}
+
+func TestStartStopsProcessWhenStoreFails(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell script as the FrankenPHP binary")
+	}
+	t.Setenv("FRAGO_PHP_INI_DIR", t.TempDir())
+	binary := filepath.Join(t.TempDir(), "frankenphp")
+	if err := os.WriteFile(binary, []byte("#!/bin/sh\nexec sleep 60\n"), 0755); err != nil {
+		t.Fatalf("write binary: %v", err)
+	}
+	mgr := runner.NewManager()
+	svc := New(mgr, store.New(failingBackend{}), nil)
+
+	dir := t.TempDir()
+	if err := svc.Start(dir, binary, "", 0); err == nil || !strings.Contains(err.Error(), "disk full") {
+		t.Fatalf("expected the store error, got %v", err)
+	}
+	deadline := time.Now().Add(3 * time.Second)
+	for {
+		if _, running := mgr.Get(dir); !running {
+			break
+		}
+		if time.Now().After(deadline) {
+			_ = mgr.Stop(dir)
+			t.Fatalf("process left running after the store failed")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func TestRestartAppliesSavedDocroot(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell script as the FrankenPHP binary")
+	}
+	t.Setenv("FRAGO_PHP_INI_DIR", t.TempDir())
+	binary := filepath.Join(t.TempDir(), "frankenphp")
+	if err := os.WriteFile(binary, []byte("#!/bin/sh\nexec sleep 60\n"), 0755); err != nil {
+		t.Fatalf("write binary: %v", err)
+	}
+	dir := t.TempDir()
+	// A Caddyfile Frago generated on an earlier run.
+	caddyfile := filepath.Join(dir, "Caddyfile")
+	if err := os.WriteFile(caddyfile, []byte(":8125 {\n\troot * .\n\tphp_server\n\tfile_server\n}"), 0644); err != nil {
+		t.Fatalf("write Caddyfile: %v", err)
+	}
+	projects := store.New(&memoryBackend{})
+	svc := New(runner.NewManager(), projects, nil)
+	if _, err := projects.Create(store.Project{Path: dir, LastBinaryPath: binary}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if err := svc.StartSaved(dir); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	t.Cleanup(func() { stopAndWait(svc, dir) })
+
+	// What Edit does when the docroot changes and the restart is accepted.
+	if _, err := projects.Update(dir, func(p *store.Project) { p.Docroot = "public" }); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if err := svc.Restart(dir); err != nil {
+		t.Fatalf("restart: %v", err)
+	}
+	data, err := os.ReadFile(caddyfile)
+	if err != nil || !strings.Contains(string(data), "root * public") {
+		t.Fatalf("expected the saved docroot to be served, got %v:\n%s", err, data)
+	}
+}
+
+func TestUnhealthyTransitionSendsEvent(t *testing.T) {
+	svc := New(runner.NewManager(), store.New(&memoryBackend{}), nil)
+	var events []Event
+	svc.OnEvent(func(e Event) { events = append(events, e) })
+
+	svc.setHealth("/srv/shop", false, "starting")
+	svc.setHealth("/srv/shop", true, "")
+	svc.setHealth("/srv/shop", false, "http 500")
+	svc.setHealth("/srv/shop", false, "http 500")
+	if len(events) != 1 || events[0].Kind != EventUnhealthy || events[0].Error != "http 500" {
+		t.Fatalf("expected one unhealthy event, got %+v", events)
+	}
+}
+
+func TestLastErrorLine(t *testing.T) {
+	lines := []string{
+		`{"level":"error","msg":"handler failed","error":"database is locked"}`,
+		`{"level":"info","msg":"serving"}`,
+		"",
+	}
+	if got := lastErrorLine(lines); got != "handler failed: database is locked" {
+		t.Fatalf("unexpected error line: %q", got)
+	}
+	if got := lastErrorLine([]string{"starting", "ready", ""}); got != "ready" {
+		t.Fatalf("expected the last line, got %q", got)
+	}
+}
+
+func TestRescanVersionsNotifiesOnChange(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell script as the FrankenPHP binary")
+	}
+	bin := t.TempDir()
+	t.Setenv("PATH", bin)
+	t.Setenv("FRANKENPHP_BINARY", filepath.Join(bin, "missing"))
+	t.Setenv("FRAGO_BINARIES_DIR", t.TempDir())
+
+	svc := New(runner.NewManager(), store.New(&memoryBackend{}), nil)
+	svc.detector = &runner.Detector{}
+	var notified [][]runner.PHPVersion
+	svc.OnVersions(func(v []runner.PHPVersion) { notified = append(notified, v) })
+
+	if err := os.WriteFile(filepath.Join(bin, "frankenphp"), []byte("#!/bin/sh\necho 'FrankenPHP v1.9.0 PHP 8.4.1 Caddy v2.10.0'\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	var found []string
+	versions := svc.RescanVersions(func(v runner.PHPVersion) { found = append(found, v.Path) })
+	if len(versions) != 1 || len(found) != 1 || len(svc.Versions()) != 1 {
+		t.Fatalf("unexpected scan %+v (found %v)", versions, found)
+	}
+	if path, _ := svc.ResolveStartOptions(store.Project{LastVersionLabel: "frankenphp (PHP 8.4.1)"}); path != versions[0].Path {
+		t.Fatalf("rescanned binary not resolvable, got %q", path)
+	}
+
+	svc.RescanVersions(nil)
+	if len(notified) != 1 {
+		t.Fatalf("expected one notification for one change, got %d", len(notified))
+	}
+}
+
+func TestSidecarsRunWithProject(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell script as the FrankenPHP binary")
+	}
+	t.Setenv("FRAGO_PHP_INI_DIR", t.TempDir())
+	binary := filepath.Join(t.TempDir(), "frankenphp")
+	script := "#!/bin/sh\nif [ \"$1\" = php-cli ]; then shift; echo \"php $*\"; fi\nexec sleep 60\n"
+	if err := os.WriteFile(binary, []byte(script), 0755); err != nil {
+		t.Fatalf("write binary: %v", err)
+	}
+	dir := t.TempDir()
+	manifest := `
+[env]
+APP_ENV = "dev"
+
+[sidecars.queue]
+command = ["php", "artisan", "queue:work"]
+
+[sidecars.echo]
+command = ["sh", "-c", "echo $APP_ENV $ROLE; exit 2"]
+env = { ROLE = "echo" }
+restart = "on-failure"
+
+[sidecars.manual]
+command = ["sleep", "60"]
+auto_start = false
+`
+	if err := os.WriteFile(filepath.Join(dir, "frago.toml"), []byte(manifest), 0644); err != nil {
+		t.Fatalf("write manifest: %v", err)
+	}
+	mgr := runner.NewManager()
+	svc := New(mgr, store.New(&memoryBackend{}), nil)
+	events := make(chan Event, 4)
+	svc.OnEvent(func(e Event) { events <- e })
+
+	if err := svc.Start(dir, binary, "", 0); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	t.Cleanup(func() { stopAndWait(svc, dir) })
+	select {
+	case e := <-events:
+		if e.Kind != EventSidecarFailed || e.Sidecar != "echo" || e.LastLine != "dev echo" {
+			t.Fatalf("unexpected event: %+v", e)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("no sidecar event")
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for mgr.TailSidecarLogs(dir, "queue", 10) != "php artisan queue:work" {
+		if time.Now().After(deadline) {
+			t.Fatalf("queue did not run with php-cli: %q", mgr.TailSidecarLogs(dir, "queue", 10))
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	sidecars, err := svc.Sidecars(dir)
+	if err != nil {
+		t.Fatalf("sidecars: %v", err)
+	}
+	got := make(map[string]SidecarStatus)
+	for _, sc := range sidecars {
+		got[sc.Name] = sc
+	}
+	if len(sidecars) != 3 || !got["queue"].Running || got["manual"].Running || got["manual"].AutoStart ||
+		got["echo"].LastExit == nil || !got["echo"].LastExit.Failed {
+		t.Fatalf("unexpected sidecars %+v", sidecars)
+	}
+
+	if err := svc.StartSidecar(dir, "missing"); !errors.Is(err, store.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+	if err := svc.StartSidecar(dir, "manual"); err != nil {
+		t.Fatalf("start manual: %v", err)
+	}
+	svc.applyRestartPolicies()
+	if got := svc.restarts[sidecarID(dir, "echo")].attempts; got != 1 {
+		t.Fatalf("expected the failed sidecar to be restarted once, got %d", got)
+	}
+
+	if err := svc.Stop(dir); err != nil {
+		t.Fatalf("stop: %v", err)
+	}
+	if err := svc.waitSidecarsStopped(dir, sidecarStopTimeout); err != nil {
+		t.Fatalf("sidecars still running: %v", mgr.Sidecars(dir))
+	}
+}
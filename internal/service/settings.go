@@ -0,0 +1,136 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/devmarvs/frago/internal/manifest"
+	"github.com/devmarvs/frago/internal/store"
+)
+
+const (
+	// maxRestarts is how many times in a row a project is restarted by its
+	// restart policy before Frago gives up on it.
+	maxRestarts = 5
+	// restartResetAfter is how long a project must stay up for its restart
+	// count to start over.
+	restartResetAfter = time.Minute
+)
+
+type restartState struct {
+	attempts int
+	last     time.Time
+}
+
+// ValidateSettings checks the settings saved for a project: the preferred
+// port, binary, PHP constraint, php.ini settings, docroot, health path, env
+// vars and restart policy.
+func (s *Service) ValidateSettings(info store.Project) error {
+	var errs []error
+	if err := ValidateProject(info); err != nil {
+		errs = append(errs, err)
+	} else if info.Docroot != "" {
+		if fi, err := os.Stat(filepath.Join(info.Path, info.Docroot)); err != nil || !fi.IsDir() {
+			errs = append(errs, fmt.Errorf("docroot %q is not a directory in the project", info.Docroot))
+		}
+	}
+	if info.LastBinaryPath != "" {
+		if fi, err := os.Stat(info.LastBinaryPath); err != nil || fi.IsDir() {
+			errs = append(errs, fmt.Errorf("binary %s does not exist", info.LastBinaryPath))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ValidateProject checks the settings of a project that do not depend on
+// the files on this machine, such as those of an imported project: the
+// preferred port, PHP constraint, php.ini settings, docroot, health path,
+// env vars, Xdebug client and restart policy.
+func ValidateProject(info store.Project) error {
+	m := manifest.Manifest{
+		Port:         info.PreferredPort,
+		Docroot:      info.Docroot,
+		HealthPath:   info.HealthPath,
+		Env:          info.Env,
+		PHP:          info.PHP,
+		PHPIniPreset: info.PHPIniPreset,
+		PHPIni:       info.PHPIni,
+	}
+	var errs []error
+	if err := m.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+	if err := ValidateXdebug(info.Xdebug); err != nil {
+		errs = append(errs, err)
+	}
+	if !slices.Contains(store.RestartPolicies, info.RestartPolicy) {
+		errs = append(errs, fmt.Errorf("restart policy %q is invalid; use %q or %q", info.RestartPolicy, store.RestartOnFailure, store.RestartAlways))
+	}
+	return errors.Join(errs...)
+}
+
+// applySettings lays the settings saved for a project over its manifest.
+func applySettings(m *manifest.Manifest, saved store.Project) {
+	if saved.Docroot != "" {
+		m.Docroot = saved.Docroot
+	}
+	if saved.HealthPath != "" {
+		m.HealthPath = saved.HealthPath
+	}
+	if len(saved.Env) > 0 {
+		if m.Env == nil {
+			m.Env = make(map[string]string, len(saved.Env))
+		}
+		for key, value := range saved.Env {
+			m.Env[key] = value
+		}
+	}
+}
+
+// applyRestartPolicies starts saved projects and sidecars again whose
+// process exited without a Stop, as their restart policy asks. A project
+// that keeps exiting is given up on after maxRestarts attempts.
+func (s *Service) applyRestartPolicies() {
+	for _, info := range s.projects.List() {
+		if info.RestartPolicy == store.RestartNever {
+			continue
+		}
+		if _, running := s.mgr.Get(info.Path); running {
+			continue
+		}
+		exit, ok := s.mgr.LastExit(info.Path)
+		if !ok || exit.Requested || (info.RestartPolicy == store.RestartOnFailure && !exit.Failed) {
+			continue
+		}
+		if !s.allowRestart(info.Path, exit.When) {
+			continue
+		}
+		if err := s.StartSaved(info.Path); err != nil {
+			log.Printf("Restart %s failed: %v", info.Path, err)
+		}
+	}
+	s.applySidecarRestartPolicies()
+}
+
+// allowRestart counts a restart attempt for path, which exited at exitedAt,
+// and reports whether it may go ahead.
+func (s *Service) allowRestart(path string, exitedAt time.Time) bool {
+	s.monitorMu.Lock()
+	defer s.monitorMu.Unlock()
+	state := s.restarts[path]
+	if exitedAt.Sub(state.last) > restartResetAfter {
+		state.attempts = 0
+	}
+	if state.attempts >= maxRestarts {
+		return false
+	}
+	state.attempts++
+	state.last = time.Now()
+	s.restarts[path] = state
+	return true
+}
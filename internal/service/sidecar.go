@@ -0,0 +1,255 @@
+package service
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/devmarvs/frago/internal/manifest"
+	"github.com/devmarvs/frago/internal/runner"
+	"github.com/devmarvs/frago/internal/store"
+)
+
+// sidecarStopTimeout is how long Restart waits for the sidecars of a project
+// to exit, which may take until they are killed.
+const sidecarStopTimeout = runner.SidecarStopGrace + time.Second
+
+// SidecarStatus describes a sidecar declared in the manifest of a project,
+// or one still running after it was removed from the manifest.
+type SidecarStatus struct {
+	Name string
+	// Command is the command line from the manifest.
+	Command   []string
+	Restart   string
+	AutoStart bool
+
+	Running   bool
+	PID       int
+	StartedAt time.Time
+	// Stats is the last sample taken while the sidecar runs.
+	Stats *Stats
+	// LastExit is how the sidecar last exited, when it is not running.
+	LastExit *runner.ExitInfo
+}
+
+// sidecarPolicy is the restart policy a sidecar was last started with.
+type sidecarPolicy struct {
+	path    string
+	name    string
+	restart string
+}
+
+// sidecarID keys the stats and restart state of a sidecar alongside those of
+// projects.
+func sidecarID(path, name string) string {
+	return path + "\x00" + name
+}
+
+// Sidecars returns the sidecars of the project in path, sorted by name.
+func (s *Service) Sidecars(path string) ([]SidecarStatus, error) {
+	m, err := manifest.Load(path)
+	if err != nil {
+		return nil, fmt.Errorf("manifest error: %w", err)
+	}
+	list := make([]SidecarStatus, 0, len(m.Sidecars))
+	for _, name := range m.SidecarNames() {
+		list = append(list, s.sidecarStatus(path, name, m.Sidecars[name]))
+	}
+	for _, proc := range s.mgr.Sidecars(path) {
+		if _, declared := m.Sidecars[proc.Name]; !declared {
+			manual := false
+			list = append(list, s.sidecarStatus(path, proc.Name, manifest.Sidecar{Command: proc.Args, AutoStart: &manual}))
+		}
+	}
+	return list, nil
+}
+
+func (s *Service) sidecarStatus(path, name string, sc manifest.Sidecar) SidecarStatus {
+	status := SidecarStatus{
+		Name:      name,
+		Command:   sc.Command,
+		Restart:   sc.Restart,
+		AutoStart: sc.StartsWithProject(),
+	}
+	if proc, ok := s.mgr.GetSidecar(path, name); ok {
+		status.Running = true
+		status.PID = proc.PID()
+		status.StartedAt = proc.StartedAt
+		if stats, ok := s.Stats(sidecarID(path, name)); ok {
+			status.Stats = &stats
+		}
+	} else if exit, ok := s.mgr.LastSidecarExit(path, name); ok {
+		status.LastExit = &exit
+	}
+	return status
+}
+
+// StartSidecar starts the sidecar name declared in the manifest of the
+// project in path. PHP commands use the binary the project runs with, or
+// would start with when it is stopped.
+func (s *Service) StartSidecar(path, name string) error {
+	m, err := manifest.Load(path)
+	if err != nil {
+		return fmt.Errorf("manifest error: %w", err)
+	}
+	sc, ok := m.Sidecars[name]
+	if !ok {
+		return fmt.Errorf("%w: sidecar %s", store.ErrNotFound, name)
+	}
+	saved, ok := s.projects.Get(path)
+	if ok {
+		applySettings(&m, saved)
+	} else {
+		saved.Path = path
+	}
+	binary := ""
+	if proc, running := s.mgr.Get(path); running {
+		binary = proc.BinaryPath
+	} else {
+		binary = s.StartBinary(saved)
+	}
+	return s.startSidecar(path, name, sc, m, saved, binary)
+}
+
+func (s *Service) startSidecar(path, name string, sc manifest.Sidecar, m manifest.Manifest, saved store.Project, binary string) error {
+	env, err := projectEnv(path, m, saved)
+	if err != nil {
+		return err
+	}
+	env = append(env, manifest.Manifest{Env: sc.Env}.Environ()...)
+	if err := s.mgr.StartSidecar(path, name, sidecarArgs(binary, sc.Command), env); err != nil {
+		return fmt.Errorf("sidecar %s: %w", name, err)
+	}
+	s.monitorMu.Lock()
+	s.sidecars[sidecarID(path, name)] = sidecarPolicy{path, name, sc.Restart}
+	s.monitorMu.Unlock()
+	return nil
+}
+
+// startSidecars starts the sidecars of a project that start with it and are
+// not running yet. Failures are reported with EventSidecarFailed rather than
+// failing the start of the project.
+func (s *Service) startSidecars(path string, m manifest.Manifest, saved store.Project, binary string) {
+	for _, name := range m.SidecarNames() {
+		sc := m.Sidecars[name]
+		if !sc.StartsWithProject() {
+			continue
+		}
+		if _, running := s.mgr.GetSidecar(path, name); running {
+			continue
+		}
+		if err := s.startSidecar(path, name, sc, m, saved, binary); err != nil {
+			s.emitEvent(Event{Kind: EventSidecarFailed, Path: path, Sidecar: name, Error: err.Error()})
+		}
+	}
+}
+
+// sidecarArgs returns the command line of a sidecar. A command starting with
+// "php" runs as "php-cli" of binary, or of the default FrankenPHP binary when
+// binary is empty.
+func sidecarArgs(binary string, command []string) []string {
+	if command[0] != "php" {
+		return command
+	}
+	if binary == "" {
+		binary = runner.DefaultFrankenPHPBinary()
+	}
+	return append([]string{binary, "php-cli"}, command[1:]...)
+}
+
+// StopSidecar asks a running sidecar of the project in path to exit.
+func (s *Service) StopSidecar(path, name string) error {
+	return s.mgr.StopSidecar(path, name)
+}
+
+// RestartSidecar stops the sidecar if it is running, waits for it to exit,
+// and starts it again.
+func (s *Service) RestartSidecar(path, name string) error {
+	if _, running := s.mgr.GetSidecar(path, name); running {
+		if err := s.mgr.StopSidecar(path, name); err != nil {
+			return err
+		}
+		deadline := time.Now().Add(sidecarStopTimeout)
+		for {
+			if _, running := s.mgr.GetSidecar(path, name); !running {
+				break
+			}
+			if time.Now().After(deadline) {
+				return fmt.Errorf("timeout waiting for sidecar %s to stop", name)
+			}
+			time.Sleep(100 * time.Millisecond)
+		}
+	}
+	return s.StartSidecar(path, name)
+}
+
+// StopSidecars asks every running sidecar of the project in path to exit.
+func (s *Service) StopSidecars(path string) {
+	for _, proc := range s.mgr.Sidecars(path) {
+		if err := s.mgr.StopSidecar(path, proc.Name); err != nil {
+			log.Printf("Stop sidecar %s of %s failed: %v", proc.Name, path, err)
+		}
+	}
+}
+
+func (s *Service) waitSidecarsStopped(path string, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for len(s.mgr.Sidecars(path)) > 0 {
+		if time.Now().After(deadline) {
+			return fmt.Errorf("timeout waiting for the sidecars of %s to stop", path)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	return nil
+}
+
+// forgetSidecars drops the restart policies and stats of the sidecars of the
+// project in path.
+func (s *Service) forgetSidecars(path string) {
+	s.monitorMu.Lock()
+	defer s.monitorMu.Unlock()
+	for id, p := range s.sidecars {
+		if p.path == path {
+			delete(s.sidecars, id)
+			delete(s.stats, id)
+			delete(s.restarts, id)
+		}
+	}
+}
+
+// handleSidecarExit reports sidecars that crashed.
+func (s *Service) handleSidecarExit(path, name string, info runner.ExitInfo) {
+	if info.Failed {
+		s.emitEvent(Event{Kind: EventSidecarFailed, Path: path, Sidecar: name, Error: info.Err})
+	}
+}
+
+// applySidecarRestartPolicies starts sidecars again that exited without a
+// Stop, as their restart policy asks, with the same limit as projects.
+func (s *Service) applySidecarRestartPolicies() {
+	s.monitorMu.Lock()
+	policies := make([]sidecarPolicy, 0, len(s.sidecars))
+	for _, p := range s.sidecars {
+		policies = append(policies, p)
+	}
+	s.monitorMu.Unlock()
+
+	for _, p := range policies {
+		if p.restart == store.RestartNever {
+			continue
+		}
+		if _, running := s.mgr.GetSidecar(p.path, p.name); running {
+			continue
+		}
+		exit, ok := s.mgr.LastSidecarExit(p.path, p.name)
+		if !ok || exit.Requested || (p.restart == store.RestartOnFailure && !exit.Failed) {
+			continue
+		}
+		if !s.allowRestart(sidecarID(p.path, p.name), exit.When) {
+			continue
+		}
+		if err := s.StartSidecar(p.path, p.name); err != nil {
+			log.Printf("Restart sidecar %s of %s failed: %v", p.name, p.path, err)
+		}
+	}
+}
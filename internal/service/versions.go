@@ -0,0 +1,49 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/devmarvs/frago/internal/runner"
+)
+
+// RescanVersions detects the FrankenPHP binaries again and makes the results
+// the known versions. Binaries unchanged since the last scan are not run.
+// found, when set, is called with each binary as soon as it is identified,
+// before the scan finishes.
+func (s *Service) RescanVersions(found func(runner.PHPVersion)) []runner.PHPVersion {
+	versions := s.detector.Scan(found)
+	s.SetVersions(versions)
+	return versions
+}
+
+// OnVersions registers fn to be called with the detected binaries whenever
+// they change. fn runs on the goroutine that set them. The returned function
+// unregisters it.
+func (s *Service) OnVersions(fn func([]runner.PHPVersion)) func() {
+	s.eventMu.Lock()
+	defer s.eventMu.Unlock()
+	id := s.nextHandler
+	s.nextHandler++
+	s.versionHandlers[id] = fn
+	return func() {
+		s.eventMu.Lock()
+		defer s.eventMu.Unlock()
+		delete(s.versionHandlers, id)
+	}
+}
+
+// RunVersionScans rescans the FrankenPHP binaries every interval until ctx
+// is done, so binaries installed or upgraded meanwhile are picked up.
+func (s *Service) RunVersionScans(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.RescanVersions(nil)
+		}
+	}
+}
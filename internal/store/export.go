@@ -0,0 +1,335 @@
+package store
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+)
+
+// ExportFormat identifies Frago export files.
+const ExportFormat = "frago-projects"
+
+// ExportVersion is the version of the export file layout.
+const ExportVersion = 1
+
+// Export is a portable copy of the saved projects and workspaces. Unlike the
+// project file it leaves out per-machine history such as the last port, URL
+// and use time.
+type Export struct {
+	Format     string    `json:"format" toml:"format"`
+	Version    int       `json:"version" toml:"version"`
+	ExportedAt time.Time `json:"exported_at" toml:"exported_at"`
+	// Home is the exporting user's home directory, used to remap paths when
+	// importing under a different one.
+	Home       string              `json:"home,omitempty" toml:"home,omitempty"`
+	Projects   []ExportedProject   `json:"projects" toml:"projects"`
+	Workspaces []ExportedWorkspace `json:"workspaces,omitempty" toml:"workspaces,omitempty"`
+}
+
+// ExportedProject holds the settings of one exported project.
+type ExportedProject struct {
+	Path          string            `json:"path" toml:"path"`
+	PreferredPort int               `json:"preferred_port,omitempty" toml:"preferred_port,omitempty"`
+	VersionLabel  string            `json:"version_label,omitempty" toml:"version_label,omitempty"`
+	BinaryPath    string            `json:"binary_path,omitempty" toml:"binary_path,omitempty"`
+	Pinned        bool              `json:"pinned,omitempty" toml:"pinned,omitempty"`
+	AutoStart     bool              `json:"auto_start,omitempty" toml:"auto_start,omitempty"`
+	DependsOn     []string          `json:"depends_on,omitempty" toml:"depends_on,omitempty"`
+	Env           map[string]string `json:"env,omitempty" toml:"env,omitempty"`
+	Name          string            `json:"name,omitempty" toml:"name,omitempty"`
+	Tags          []string          `json:"tags,omitempty" toml:"tags,omitempty"`
+	Docroot       string            `json:"docroot,omitempty" toml:"docroot,omitempty"`
+	HealthPath    string            `json:"health_path,omitempty" toml:"health_path,omitempty"`
+	RestartPolicy string            `json:"restart_policy,omitempty" toml:"restart_policy,omitempty"`
+	Muted         bool              `json:"muted,omitempty" toml:"muted,omitempty"`
+	PHP           string            `json:"php,omitempty" toml:"php,omitempty"`
+	PHPIniPreset  string            `json:"php_ini_preset,omitempty" toml:"php_ini_preset,omitempty"`
+	PHPIni        map[string]string `json:"php_ini,omitempty" toml:"php_ini,omitempty"`
+	Xdebug        Xdebug            `json:"xdebug,omitzero" toml:"xdebug,omitempty"`
+}
+
+// ExportedWorkspace is an exported workspace; projects are paths in start
+// order.
+type ExportedWorkspace struct {
+	Name     string   `json:"name" toml:"name"`
+	Projects []string `json:"projects" toml:"projects"`
+}
+
+// ImportSummary reports what Import changed.
+type ImportSummary struct {
+	Added             int
+	Updated           int
+	Workspaces        int
+	DroppedBinaryPath []string
+	// Skipped lists the projects left out as "path: reason".
+	Skipped []string
+}
+
+// Export returns the saved projects and workspaces for transfer to another
+// machine.
+func (s *Store) Export() Export {
+	home, _ := os.UserHomeDir()
+	e := Export{
+		Format:     ExportFormat,
+		Version:    ExportVersion,
+		ExportedAt: time.Now().UTC().Truncate(time.Second),
+		Home:       home,
+		Projects:   []ExportedProject{},
+	}
+	for _, p := range s.List() {
+		e.Projects = append(e.Projects, ExportedProject{
+			Path:          p.Path,
+			PreferredPort: p.PreferredPort,
+			VersionLabel:  p.LastVersionLabel,
+			BinaryPath:    p.LastBinaryPath,
+			Pinned:        p.Pinned,
+			AutoStart:     p.AutoStart,
+			DependsOn:     p.DependsOn,
+			Env:           p.Env,
+			Name:          p.Name,
+			Tags:          p.Tags,
+			Docroot:       p.Docroot,
+			HealthPath:    p.HealthPath,
+			RestartPolicy: p.RestartPolicy,
+			Muted:         p.Muted,
+			PHP:           p.PHP,
+			PHPIniPreset:  p.PHPIniPreset,
+			PHPIni:        p.PHPIni,
+			Xdebug:        p.Xdebug,
+		})
+	}
+	for _, w := range s.Workspaces() {
+		e.Workspaces = append(e.Workspaces, ExportedWorkspace{Name: w.Name, Projects: w.Projects})
+	}
+	return e
+}
+
+// Import adds the exported projects and workspaces. Projects already saved
+// for the same path and workspaces with the same name are overwritten; other
+// saved projects are kept. Binary paths that do not exist on this machine are
+// dropped so the remembered version label picks a detected binary instead.
+//
+// An export file is untrusted input: projects with relative paths, or whose
+// settings validate rejects, are skipped and listed in the summary.
+func (s *Store) Import(e Export, validate func(Project) error) (ImportSummary, error) {
+	var summary ImportSummary
+	var accepted []ExportedProject
+	for _, ep := range e.Projects {
+		if ep.Path == "" {
+			continue
+		}
+		if err := checkImported(ep, validate); err != nil {
+			summary.Skipped = append(summary.Skipped, fmt.Sprintf("%s: %v", ep.Path, err))
+			continue
+		}
+		accepted = append(accepted, ep)
+	}
+
+	err := s.mutate(func() (bool, error) {
+		for _, ep := range accepted {
+			if _, exists := s.projects[ep.Path]; exists {
+				summary.Updated++
+			} else {
+				summary.Added++
+			}
+			p := s.ensureLocked(ep.Path)
+			p.PreferredPort = ep.PreferredPort
+			p.LastVersionLabel = ep.VersionLabel
+			p.LastBinaryPath = ep.BinaryPath
+			if ep.BinaryPath != "" {
+				if _, err := os.Stat(ep.BinaryPath); err != nil {
+					p.LastBinaryPath = ""
+					summary.DroppedBinaryPath = append(summary.DroppedBinaryPath, ep.BinaryPath)
+				}
+			}
+			p.Pinned = ep.Pinned
+			p.AutoStart = ep.AutoStart
+			p.DependsOn = append([]string(nil), ep.DependsOn...)
+			p.Env = copyEnv(ep.Env)
+			p.Name = ep.Name
+			p.Tags = NormalizeTags(ep.Tags)
+			p.Docroot = ep.Docroot
+			p.HealthPath = ep.HealthPath
+			p.RestartPolicy = ep.RestartPolicy
+			p.Muted = ep.Muted
+			p.PHP = ep.PHP
+			p.PHPIniPreset = ep.PHPIniPreset
+			p.PHPIni = copyEnv(ep.PHPIni)
+			p.Xdebug = ep.Xdebug
+		}
+
+		for _, ew := range e.Workspaces {
+			w := Workspace{Name: strings.TrimSpace(ew.Name)}
+			for _, path := range ew.Projects {
+				if _, ok := s.projects[path]; ok && !w.Contains(path) {
+					w.Projects = append(w.Projects, path)
+				}
+			}
+			i := -1
+			for j, existing := range s.workspaces {
+				if strings.EqualFold(existing.Name, w.Name) {
+					i = j
+					break
+				}
+			}
+			if i >= 0 {
+				w.ID = s.workspaces[i].ID
+			} else {
+				id, err := newWorkspaceID()
+				if err != nil {
+					return false, err
+				}
+				w.ID = id
+			}
+			if err := s.validateWorkspaceLocked(w); err != nil {
+				return false, err
+			}
+			if i >= 0 {
+				s.workspaces[i] = w
+			} else {
+				s.workspaces = append(s.workspaces, w)
+			}
+			summary.Workspaces++
+		}
+		return true, nil
+	})
+	if err != nil {
+		return ImportSummary{}, err
+	}
+	return summary, nil
+}
+
+// checkImported rejects an exported project with relative paths or settings
+// validate does not accept.
+func checkImported(ep ExportedProject, validate func(Project) error) error {
+	if !filepath.IsAbs(ep.Path) {
+		return fmt.Errorf("path must be absolute")
+	}
+	for _, dep := range ep.DependsOn {
+		if !filepath.IsAbs(dep) {
+			return fmt.Errorf("depends_on %q must be an absolute path", dep)
+		}
+	}
+	if validate == nil {
+		return nil
+	}
+	return validate(Project{
+		Path:             ep.Path,
+		PreferredPort:    ep.PreferredPort,
+		LastVersionLabel: ep.VersionLabel,
+		LastBinaryPath:   ep.BinaryPath,
+		DependsOn:        ep.DependsOn,
+		Env:              ep.Env,
+		Name:             ep.Name,
+		Tags:             ep.Tags,
+		Docroot:          ep.Docroot,
+		HealthPath:       ep.HealthPath,
+		RestartPolicy:    ep.RestartPolicy,
+		PHP:              ep.PHP,
+		PHPIniPreset:     ep.PHPIniPreset,
+		PHPIni:           ep.PHPIni,
+		Xdebug:           ep.Xdebug,
+	})
+}
+
+// Remap rewrites every path in e that is from or below from so it starts
+// with to instead.
+func (e Export) Remap(from, to string) Export {
+	if from == "" || from == to {
+		return e
+	}
+	remap := func(path string) string {
+		rest, ok := strings.CutPrefix(path, from)
+		if !ok || (rest != "" && !strings.HasPrefix(rest, "/") && !strings.HasPrefix(rest, `\`)) {
+			return path
+		}
+		return filepath.Clean(to + filepath.FromSlash(strings.ReplaceAll(rest, `\`, "/")))
+	}
+	remapAll := func(paths []string) []string {
+		out := make([]string, 0, len(paths))
+		for _, p := range paths {
+			out = append(out, remap(p))
+		}
+		return out
+	}
+
+	out := e
+	out.Projects = make([]ExportedProject, 0, len(e.Projects))
+	for _, p := range e.Projects {
+		p.Path = remap(p.Path)
+		if p.BinaryPath != "" {
+			p.BinaryPath = remap(p.BinaryPath)
+		}
+		if p.DependsOn != nil {
+			p.DependsOn = remapAll(p.DependsOn)
+		}
+		out.Projects = append(out.Projects, p)
+	}
+	out.Workspaces = make([]ExportedWorkspace, 0, len(e.Workspaces))
+	for _, w := range e.Workspaces {
+		w.Projects = remapAll(w.Projects)
+		out.Workspaces = append(out.Workspaces, w)
+	}
+	if out.Home != "" {
+		out.Home = remap(out.Home)
+	}
+	return out
+}
+
+// ExportFileFormat returns "toml" for .toml files and "json" otherwise.
+func ExportFileFormat(path string) string {
+	if strings.EqualFold(filepath.Ext(path), ".toml") {
+		return "toml"
+	}
+	return "json"
+}
+
+// EncodeExport serializes e as "json" or "toml".
+func EncodeExport(e Export, format string) ([]byte, error) {
+	switch format {
+	case "json":
+		data, err := json.MarshalIndent(e, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return append(data, '\n'), nil
+	case "toml":
+		var buf bytes.Buffer
+		if err := toml.NewEncoder(&buf).Encode(e); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	default:
+		return nil, fmt.Errorf("unknown export format %q; use json or toml", format)
+	}
+}
+
+// DecodeExport parses an export written as "json" or "toml".
+func DecodeExport(data []byte, format string) (Export, error) {
+	var e Export
+	switch format {
+	case "json":
+		if err := json.Unmarshal(data, &e); err != nil {
+			return Export{}, fmt.Errorf("parse export: %w", err)
+		}
+	case "toml":
+		if _, err := toml.Decode(string(data), &e); err != nil {
+			return Export{}, fmt.Errorf("parse export: %w", err)
+		}
+	default:
+		return Export{}, fmt.Errorf("unknown export format %q; use json or toml", format)
+	}
+	if e.Format != ExportFormat {
+		return Export{}, fmt.Errorf("not a Frago project export")
+	}
+	if e.Version > ExportVersion {
+		return Export{}, fmt.Errorf("%w (export version %d)", ErrUnsupportedVersion, e.Version)
+	}
+	return e, nil
+}
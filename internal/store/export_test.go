@@ -0,0 +1,108 @@
+package store
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestExportRoundTrip(t *testing.T) {
+	s := New(&memoryBackend{})
+	if _, err := s.Create(Project{Path: "/home/alice/src/api", PreferredPort: 8100, AutoStart: true, Env: map[string]string{"APP_ENV": "dev"}}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if _, err := s.Create(Project{Path: "/home/alice/src/shop", Pinned: true, DependsOn: []string{"/home/alice/src/api"}}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if _, err := s.CreateWorkspace("Shop", []string{"/home/alice/src/api", "/home/alice/src/shop"}); err != nil {
+		t.Fatalf("create workspace: %v", err)
+	}
+
+	exported := s.Export()
+	for _, format := range []string{"json", "toml"} {
+		data, err := EncodeExport(exported, format)
+		if err != nil {
+			t.Fatalf("%s: encode: %v", format, err)
+		}
+		decoded, err := DecodeExport(data, format)
+		if err != nil {
+			t.Fatalf("%s: decode: %v\n%s", format, err, data)
+		}
+		if !reflect.DeepEqual(decoded.Projects, exported.Projects) || !reflect.DeepEqual(decoded.Workspaces, exported.Workspaces) {
+			t.Fatalf("%s: round trip changed the export:\n%+v\n%+v", format, decoded, exported)
+		}
+	}
+
+	if _, err := DecodeExport([]byte(`{"projects":[]}`), "json"); err == nil {
+		t.Fatalf("expected a file without the export marker to be rejected")
+	}
+}
+
+func TestImportRemapsAndMerges(t *testing.T) {
+	root := t.TempDir()
+	shared := filepath.Join(root, "srv", "shared")
+	e := Export{
+		Format:  ExportFormat,
+		Version: ExportVersion,
+		Home:    "/home/alice",
+		Projects: []ExportedProject{
+			{Path: "/home/alice/src/api", PreferredPort: 8100, BinaryPath: "/home/alice/bin/frankenphp", VersionLabel: "fp (PHP 8.3.1)"},
+			{Path: "/home/alice/src/shop", DependsOn: []string{"/home/alice/src/api"}, Env: map[string]string{"A": "1"}},
+			{Path: shared},
+			{Path: "src/relative"},
+			{Path: "/home/alice/src/bad", DependsOn: []string{"../api"}},
+			{Path: "/home/alice/src/sometimes", RestartPolicy: "sometimes"},
+		},
+		Workspaces: []ExportedWorkspace{{Name: "Shop", Projects: []string{"/home/alice/src/api", "/home/alice/src/shop"}}},
+	}
+	home := filepath.Join(root, "Users", "bob")
+	remapped := e.Remap(e.Home, home)
+	api := filepath.Join(home, "src", "api")
+	shop := filepath.Join(home, "src", "shop")
+
+	s := New(&memoryBackend{})
+	if _, err := s.Create(Project{Path: shop, PreferredPort: 9000}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	validate := func(p Project) error {
+		if p.RestartPolicy != RestartNever {
+			return errors.New("invalid restart policy")
+		}
+		return nil
+	}
+	summary, err := s.Import(remapped, validate)
+	if err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	if summary.Added != 2 || summary.Updated != 1 || summary.Workspaces != 1 || len(summary.DroppedBinaryPath) != 1 || len(summary.Skipped) != 3 {
+		t.Fatalf("unexpected summary: %+v", summary)
+	}
+	if _, ok := s.Get(filepath.Join(home, "src", "sometimes")); ok {
+		t.Fatalf("a project the validator rejects must not be imported")
+	}
+
+	got, ok := s.Get(api)
+	if !ok || got.PreferredPort != 8100 || got.LastBinaryPath != "" || got.LastVersionLabel != "fp (PHP 8.3.1)" {
+		t.Fatalf("unexpected imported project: %+v", got)
+	}
+	got, _ = s.Get(shop)
+	if got.PreferredPort != 0 || !reflect.DeepEqual(got.DependsOn, []string{api}) || got.Env["A"] != "1" {
+		t.Fatalf("unexpected updated project: %+v", got)
+	}
+	if _, ok := s.Get(shared); !ok {
+		t.Fatalf("paths outside the home directory must be kept")
+	}
+	workspaces := s.Workspaces()
+	if len(workspaces) != 1 || !reflect.DeepEqual(workspaces[0].Projects, []string{api, shop}) {
+		t.Fatalf("unexpected workspaces: %+v", workspaces)
+	}
+
+	// Importing again updates the workspace instead of adding a second one.
+	if _, err := s.Import(remapped, validate); err != nil {
+		t.Fatalf("second import: %v", err)
+	}
+	if len(s.Workspaces()) != 1 {
+		t.Fatalf("expected the workspace to be merged by name")
+	}
+}
@@ -0,0 +1,68 @@
+package store
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+// FileBackend persists the project list to a JSON file. It implements Locker.
+type FileBackend struct {
+	Path string
+}
+
+// DefaultPath returns the location of the project file in the user's
+// configuration directory.
+func DefaultPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "frago", "projects.json"), nil
+}
+
+// Load returns the file contents, or nothing if the file does not exist yet.
+func (b FileBackend) Load() ([]byte, error) {
+	data, err := os.ReadFile(b.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	return data, err
+}
+
+// Lock takes an exclusive lock on a sibling ".lock" file so the GUI, the
+// daemon and the CLI do not interleave read-modify-write cycles.
+func (b FileBackend) Lock() (func() error, error) {
+	if err := os.MkdirAll(filepath.Dir(b.Path), 0o700); err != nil {
+		return nil, err
+	}
+	return lockFile(b.Path + ".lock")
+}
+
+// Save replaces the file contents. The data is written to a temporary file
+// first so readers never see a partial write.
+func (b FileBackend) Save(data []byte) error {
+	dir := filepath.Dir(b.Path)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(dir, ".projects-*.json")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), b.Path)
+}
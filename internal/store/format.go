@@ -0,0 +1,120 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// CurrentVersion is the version of the on-disk format written by this build.
+// Files with an older version are migrated when loaded and rewritten in the
+// current format on the next save. Optional fields are added without a new
+// version, as older builds ignore fields they do not know; it changes only
+// when older builds would misread the file.
+const CurrentVersion = 2
+
+// ErrUnsupportedVersion indicates a project file written by a newer Frago.
+// It is never overwritten.
+var ErrUnsupportedVersion = errors.New("project file was written by a newer version of Frago")
+
+// migrations upgrade a decoded document from the version they are keyed by
+// to the next one. Version 1 is the unversioned format Frago stored in its
+// preferences.
+var migrations = map[int]func(doc map[string]any) error{
+	1: migrateV1,
+}
+
+type storedProject struct {
+	Path             string            `json:"path"`
+	PreferredPort    int               `json:"preferred_port,omitempty"`
+	LastPort         int               `json:"last_port,omitempty"`
+	LastURL          string            `json:"last_url,omitempty"`
+	LastVersionLabel string            `json:"last_version_label,omitempty"`
+	LastBinaryPath   string            `json:"last_binary_path,omitempty"`
+	Pinned           bool              `json:"pinned,omitempty"`
+	AutoStart        bool              `json:"auto_start,omitempty"`
+	LastUsed         time.Time         `json:"last_used,omitzero"`
+	DependsOn        []string          `json:"depends_on,omitempty"`
+	Env              map[string]string `json:"env,omitempty"`
+	Name             string            `json:"name,omitempty"`
+	Tags             []string          `json:"tags,omitempty"`
+	Docroot          string            `json:"docroot,omitempty"`
+	HealthPath       string            `json:"health_path,omitempty"`
+	RestartPolicy    string            `json:"restart_policy,omitempty"`
+	Muted            bool              `json:"muted,omitempty"`
+	PHP              string            `json:"php,omitempty"`
+	PHPIniPreset     string            `json:"php_ini_preset,omitempty"`
+	PHPIni           map[string]string `json:"php_ini,omitempty"`
+	Xdebug           Xdebug            `json:"xdebug,omitzero"`
+}
+
+type storedWorkspace struct {
+	ID       string   `json:"id"`
+	Name     string   `json:"name"`
+	Projects []string `json:"projects"`
+}
+
+type storedState struct {
+	Version    int               `json:"version"`
+	Projects   []storedProject   `json:"projects"`
+	Workspaces []storedWorkspace `json:"workspaces,omitempty"`
+}
+
+// decode parses raw in any supported version and migrates it to the current
+// one.
+func decode(raw []byte) (storedState, error) {
+	var doc map[string]any
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return storedState{}, fmt.Errorf("parse project state: %w", err)
+	}
+
+	version := 1
+	if v, ok := doc["version"].(float64); ok {
+		version = int(v)
+	}
+	if version > CurrentVersion {
+		return storedState{}, fmt.Errorf("%w (version %d)", ErrUnsupportedVersion, version)
+	}
+	for ; version < CurrentVersion; version++ {
+		migrate, ok := migrations[version]
+		if !ok {
+			return storedState{}, fmt.Errorf("no migration from project file version %d", version)
+		}
+		if err := migrate(doc); err != nil {
+			return storedState{}, fmt.Errorf("migrate project file from version %d: %w", version, err)
+		}
+	}
+	doc["version"] = CurrentVersion
+
+	migrated, err := json.Marshal(doc)
+	if err != nil {
+		return storedState{}, err
+	}
+	var state storedState
+	if err := json.Unmarshal(migrated, &state); err != nil {
+		return storedState{}, fmt.Errorf("parse project state: %w", err)
+	}
+	return state, nil
+}
+
+func encode(state storedState) ([]byte, error) {
+	state.Version = CurrentVersion
+	return json.MarshalIndent(state, "", "  ")
+}
+
+// migrateV1 replaces last_used_unix with an RFC 3339 last_used timestamp.
+func migrateV1(doc map[string]any) error {
+	projects, _ := doc["projects"].([]any)
+	for _, item := range projects {
+		project, ok := item.(map[string]any)
+		if !ok {
+			return fmt.Errorf("project entry is not an object")
+		}
+		if unix, ok := project["last_used_unix"].(float64); ok && unix > 0 {
+			project["last_used"] = time.Unix(int64(unix), 0).UTC().Format(time.RFC3339)
+		}
+		delete(project, "last_used_unix")
+	}
+	return nil
+}
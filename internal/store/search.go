@@ -0,0 +1,73 @@
+package store
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// DisplayName returns the project's name, or the last element of its path
+// when no name is set.
+func (p Project) DisplayName() string {
+	if p.Name != "" {
+		return p.Name
+	}
+	base := filepath.Base(p.Path)
+	if base == "" || base == "." || base == string(filepath.Separator) {
+		return p.Path
+	}
+	return base
+}
+
+// NormalizeTags trims tags and drops empty ones and repeats, ignoring case.
+// Commas split a tag in two so tags can be typed as a list.
+func NormalizeTags(tags []string) []string {
+	var out []string
+	seen := make(map[string]bool)
+	for _, tag := range tags {
+		for _, part := range strings.Split(tag, ",") {
+			part = strings.TrimSpace(part)
+			key := strings.ToLower(part)
+			if part == "" || seen[key] {
+				continue
+			}
+			seen[key] = true
+			out = append(out, part)
+		}
+	}
+	return out
+}
+
+// Matches reports whether every word of query, ignoring case, occurs in the
+// project's name, path or tags, or in status. "tag:NAME" only matches a tag
+// equal to NAME. An empty query matches every project.
+func (p Project) Matches(query, status string) bool {
+	for _, term := range strings.Fields(strings.ToLower(query)) {
+		if tag, ok := strings.CutPrefix(term, "tag:"); ok {
+			if !p.hasTag(tag) {
+				return false
+			}
+			continue
+		}
+		fields := append([]string{p.Name, p.Path, status}, p.Tags...)
+		found := false
+		for _, field := range fields {
+			if strings.Contains(strings.ToLower(field), term) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func (p Project) hasTag(tag string) bool {
+	for _, t := range p.Tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
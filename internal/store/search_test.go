@@ -0,0 +1,45 @@
+package store
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDisplayName(t *testing.T) {
+	if got := (Project{Path: "/srv/shop/app"}).DisplayName(); got != "app" {
+		t.Fatalf("expected the path base, got %q", got)
+	}
+	if got := (Project{Path: "/srv/shop/app", Name: "Shop"}).DisplayName(); got != "Shop" {
+		t.Fatalf("expected the name, got %q", got)
+	}
+}
+
+func TestNormalizeTags(t *testing.T) {
+	got := NormalizeTags([]string{" client-a, Laravel", "laravel", "", "legacy "})
+	want := []string{"client-a", "Laravel", "legacy"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+}
+
+func TestProjectMatches(t *testing.T) {
+	p := Project{Path: "/srv/client-a/web", Name: "Storefront", Tags: []string{"laravel", "client-a"}}
+	cases := []struct {
+		query string
+		want  bool
+	}{
+		{"", true},
+		{"store", true},
+		{"CLIENT-A/web", true},
+		{"laravel running", true},
+		{"tag:laravel", true},
+		{"tag:lara", false},
+		{"symfony", false},
+		{"store stopped", false},
+	}
+	for _, tc := range cases {
+		if got := p.Matches(tc.query, "running"); got != tc.want {
+			t.Errorf("Matches(%q) = %v, want %v", tc.query, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,515 @@
+// Package store owns the list of saved projects shared by the GUI and the API.
+package store
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrNotFound indicates no saved project matches the given path or ID.
+	ErrNotFound = errors.New("project not found")
+	// ErrExists indicates a project is already saved for the given path.
+	ErrExists = errors.New("project already exists")
+)
+
+// Project is a saved project and the settings Frago remembers for it.
+type Project struct {
+	ID               string    `json:"id"`
+	Path             string    `json:"path"`
+	PreferredPort    int       `json:"preferred_port,omitempty"`
+	LastPort         int       `json:"last_port,omitempty"`
+	LastURL          string    `json:"last_url,omitempty"`
+	LastVersionLabel string    `json:"last_version_label,omitempty"`
+	LastBinaryPath   string    `json:"last_binary_path,omitempty"`
+	Pinned           bool      `json:"pinned"`
+	AutoStart        bool      `json:"auto_start"`
+	LastUsed         time.Time `json:"last_used,omitzero"`
+	// DependsOn lists the paths of saved projects that must be running and
+	// ready before this one starts.
+	DependsOn []string `json:"depends_on,omitempty"`
+	// Env is added to the FrankenPHP environment, overriding variables of
+	// the same name from the project manifest.
+	Env map[string]string `json:"env,omitempty"`
+	// Name is shown instead of the path when set.
+	Name string `json:"name,omitempty"`
+	// Tags label the project for filtering; see NormalizeTags.
+	Tags []string `json:"tags,omitempty"`
+	// Docroot and HealthPath override the project manifest when set.
+	Docroot    string `json:"docroot,omitempty"`
+	HealthPath string `json:"health_path,omitempty"`
+	// RestartPolicy is one of the Restart constants.
+	RestartPolicy string `json:"restart_policy,omitempty"`
+	// Muted turns off desktop notifications for the project.
+	Muted bool `json:"muted,omitempty"`
+	// PHP is a version constraint such as "^8.3" that picks the binary,
+	// overriding the manifest and composer.json.
+	PHP string `json:"php,omitempty"`
+	// PHPIniPreset and PHPIni override the php.ini preset and settings of
+	// the project manifest; PHPIni is merged per setting.
+	PHPIniPreset string            `json:"php_ini_preset,omitempty"`
+	PHPIni       map[string]string `json:"php_ini,omitempty"`
+	// Xdebug holds the debug toggle and where Xdebug connects.
+	Xdebug Xdebug `json:"xdebug,omitzero"`
+}
+
+// Xdebug configures step debugging for a project. Zero values use Xdebug's
+// defaults.
+type Xdebug struct {
+	Enabled bool `json:"enabled"`
+	// ClientHost and ClientPort locate the IDE; Xdebug defaults to
+	// localhost:9003.
+	ClientHost string `json:"client_host,omitempty"`
+	ClientPort int    `json:"client_port,omitempty"`
+	// Trigger starts a debug session only for requests that carry an
+	// XDEBUG_TRIGGER or XDEBUG_SESSION cookie, query or body parameter,
+	// instead of for every request.
+	Trigger bool `json:"trigger,omitempty"`
+}
+
+// Restart policies decide whether Frago starts a project again after its
+// process exits without being stopped.
+const (
+	RestartNever     = ""
+	RestartOnFailure = "on-failure"
+	RestartAlways    = "always"
+)
+
+// RestartPolicies lists the valid restart policies.
+var RestartPolicies = []string{RestartNever, RestartOnFailure, RestartAlways}
+
+func (p Project) clone() Project {
+	p.DependsOn = append([]string(nil), p.DependsOn...)
+	p.Env = copyEnv(p.Env)
+	p.PHPIni = copyEnv(p.PHPIni)
+	p.Tags = append([]string(nil), p.Tags...)
+	return p
+}
+
+func copyEnv(env map[string]string) map[string]string {
+	if env == nil {
+		return nil
+	}
+	out := make(map[string]string, len(env))
+	for k, v := range env {
+		out[k] = v
+	}
+	return out
+}
+
+// ProjectID returns the stable identifier used for a project path in the API.
+func ProjectID(path string) string {
+	sum := sha256.Sum256([]byte(path))
+	return hex.EncodeToString(sum[:6])
+}
+
+// Backend persists the serialized project list.
+type Backend interface {
+	Load() ([]byte, error)
+	Save(data []byte) error
+}
+
+// Locker is implemented by backends shared between processes, such as the
+// GUI and the CLI. Lock blocks until no other process holds the lock.
+type Locker interface {
+	Lock() (unlock func() error, err error)
+}
+
+// Store is a goroutine-safe collection of projects. Every mutation is written
+// through to the backend. Callers receive copies, never internal pointers.
+//
+// When the backend is a Locker, each mutation holds the lock and first picks
+// up changes saved by other processes, so concurrent writers do not lose
+// each other's updates.
+type Store struct {
+	mu       sync.Mutex
+	backend  Backend
+	projects map[string]*Project
+	order    []string
+	// workspaces are kept in creation order.
+	workspaces []Workspace
+	// raw is the backend content last loaded or saved, used to detect
+	// changes made by other processes.
+	raw []byte
+
+	subMu  sync.Mutex
+	subs   map[int]func()
+	nextID int
+}
+
+// New creates an empty store persisted to backend.
+func New(backend Backend) *Store {
+	return &Store{
+		backend:  backend,
+		projects: make(map[string]*Project),
+		subs:     make(map[int]func()),
+	}
+}
+
+// Load replaces the in-memory projects with the backend contents, migrating
+// older formats.
+func (s *Store) Load() error {
+	s.mu.Lock()
+	_, err := s.reloadLocked(true)
+	s.mu.Unlock()
+	if err == nil {
+		s.notify()
+	}
+	return err
+}
+
+// Replace parses raw, in any supported format version, and replaces every
+// saved project with its contents.
+func (s *Store) Replace(raw []byte) error {
+	state, err := decode(raw)
+	if err != nil {
+		return err
+	}
+	return s.mutate(func() (bool, error) {
+		s.applyLocked(state)
+		return true, nil
+	})
+}
+
+// Subscribe registers fn to be called after the projects change, whether
+// through this store or, while Watch runs, by another process. fn runs on the
+// goroutine that made the change. The returned function unsubscribes.
+func (s *Store) Subscribe(fn func()) func() {
+	s.subMu.Lock()
+	defer s.subMu.Unlock()
+	id := s.nextID
+	s.nextID++
+	s.subs[id] = fn
+	return func() {
+		s.subMu.Lock()
+		defer s.subMu.Unlock()
+		delete(s.subs, id)
+	}
+}
+
+// Watch polls the backend every interval and reloads the store when another
+// process changed it, until ctx is canceled.
+func (s *Store) Watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		s.mu.Lock()
+		changed, err := s.reloadLocked(false)
+		s.mu.Unlock()
+		if err == nil && changed {
+			s.notify()
+		}
+	}
+}
+
+// List returns all projects in the order they were added.
+func (s *Store) List() []Project {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list := make([]Project, 0, len(s.order))
+	for _, path := range s.order {
+		list = append(list, s.projects[path].clone())
+	}
+	return list
+}
+
+// Sorted returns all projects in display order: pinned first, then most
+// recently used, then the order they were added.
+func (s *Store) Sorted() []Project {
+	list := s.List()
+	sort.SliceStable(list, func(i, j int) bool {
+		if list[i].Pinned != list[j].Pinned {
+			return list[i].Pinned
+		}
+		if list[i].LastUsed.IsZero() != list[j].LastUsed.IsZero() {
+			return !list[i].LastUsed.IsZero()
+		}
+		return list[i].LastUsed.After(list[j].LastUsed)
+	})
+	return list
+}
+
+// Get returns the project saved for path.
+func (s *Store) Get(path string) (Project, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p, ok := s.projects[path]
+	if !ok {
+		return Project{}, false
+	}
+	return p.clone(), true
+}
+
+// GetByID returns the project with the given API identifier.
+func (s *Store) GetByID(id string) (Project, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, path := range s.order {
+		if p := s.projects[path]; p.ID == id {
+			return p.clone(), true
+		}
+	}
+	return Project{}, false
+}
+
+// Ensure returns the project for path, creating an empty one if needed.
+// The boolean reports whether the project was created.
+func (s *Store) Ensure(path string) (Project, bool, error) {
+	s.mu.Lock()
+	if p, ok := s.projects[path]; ok {
+		s.mu.Unlock()
+		return p.clone(), false, nil
+	}
+	s.mu.Unlock()
+
+	var project Project
+	var created bool
+	err := s.mutate(func() (bool, error) {
+		_, exists := s.projects[path]
+		project = s.ensureLocked(path).clone()
+		created = !exists
+		return created, nil
+	})
+	return project, created, err
+}
+
+// Create adds a new project. It fails with ErrExists if the path is taken.
+func (s *Store) Create(project Project) (Project, error) {
+	if project.Path == "" {
+		return Project{}, fmt.Errorf("project path is required")
+	}
+
+	var created Project
+	err := s.mutate(func() (bool, error) {
+		if _, ok := s.projects[project.Path]; ok {
+			return false, fmt.Errorf("%w: %s", ErrExists, project.Path)
+		}
+		p := s.ensureLocked(project.Path)
+		id := p.ID
+		*p = project.clone()
+		p.ID = id
+		created = p.clone()
+		return true, nil
+	})
+	return created, err
+}
+
+// Update applies fn to the project saved for path and persists the result.
+// The path and ID cannot be changed by fn.
+func (s *Store) Update(path string, fn func(*Project)) (Project, error) {
+	var updated Project
+	err := s.mutate(func() (bool, error) {
+		p, ok := s.projects[path]
+		if !ok {
+			return false, fmt.Errorf("%w: %s", ErrNotFound, path)
+		}
+		updated = p.clone()
+		fn(&updated)
+		updated.Path = p.Path
+		updated.ID = p.ID
+		*p = updated.clone()
+		return true, nil
+	})
+	return updated, err
+}
+
+// Delete removes the project saved for path.
+func (s *Store) Delete(path string) error {
+	return s.mutate(func() (bool, error) {
+		if _, ok := s.projects[path]; !ok {
+			return false, fmt.Errorf("%w: %s", ErrNotFound, path)
+		}
+		delete(s.projects, path)
+		s.removeFromWorkspacesLocked(path)
+		for _, other := range s.projects {
+			other.DependsOn = without(other.DependsOn, path)
+		}
+		for i, p := range s.order {
+			if p == path {
+				s.order = append(s.order[:i], s.order[i+1:]...)
+				break
+			}
+		}
+		return true, nil
+	})
+}
+
+// mutate runs fn under the store mutex and, when the backend supports it, the
+// cross-process lock. Changes saved by other processes are loaded first. If
+// fn reports a change it is saved; subscribers are notified of any change.
+func (s *Store) mutate(fn func() (bool, error)) error {
+	if locker, ok := s.backend.(Locker); ok {
+		unlock, err := locker.Lock()
+		if err != nil {
+			return fmt.Errorf("lock project state: %w", err)
+		}
+		defer func() { _ = unlock() }()
+	}
+
+	s.mu.Lock()
+	reloaded, err := s.reloadLocked(false)
+	if err != nil {
+		s.mu.Unlock()
+		return err
+	}
+	changed, err := fn()
+	if err == nil && changed {
+		err = s.saveLocked()
+	}
+	s.mu.Unlock()
+
+	if reloaded || (changed && err == nil) {
+		s.notify()
+	}
+	return err
+}
+
+// reloadLocked reads the backend and replaces the in-memory projects when the
+// content differs from what was last seen, or always when force is set.
+func (s *Store) reloadLocked(force bool) (bool, error) {
+	raw, err := s.backend.Load()
+	if err != nil {
+		return false, err
+	}
+	if !force && bytes.Equal(raw, s.raw) {
+		return false, nil
+	}
+
+	state := storedState{}
+	if len(bytes.TrimSpace(raw)) > 0 {
+		if state, err = decode(raw); err != nil {
+			return false, err
+		}
+	}
+	s.applyLocked(state)
+	s.raw = raw
+	return true, nil
+}
+
+func (s *Store) applyLocked(state storedState) {
+	s.projects = make(map[string]*Project)
+	s.order = nil
+	for _, stored := range state.Projects {
+		if stored.Path == "" {
+			continue
+		}
+		p := s.ensureLocked(stored.Path)
+		p.PreferredPort = stored.PreferredPort
+		p.LastPort = stored.LastPort
+		p.LastURL = stored.LastURL
+		p.LastVersionLabel = stored.LastVersionLabel
+		p.LastBinaryPath = stored.LastBinaryPath
+		p.Pinned = stored.Pinned
+		p.AutoStart = stored.AutoStart
+		p.LastUsed = stored.LastUsed
+		p.DependsOn = append([]string(nil), stored.DependsOn...)
+		p.Env = stored.Env
+		p.Name = stored.Name
+		p.Tags = append([]string(nil), stored.Tags...)
+		p.Docroot = stored.Docroot
+		p.HealthPath = stored.HealthPath
+		p.RestartPolicy = stored.RestartPolicy
+		p.Muted = stored.Muted
+		p.PHP = stored.PHP
+		p.PHPIniPreset = stored.PHPIniPreset
+		p.PHPIni = stored.PHPIni
+		p.Xdebug = stored.Xdebug
+	}
+
+	s.workspaces = nil
+	for _, stored := range state.Workspaces {
+		w := Workspace{ID: stored.ID, Name: stored.Name}
+		for _, path := range stored.Projects {
+			if _, ok := s.projects[path]; ok && !w.Contains(path) {
+				w.Projects = append(w.Projects, path)
+			}
+		}
+		s.workspaces = append(s.workspaces, w)
+	}
+}
+
+func (s *Store) notify() {
+	s.subMu.Lock()
+	subs := make([]func(), 0, len(s.subs))
+	for _, fn := range s.subs {
+		subs = append(subs, fn)
+	}
+	s.subMu.Unlock()
+
+	for _, fn := range subs {
+		fn()
+	}
+}
+
+func (s *Store) ensureLocked(path string) *Project {
+	if p, ok := s.projects[path]; ok {
+		return p
+	}
+	p := &Project{ID: ProjectID(path), Path: path}
+	s.projects[path] = p
+	s.order = append(s.order, path)
+	return p
+}
+
+func (s *Store) saveLocked() error {
+	state := storedState{
+		Projects: make([]storedProject, 0, len(s.order)),
+	}
+	for _, path := range s.order {
+		p := s.projects[path]
+		state.Projects = append(state.Projects, storedProject{
+			Path:             p.Path,
+			PreferredPort:    p.PreferredPort,
+			LastPort:         p.LastPort,
+			LastURL:          p.LastURL,
+			LastVersionLabel: p.LastVersionLabel,
+			LastBinaryPath:   p.LastBinaryPath,
+			Pinned:           p.Pinned,
+			AutoStart:        p.AutoStart,
+			LastUsed:         p.LastUsed,
+			DependsOn:        p.DependsOn,
+			Env:              p.Env,
+			Name:             p.Name,
+			Tags:             p.Tags,
+			Docroot:          p.Docroot,
+			HealthPath:       p.HealthPath,
+			RestartPolicy:    p.RestartPolicy,
+			Muted:            p.Muted,
+			PHP:              p.PHP,
+			PHPIniPreset:     p.PHPIniPreset,
+			PHPIni:           p.PHPIni,
+			Xdebug:           p.Xdebug,
+		})
+	}
+
+	for _, w := range s.workspaces {
+		state.Workspaces = append(state.Workspaces, storedWorkspace{
+			ID:       w.ID,
+			Name:     w.Name,
+			Projects: w.Projects,
+		})
+	}
+
+	raw, err := encode(state)
+	if err != nil {
+		return err
+	}
+	if err := s.backend.Save(raw); err != nil {
+		return err
+	}
+	s.raw = raw
+	return nil
+}
@@ -0,0 +1,281 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type memoryBackend struct {
+	data []byte
+}
+
+func (b *memoryBackend) Load() ([]byte, error) { return b.data, nil }
+
+func (b *memoryBackend) Save(data []byte) error {
+	b.data = append([]byte(nil), data...)
+	return nil
+}
+
+func TestStorePersistsAcrossLoad(t *testing.T) {
+	backend := &memoryBackend{}
+	s := New(backend)
+
+	if _, err := s.Create(Project{Path: "/srv/shop", PreferredPort: 8081, AutoStart: true}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	used := time.Unix(1700000000, 0)
+	if _, err := s.Update("/srv/shop", func(p *Project) {
+		p.Pinned = true
+		p.LastUsed = used
+		p.Name = "Shop"
+		p.Docroot = "public"
+		p.HealthPath = "/up"
+		p.RestartPolicy = RestartOnFailure
+		p.Path = "/elsewhere"
+	}); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+
+	reloaded := New(backend)
+	if err := reloaded.Load(); err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	got, ok := reloaded.Get("/srv/shop")
+	if !ok {
+		t.Fatalf("project missing after reload")
+	}
+	if got.ID != ProjectID("/srv/shop") || got.PreferredPort != 8081 || !got.AutoStart || !got.Pinned || !got.LastUsed.Equal(used) {
+		t.Fatalf("unexpected project after reload: %+v", got)
+	}
+	if got.Name != "Shop" || got.Docroot != "public" || got.HealthPath != "/up" || got.RestartPolicy != RestartOnFailure {
+		t.Fatalf("settings not kept after reload: %+v", got)
+	}
+}
+
+func TestStoreErrors(t *testing.T) {
+	s := New(&memoryBackend{})
+	if _, err := s.Create(Project{Path: "/a"}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if _, err := s.Create(Project{Path: "/a"}); !errors.Is(err, ErrExists) {
+		t.Fatalf("expected ErrExists, got %v", err)
+	}
+	if _, err := s.Update("/missing", func(*Project) {}); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+	if err := s.Delete("/missing"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestStoreSorted(t *testing.T) {
+	s := New(&memoryBackend{})
+	now := time.Now()
+	for _, p := range []Project{
+		{Path: "/never"},
+		{Path: "/old", LastUsed: now.Add(-time.Hour)},
+		{Path: "/pinned", Pinned: true},
+		{Path: "/new", LastUsed: now},
+	} {
+		if _, err := s.Create(p); err != nil {
+			t.Fatalf("create %s: %v", p.Path, err)
+		}
+	}
+
+	want := []string{"/pinned", "/new", "/old", "/never"}
+	for i, p := range s.Sorted() {
+		if p.Path != want[i] {
+			t.Fatalf("position %d: expected %s, got %s", i, want[i], p.Path)
+		}
+	}
+}
+
+func TestFileBackendRoundTrip(t *testing.T) {
+	backend := FileBackend{Path: filepath.Join(t.TempDir(), "nested", "projects.json")}
+
+	s := New(backend)
+	if err := s.Load(); err != nil {
+		t.Fatalf("load missing file: %v", err)
+	}
+	if _, err := s.Create(Project{Path: "/srv/shop", PreferredPort: 8081}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	reloaded := New(backend)
+	if err := reloaded.Load(); err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	if p, ok := reloaded.Get("/srv/shop"); !ok || p.PreferredPort != 8081 {
+		t.Fatalf("unexpected project after reload: %+v, %v", p, ok)
+	}
+}
+
+func TestLoadMigratesVersion1(t *testing.T) {
+	backend := &memoryBackend{data: []byte(`{"projects":[{"path":"/srv/shop","preferred_port":8081,"pinned":true,"last_used_unix":1700000000}]}`)}
+	s := New(backend)
+	if err := s.Load(); err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	got, ok := s.Get("/srv/shop")
+	if !ok || got.PreferredPort != 8081 || !got.Pinned || !got.LastUsed.Equal(time.Unix(1700000000, 0)) {
+		t.Fatalf("unexpected migrated project: %+v", got)
+	}
+
+	if _, err := s.Update("/srv/shop", func(p *Project) { p.AutoStart = true }); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	var saved map[string]any
+	if err := json.Unmarshal(backend.data, &saved); err != nil {
+		t.Fatalf("decode saved: %v", err)
+	}
+	if saved["version"] != float64(CurrentVersion) {
+		t.Fatalf("expected version %d, got %v", CurrentVersion, saved["version"])
+	}
+	if strings.Contains(string(backend.data), "last_used_unix") {
+		t.Fatalf("legacy field still present: %s", backend.data)
+	}
+}
+
+func TestNewerVersionIsNotOverwritten(t *testing.T) {
+	original := []byte(`{"version":99,"projects":[]}`)
+	backend := &memoryBackend{data: original}
+	s := New(backend)
+	if err := s.Load(); !errors.Is(err, ErrUnsupportedVersion) {
+		t.Fatalf("expected ErrUnsupportedVersion, got %v", err)
+	}
+	if _, err := s.Create(Project{Path: "/srv/shop"}); !errors.Is(err, ErrUnsupportedVersion) {
+		t.Fatalf("expected create to fail with ErrUnsupportedVersion, got %v", err)
+	}
+	if string(backend.data) != string(original) {
+		t.Fatalf("newer file was overwritten: %s", backend.data)
+	}
+}
+
+func TestConcurrentStoresShareFile(t *testing.T) {
+	backend := FileBackend{Path: filepath.Join(t.TempDir(), "projects.json")}
+	gui, cli := New(backend), New(backend)
+	for _, s := range []*Store{gui, cli} {
+		if err := s.Load(); err != nil {
+			t.Fatalf("load: %v", err)
+		}
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			if _, err := gui.Create(Project{Path: fmt.Sprintf("/srv/gui-%d", i)}); err != nil {
+				t.Errorf("gui create: %v", err)
+			}
+		}(i)
+		go func(i int) {
+			defer wg.Done()
+			if _, err := cli.Create(Project{Path: fmt.Sprintf("/srv/cli-%d", i)}); err != nil {
+				t.Errorf("cli create: %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	check := New(backend)
+	if err := check.Load(); err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if got := len(check.List()); got != 40 {
+		t.Fatalf("expected 40 projects, got %d", got)
+	}
+}
+
+func TestSubscribeAndWatch(t *testing.T) {
+	backend := FileBackend{Path: filepath.Join(t.TempDir(), "projects.json")}
+	watched, other := New(backend), New(backend)
+
+	changes := make(chan struct{}, 10)
+	unsubscribe := watched.Subscribe(func() { changes <- struct{}{} })
+	defer unsubscribe()
+
+	if _, err := watched.Create(Project{Path: "/srv/shop"}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	select {
+	case <-changes:
+	default:
+		t.Fatalf("expected a notification for a local change")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go watched.Watch(ctx, 10*time.Millisecond)
+
+	if _, err := other.Create(Project{Path: "/srv/api"}); err != nil {
+		t.Fatalf("create from other store: %v", err)
+	}
+	select {
+	case <-changes:
+	case <-time.After(2 * time.Second):
+		t.Fatalf("expected a notification for an external change")
+	}
+	if _, ok := watched.Get("/srv/api"); !ok {
+		t.Fatalf("external change was not loaded")
+	}
+}
+
+func TestWorkspaces(t *testing.T) {
+	backend := &memoryBackend{}
+	s := New(backend)
+	for _, path := range []string{"/srv/api", "/srv/shop", "/srv/admin"} {
+		if _, err := s.Create(Project{Path: path}); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+	}
+
+	w, err := s.CreateWorkspace(" Shop ", []string{"/srv/api", "/srv/shop"})
+	if err != nil {
+		t.Fatalf("create workspace: %v", err)
+	}
+	if w.Name != "Shop" || w.ID == "" {
+		t.Fatalf("unexpected workspace: %+v", w)
+	}
+	if _, err := s.CreateWorkspace("shop", nil); !errors.Is(err, ErrExists) {
+		t.Fatalf("expected ErrExists for duplicate name, got %v", err)
+	}
+	if _, err := s.CreateWorkspace("Other", []string{"/nowhere"}); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound for unknown project, got %v", err)
+	}
+	if _, err := s.UpdateWorkspace(w.ID, func(w *Workspace) { w.Projects = append(w.Projects, "/srv/api") }); err == nil {
+		t.Fatalf("expected duplicate member to be rejected")
+	}
+
+	if _, err := s.UpdateWorkspace(w.ID, func(w *Workspace) {
+		w.Projects = []string{"/srv/admin", "/srv/api", "/srv/shop"}
+	}); err != nil {
+		t.Fatalf("update workspace: %v", err)
+	}
+	if err := s.Delete("/srv/api"); err != nil {
+		t.Fatalf("delete project: %v", err)
+	}
+
+	reloaded := New(backend)
+	if err := reloaded.Load(); err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	got, ok := reloaded.GetWorkspace(w.ID)
+	if !ok || strings.Join(got.Projects, ",") != "/srv/admin,/srv/shop" {
+		t.Fatalf("unexpected workspace after reload: %+v", got)
+	}
+
+	if err := reloaded.DeleteWorkspace(w.ID); err != nil {
+		t.Fatalf("delete workspace: %v", err)
+	}
+	if len(reloaded.Workspaces()) != 0 || len(reloaded.List()) != 2 {
+		t.Fatalf("deleting a workspace must keep its projects")
+	}
+}
@@ -0,0 +1,166 @@
+package store
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// Workspace is a named group of saved projects. Projects holds project paths
+// in start order; they are stopped in reverse order.
+type Workspace struct {
+	ID       string   `json:"id"`
+	Name     string   `json:"name"`
+	Projects []string `json:"projects"`
+}
+
+func (w Workspace) clone() Workspace {
+	w.Projects = append([]string(nil), w.Projects...)
+	return w
+}
+
+// Contains reports whether path is a member of w.
+func (w Workspace) Contains(path string) bool {
+	for _, p := range w.Projects {
+		if p == path {
+			return true
+		}
+	}
+	return false
+}
+
+// Workspaces returns all workspaces in the order they were created.
+func (s *Store) Workspaces() []Workspace {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list := make([]Workspace, 0, len(s.workspaces))
+	for _, w := range s.workspaces {
+		list = append(list, w.clone())
+	}
+	return list
+}
+
+// GetWorkspace returns the workspace with the given ID.
+func (s *Store) GetWorkspace(id string) (Workspace, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if i := s.workspaceIndexLocked(id); i >= 0 {
+		return s.workspaces[i].clone(), true
+	}
+	return Workspace{}, false
+}
+
+// CreateWorkspace adds a workspace. Names must be unique, ignoring case, and
+// every project must already be saved.
+func (s *Store) CreateWorkspace(name string, projects []string) (Workspace, error) {
+	id, err := newWorkspaceID()
+	if err != nil {
+		return Workspace{}, err
+	}
+
+	var created Workspace
+	err = s.mutate(func() (bool, error) {
+		w := Workspace{ID: id, Name: strings.TrimSpace(name), Projects: append([]string(nil), projects...)}
+		if err := s.validateWorkspaceLocked(w); err != nil {
+			return false, err
+		}
+		s.workspaces = append(s.workspaces, w)
+		created = w.clone()
+		return true, nil
+	})
+	return created, err
+}
+
+// UpdateWorkspace applies fn to the workspace with the given ID and persists
+// the result. The ID cannot be changed by fn.
+func (s *Store) UpdateWorkspace(id string, fn func(*Workspace)) (Workspace, error) {
+	var updated Workspace
+	err := s.mutate(func() (bool, error) {
+		i := s.workspaceIndexLocked(id)
+		if i < 0 {
+			return false, fmt.Errorf("%w: workspace %s", ErrNotFound, id)
+		}
+		w := s.workspaces[i].clone()
+		fn(&w)
+		w.ID = id
+		w.Name = strings.TrimSpace(w.Name)
+		if err := s.validateWorkspaceLocked(w); err != nil {
+			return false, err
+		}
+		s.workspaces[i] = w
+		updated = w.clone()
+		return true, nil
+	})
+	return updated, err
+}
+
+// DeleteWorkspace removes a workspace. Its projects stay saved.
+func (s *Store) DeleteWorkspace(id string) error {
+	return s.mutate(func() (bool, error) {
+		i := s.workspaceIndexLocked(id)
+		if i < 0 {
+			return false, fmt.Errorf("%w: workspace %s", ErrNotFound, id)
+		}
+		s.workspaces = append(s.workspaces[:i], s.workspaces[i+1:]...)
+		return true, nil
+	})
+}
+
+func (s *Store) workspaceIndexLocked(id string) int {
+	for i, w := range s.workspaces {
+		if w.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+func (s *Store) validateWorkspaceLocked(w Workspace) error {
+	if w.Name == "" {
+		return fmt.Errorf("workspace name is required")
+	}
+	for _, other := range s.workspaces {
+		if other.ID != w.ID && strings.EqualFold(other.Name, w.Name) {
+			return fmt.Errorf("%w: workspace %q", ErrExists, w.Name)
+		}
+	}
+	seen := make(map[string]bool, len(w.Projects))
+	for _, path := range w.Projects {
+		if _, ok := s.projects[path]; !ok {
+			return fmt.Errorf("%w: %s", ErrNotFound, path)
+		}
+		if seen[path] {
+			return fmt.Errorf("project %s is listed twice", path)
+		}
+		seen[path] = true
+	}
+	return nil
+}
+
+// removeFromWorkspacesLocked drops path from every workspace.
+func (s *Store) removeFromWorkspacesLocked(path string) {
+	for i, w := range s.workspaces {
+		s.workspaces[i].Projects = without(w.Projects, path)
+	}
+}
+
+// without returns a copy of paths with path removed.
+func without(paths []string, path string) []string {
+	var kept []string
+	for _, p := range paths {
+		if p != path {
+			kept = append(kept, p)
+		}
+	}
+	return kept
+}
+
+func newWorkspaceID() (string, error) {
+	b := make([]byte, 6)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
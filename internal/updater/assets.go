@@ -0,0 +1,120 @@
+package updater
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ErrNoAsset indicates a release has no build for this platform.
+var ErrNoAsset = errors.New("no FrankenPHP build for this platform")
+
+// ErrNoChecksum indicates a release publishes no checksum for an asset, so
+// it cannot be verified.
+var ErrNoChecksum = errors.New("no checksum published for asset")
+
+// platformOS and platformArch map GOOS and GOARCH to the names FrankenPHP
+// release assets use.
+var (
+	platformOS   = map[string]string{"linux": "linux", "darwin": "mac", "windows": "windows", "freebsd": "freebsd"}
+	platformArch = map[string]string{"amd64": "x86_64", "arm64": "aarch64"}
+)
+
+// PlatformAssets returns the release assets that are FrankenPHP builds for
+// goos and goarch, preferring the plain static build over variants such as
+// "-gnu" or "-debug".
+func PlatformAssets(release ReleaseInfo, goos, goarch string) []Asset {
+	osName, ok := platformOS[goos]
+	arch, archOK := platformArch[goarch]
+	if !ok || !archOK {
+		return nil
+	}
+	arches := []string{arch}
+	if goos == "darwin" && goarch == "arm64" {
+		// macOS builds use Apple's name for the architecture.
+		arches = []string{"arm64", arch}
+	}
+
+	var exact, variants []Asset
+	for _, a := range release.Assets {
+		name := strings.ToLower(a.Name)
+		if isChecksumFile(name) {
+			continue
+		}
+		for _, arch := range arches {
+			prefix := fmt.Sprintf("frankenphp-%s-%s", osName, arch)
+			if name == prefix || name == prefix+".exe" || name == prefix+".zip" {
+				exact = append(exact, a)
+				break
+			}
+			if strings.HasPrefix(name, prefix+"-") {
+				variants = append(variants, a)
+				break
+			}
+		}
+	}
+	return append(exact, variants...)
+}
+
+// PlatformAsset returns the preferred FrankenPHP build for goos and goarch.
+func PlatformAsset(release ReleaseInfo, goos, goarch string) (Asset, error) {
+	assets := PlatformAssets(release, goos, goarch)
+	if len(assets) == 0 {
+		return Asset{}, fmt.Errorf("%w (%s/%s) in %s", ErrNoAsset, goos, goarch, release.TagName)
+	}
+	return assets[0], nil
+}
+
+func isChecksumFile(name string) bool {
+	return strings.HasSuffix(name, ".sha256") || strings.HasSuffix(name, ".sha256sum") ||
+		strings.Contains(name, "checksums") || strings.Contains(name, "sha256sums")
+}
+
+// checksum returns the expected SHA-256 of asset as lowercase hex. It uses
+// the digest GitHub reports, a "<asset>.sha256" file, or a checksums file
+// listing every asset, in that order.
+func (c *Client) checksum(ctx context.Context, release ReleaseInfo, asset Asset) (string, error) {
+	if sum, ok := strings.CutPrefix(asset.Digest, "sha256:"); ok && sum != "" {
+		return strings.ToLower(sum), nil
+	}
+	for _, a := range release.Assets {
+		name := strings.ToLower(a.Name)
+		switch {
+		case name == strings.ToLower(asset.Name)+".sha256", name == strings.ToLower(asset.Name)+".sha256sum":
+			return c.findChecksum(ctx, a.DownloadURL, "")
+		case isChecksumFile(name) && !strings.HasSuffix(name, ".sha256") && !strings.HasSuffix(name, ".sha256sum"):
+			if sum, err := c.findChecksum(ctx, a.DownloadURL, asset.Name); err == nil {
+				return sum, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("%w %s", ErrNoChecksum, asset.Name)
+}
+
+// findChecksum reads a sha256sum-style file and returns the checksum listed
+// for name, or the first one when name is empty.
+func (c *Client) findChecksum(ctx context.Context, url, name string) (string, error) {
+	resp, err := c.get(ctx, url)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	scanner := bufio.NewScanner(io.LimitReader(resp.Body, 1<<20))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || len(fields[0]) != 64 {
+			continue
+		}
+		if name == "" || (len(fields) > 1 && strings.TrimPrefix(fields[len(fields)-1], "*") == name) {
+			return strings.ToLower(fields[0]), nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+	return "", fmt.Errorf("%w %s", ErrNoChecksum, name)
+}
@@ -0,0 +1,265 @@
+package updater
+
+import (
+	"archive/zip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/devmarvs/frago/internal/runner"
+)
+
+// ErrChecksumMismatch indicates a download that does not match its published
+// checksum. It is discarded.
+var ErrChecksumMismatch = errors.New("checksum mismatch")
+
+// ErrArchiveCollision indicates a zip archive holding two files with the
+// same name in different directories. They would overwrite each other when
+// flattened, so the archive is rejected.
+var ErrArchiveCollision = errors.New("archive contains two files with the same name")
+
+// Builds manages the FrankenPHP builds downloaded to Dir, one subdirectory
+// per release tag. runner.DetectVersions lists them alongside the binaries
+// found on PATH.
+type Builds struct {
+	Dir    string
+	Client *Client
+	// GOOS and GOARCH select the release asset; they default to the
+	// platform Frago runs on.
+	GOOS, GOARCH string
+}
+
+// NewBuilds returns the builds in runner.ManagedDir, downloaded from the
+// source set in FRAGO_RELEASE_SOURCE.
+func NewBuilds() (*Builds, error) {
+	dir, err := runner.ManagedDir()
+	if err != nil {
+		return nil, err
+	}
+	client, err := NewClient()
+	if err != nil {
+		return nil, err
+	}
+	return &Builds{Dir: dir, Client: client}, nil
+}
+
+// Installed lists the downloaded builds.
+func (b *Builds) Installed() []runner.ManagedBuild {
+	return runner.ManagedBuilds(b.Dir)
+}
+
+// Path returns the binary of the downloaded build for tag.
+func (b *Builds) Path(tag string) (string, bool) {
+	for _, build := range b.Installed() {
+		if build.Version == tag {
+			return build.Path, true
+		}
+	}
+	return "", false
+}
+
+// Progress is called while a build downloads with the bytes received so far
+// and the total size, which is 0 when unknown.
+type Progress func(done, total int64)
+
+// Install downloads the build of release for this platform, verifies its
+// checksum and stores it as an executable under its tag, replacing an
+// earlier download of the same tag. Nothing is stored when any step fails.
+func (b *Builds) Install(ctx context.Context, release ReleaseInfo, progress Progress) (runner.ManagedBuild, error) {
+	tag := release.TagName
+	if !validTag(tag) {
+		return runner.ManagedBuild{}, fmt.Errorf("invalid release tag %q", tag)
+	}
+	asset, err := PlatformAsset(release, b.goos(), b.goarch())
+	if err != nil {
+		return runner.ManagedBuild{}, err
+	}
+	want, err := b.Client.checksum(ctx, release, asset)
+	if err != nil {
+		return runner.ManagedBuild{}, err
+	}
+
+	if err := os.MkdirAll(b.Dir, 0755); err != nil {
+		return runner.ManagedBuild{}, err
+	}
+	staging, err := os.MkdirTemp(b.Dir, ".download-")
+	if err != nil {
+		return runner.ManagedBuild{}, err
+	}
+	defer os.RemoveAll(staging)
+
+	download := filepath.Join(staging, "asset")
+	if err := b.Client.download(ctx, asset, download, want, progress); err != nil {
+		return runner.ManagedBuild{}, err
+	}
+
+	buildDir := filepath.Join(staging, "build")
+	if err := os.Mkdir(buildDir, 0755); err != nil {
+		return runner.ManagedBuild{}, err
+	}
+	binary := filepath.Join(buildDir, runner.ManagedBinaryName())
+	if strings.HasSuffix(strings.ToLower(asset.Name), ".zip") {
+		err = extractZip(download, buildDir)
+	} else {
+		err = os.Rename(download, binary)
+	}
+	if err != nil {
+		return runner.ManagedBuild{}, err
+	}
+	if st, err := os.Stat(binary); err != nil || st.IsDir() {
+		return runner.ManagedBuild{}, fmt.Errorf("%s does not contain %s", asset.Name, runner.ManagedBinaryName())
+	}
+	if err := os.Chmod(binary, 0755); err != nil {
+		return runner.ManagedBuild{}, err
+	}
+
+	target := filepath.Join(b.Dir, tag)
+	if err := os.RemoveAll(target); err != nil {
+		return runner.ManagedBuild{}, err
+	}
+	if err := os.Rename(buildDir, target); err != nil {
+		return runner.ManagedBuild{}, err
+	}
+	return runner.ManagedBuild{Version: tag, Path: filepath.Join(target, runner.ManagedBinaryName())}, nil
+}
+
+// Remove deletes the downloaded build for tag.
+func (b *Builds) Remove(tag string) error {
+	if _, ok := b.Path(tag); !ok || !validTag(tag) {
+		return fmt.Errorf("FrankenPHP %s is not installed", tag)
+	}
+	return os.RemoveAll(filepath.Join(b.Dir, tag))
+}
+
+// download saves asset to path and checks it against the SHA-256 want.
+func (c *Client) download(ctx context.Context, asset Asset, path, want string, progress Progress) error {
+	resp, err := c.get(ctx, asset.DownloadURL)
+	if err != nil {
+		return fmt.Errorf("download %s: %w", asset.Name, err)
+	}
+	defer resp.Body.Close()
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	total := resp.ContentLength
+	if total < 0 {
+		total = asset.Size
+	}
+	hash := sha256.New()
+	var body io.Reader = resp.Body
+	if progress != nil {
+		body = &progressReader{r: body, total: total, fn: progress}
+	}
+	if _, err := io.Copy(io.MultiWriter(f, hash), body); err != nil {
+		return fmt.Errorf("download %s: %w", asset.Name, err)
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if got := hex.EncodeToString(hash.Sum(nil)); got != want {
+		return fmt.Errorf("%w for %s: expected %s, got %s", ErrChecksumMismatch, asset.Name, want, got)
+	}
+	return nil
+}
+
+type progressReader struct {
+	r     io.Reader
+	done  int64
+	total int64
+	fn    Progress
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	p.done += int64(n)
+	p.fn(p.done, p.total)
+	return n, err
+}
+
+// extractZip unpacks the files of archive into dir, flattening any
+// directories so the binary and the libraries next to it end up side by side.
+// Nothing is extracted when two files would end up with the same name,
+// compared without case as on Windows and macOS.
+func extractZip(archive, dir string) error {
+	r, err := zip.OpenReader(archive)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	type entry struct {
+		file *zip.File
+		name string
+	}
+	var entries []entry
+	seen := make(map[string]string)
+	for _, file := range r.File {
+		if file.FileInfo().IsDir() {
+			continue
+		}
+		name := filepath.Base(filepath.FromSlash(file.Name))
+		if name == "." || name == ".." || name == string(filepath.Separator) {
+			continue
+		}
+		if other, ok := seen[strings.ToLower(name)]; ok {
+			return fmt.Errorf("%w: %s and %s", ErrArchiveCollision, other, file.Name)
+		}
+		seen[strings.ToLower(name)] = file.Name
+		entries = append(entries, entry{file, name})
+	}
+
+	for _, e := range entries {
+		if err := extractFile(e.file, filepath.Join(dir, e.name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func extractFile(file *zip.File, path string) error {
+	src, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	dst, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(dst, src); err != nil {
+		dst.Close()
+		return err
+	}
+	return dst.Close()
+}
+
+// validTag reports whether tag is safe to use as a directory name.
+func validTag(tag string) bool {
+	return tag != "" && tag != "." && tag != ".." && !strings.HasPrefix(tag, ".") &&
+		!strings.ContainsAny(tag, `/\:`)
+}
+
+func (b *Builds) goos() string {
+	if b.GOOS != "" {
+		return b.GOOS
+	}
+	return runtime.GOOS
+}
+
+func (b *Builds) goarch() string {
+	if b.GOARCH != "" {
+		return b.GOARCH
+	}
+	return runtime.GOARCH
+}
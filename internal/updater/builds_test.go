@@ -0,0 +1,200 @@
+package updater
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/devmarvs/frago/internal/runner"
+)
+
+// fakeReleases serves a GitHub-style release API with the given files as
+// assets of one release.
+type fakeReleases struct {
+	*httptest.Server
+	files   map[string][]byte
+	release ReleaseInfo
+}
+
+func newFakeReleases(t *testing.T, tag string, files map[string][]byte, digests map[string]string) *fakeReleases {
+	t.Helper()
+	f := &fakeReleases{files: files}
+	mux := http.NewServeMux()
+	f.Server = httptest.NewServer(mux)
+	t.Cleanup(f.Close)
+
+	f.release = ReleaseInfo{TagName: tag, HtmlUrl: f.URL + "/release/" + tag}
+	for name, data := range files {
+		f.release.Assets = append(f.release.Assets, Asset{
+			Name:        name,
+			Size:        int64(len(data)),
+			DownloadURL: f.URL + "/download/" + name,
+			Digest:      digests[name],
+		})
+	}
+	mux.HandleFunc("/releases", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode([]ReleaseInfo{f.release})
+	})
+	mux.HandleFunc("/releases/latest", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(f.release)
+	})
+	mux.HandleFunc("/download/{name}", func(w http.ResponseWriter, r *http.Request) {
+		data, ok := f.files[r.PathValue("name")]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(data)
+	})
+	return f
+}
+
+func (f *fakeReleases) builds(t *testing.T, goos, goarch string) *Builds {
+	return &Builds{Dir: t.TempDir(), Client: &Client{Source: Source{Kind: SourceGitHub, Location: f.URL}, HTTP: f.Client()}, GOOS: goos, GOARCH: goarch}
+}
+
+func sum(data []byte) string {
+	h := sha256.Sum256(data)
+	return hex.EncodeToString(h[:])
+}
+
+func TestInstallVerifiesChecksumFile(t *testing.T) {
+	binary := []byte("#!/bin/sh\necho FrankenPHP v1.9.0\n")
+	other := []byte("gnu build")
+	fake := newFakeReleases(t, "v1.9.0", map[string][]byte{
+		"frankenphp-linux-x86_64":     binary,
+		"frankenphp-linux-x86_64-gnu": other,
+		"frankenphp-mac-arm64":        []byte("mac"),
+		"checksums.txt":               []byte(fmt.Sprintf("%s  frankenphp-linux-x86_64\n%s  frankenphp-linux-x86_64-gnu\n", sum(binary), sum(other))),
+	}, nil)
+	builds := fake.builds(t, "linux", "amd64")
+
+	releases, err := builds.Client.Releases(context.Background())
+	if err != nil || len(releases) != 1 {
+		t.Fatalf("releases: %v %v", releases, err)
+	}
+	var progressed int64
+	build, err := builds.Install(context.Background(), releases[0], func(done, total int64) { progressed = done })
+	if err != nil {
+		t.Fatalf("install: %v", err)
+	}
+	if build.Version != "v1.9.0" || progressed != int64(len(binary)) {
+		t.Fatalf("unexpected build %+v after %d bytes", build, progressed)
+	}
+	data, err := os.ReadFile(build.Path)
+	if err != nil || !bytes.Equal(data, binary) {
+		t.Fatalf("installed binary differs: %v", err)
+	}
+	if st, _ := os.Stat(build.Path); st.Mode().Perm()&0100 == 0 {
+		t.Fatalf("binary is not executable: %v", st.Mode())
+	}
+
+	installed := builds.Installed()
+	if len(installed) != 1 || installed[0] != build {
+		t.Fatalf("unexpected installed builds: %+v", installed)
+	}
+	if err := builds.Remove("v1.9.0"); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	if len(builds.Installed()) != 0 {
+		t.Fatalf("build still installed after remove")
+	}
+}
+
+func TestInstallRejectsChecksumMismatch(t *testing.T) {
+	fake := newFakeReleases(t, "v1.9.0", map[string][]byte{
+		"frankenphp-mac-arm64": []byte("tampered"),
+	}, map[string]string{
+		"frankenphp-mac-arm64": "sha256:" + sum([]byte("original")),
+	})
+	builds := fake.builds(t, "darwin", "arm64")
+
+	if _, err := builds.Install(context.Background(), fake.release, nil); !errors.Is(err, ErrChecksumMismatch) {
+		t.Fatalf("expected ErrChecksumMismatch, got %v", err)
+	}
+	entries, _ := os.ReadDir(builds.Dir)
+	if len(entries) != 0 {
+		t.Fatalf("failed download left files behind: %v", entries)
+	}
+}
+
+func TestInstallRequiresChecksum(t *testing.T) {
+	fake := newFakeReleases(t, "v1.9.0", map[string][]byte{"frankenphp-linux-aarch64": []byte("bin")}, nil)
+	if _, err := fake.builds(t, "linux", "arm64").Install(context.Background(), fake.release, nil); !errors.Is(err, ErrNoChecksum) {
+		t.Fatalf("expected ErrNoChecksum, got %v", err)
+	}
+	if _, err := fake.builds(t, "linux", "riscv64").Install(context.Background(), fake.release, nil); !errors.Is(err, ErrNoAsset) {
+		t.Fatalf("expected ErrNoAsset, got %v", err)
+	}
+}
+
+func TestInstallExtractsZip(t *testing.T) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range map[string]string{"frankenphp/" + runner.ManagedBinaryName(): "exe", "frankenphp/php8ts.dll": "dll"} {
+		w, _ := zw.Create(name)
+		w.Write([]byte(content))
+	}
+	zw.Close()
+	archive := buf.Bytes()
+
+	fake := newFakeReleases(t, "v1.9.0", map[string][]byte{
+		"frankenphp-windows-x86_64.zip":        archive,
+		"frankenphp-windows-x86_64.zip.sha256": []byte(sum(archive) + "  frankenphp-windows-x86_64.zip\n"),
+	}, nil)
+	build, err := fake.builds(t, "windows", "amd64").Install(context.Background(), fake.release, nil)
+	if err != nil {
+		t.Fatalf("install: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(build.Path), "php8ts.dll")); err != nil {
+		t.Fatalf("libraries next to the binary were not extracted: %v", err)
+	}
+}
+
+func TestInstallRejectsCollidingZipEntries(t *testing.T) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, entry := range []struct{ name, content string }{
+		{"frankenphp/" + runner.ManagedBinaryName(), "exe"},
+		{"frankenphp/extras/" + runner.ManagedBinaryName(), "other"},
+	} {
+		w, _ := zw.Create(entry.name)
+		w.Write([]byte(entry.content))
+	}
+	zw.Close()
+	archive := buf.Bytes()
+
+	fake := newFakeReleases(t, "v1.9.0", map[string][]byte{
+		"frankenphp-windows-x86_64.zip":        archive,
+		"frankenphp-windows-x86_64.zip.sha256": []byte(sum(archive) + "  frankenphp-windows-x86_64.zip\n"),
+	}, nil)
+	builds := fake.builds(t, "windows", "amd64")
+	if _, err := builds.Install(context.Background(), fake.release, nil); !errors.Is(err, ErrArchiveCollision) {
+		t.Fatalf("expected ErrArchiveCollision, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(builds.Dir, "v1.9.0")); !os.IsNotExist(err) {
+		t.Fatalf("expected nothing to be installed, got %v", err)
+	}
+}
+
+func TestPlatformAssetPrefersStaticBuild(t *testing.T) {
+	release := ReleaseInfo{TagName: "v1.9.0", Assets: []Asset{
+		{Name: "frankenphp-linux-x86_64-gnu"},
+		{Name: "frankenphp-linux-x86_64"},
+		{Name: "frankenphp-linux-x86_64.sha256"},
+	}}
+	asset, err := PlatformAsset(release, "linux", "amd64")
+	if err != nil || asset.Name != "frankenphp-linux-x86_64" {
+		t.Fatalf("unexpected asset %+v: %v", asset, err)
+	}
+}
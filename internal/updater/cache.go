@@ -0,0 +1,73 @@
+package updater
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+)
+
+// Cache keeps release list responses on disk so later checks revalidate them
+// with If-None-Match and If-Modified-Since instead of downloading them again.
+// A 304 Not Modified answer does not count against GitHub's rate limit.
+type Cache struct {
+	Dir string
+}
+
+// NewCache returns the cache in the user cache directory.
+func NewCache() (*Cache, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return nil, err
+	}
+	return &Cache{Dir: filepath.Join(dir, "frago", "releases")}, nil
+}
+
+type cacheEntry struct {
+	URL          string          `json:"url"`
+	ETag         string          `json:"etag,omitempty"`
+	LastModified string          `json:"last_modified,omitempty"`
+	Body         json.RawMessage `json:"body"`
+}
+
+func (c *Cache) path(url string) string {
+	h := sha256.Sum256([]byte(url))
+	return filepath.Join(c.Dir, hex.EncodeToString(h[:8])+".json")
+}
+
+// load returns the cached response for url, or nil.
+func (c *Cache) load(url string) *cacheEntry {
+	data, err := os.ReadFile(c.path(url))
+	if err != nil {
+		return nil
+	}
+	var entry cacheEntry
+	if json.Unmarshal(data, &entry) != nil || entry.URL != url || len(entry.Body) == 0 {
+		return nil
+	}
+	return &entry
+}
+
+// store saves entry, replacing the file atomically. Failures are ignored;
+// the next check simply downloads the list again.
+func (c *Cache) store(entry cacheEntry) {
+	if entry.ETag == "" && entry.LastModified == "" {
+		return
+	}
+	data, err := json.Marshal(entry)
+	if err != nil || os.MkdirAll(c.Dir, 0755) != nil {
+		return
+	}
+	f, err := os.CreateTemp(c.Dir, ".entry-*")
+	if err != nil {
+		return
+	}
+	_, err = f.Write(data)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil || os.Rename(f.Name(), c.path(entry.URL)) != nil {
+		os.Remove(f.Name())
+	}
+}
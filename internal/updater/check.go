@@ -0,0 +1,99 @@
+package updater
+
+import (
+	"context"
+	"slices"
+
+	"github.com/devmarvs/frago/internal/runner"
+)
+
+// Channel selects which releases an update check offers.
+type Channel string
+
+const (
+	// ChannelStable offers releases that are not marked as pre-releases.
+	ChannelStable Channel = "stable"
+	// ChannelPrerelease also offers release candidates and betas.
+	ChannelPrerelease Channel = "prerelease"
+)
+
+// CheckOptions configures an update check.
+type CheckOptions struct {
+	Channel Channel
+	// Skip lists release tags the user chose to skip; they are never
+	// offered, but a later release still is.
+	Skip []string
+}
+
+// Update is the result of an update check for one binary.
+type Update struct {
+	Path  string
+	Label string
+	// Current is the FrankenPHP version the binary reports. It is empty when
+	// the version could not be read, and Err says why.
+	Current string
+	Err     error
+	// Release is the newest release offered for the binary, or nil when it
+	// is up to date.
+	Release *ReleaseInfo
+}
+
+// Check fetches the releases once and compares each binary's version with
+// the newest release on the channel in opts. A binary whose version cannot
+// be read is reported with Err rather than as out of date.
+func (c *Client) Check(ctx context.Context, binaries []runner.PHPVersion, opts CheckOptions) ([]Update, error) {
+	releases, err := c.Releases(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	var updates []Update
+	for _, b := range binaries {
+		if b.Path == "" || seen[b.Path] {
+			continue
+		}
+		seen[b.Path] = true
+
+		u := Update{Path: b.Path, Label: b.Label}
+		u.Current, u.Err = runner.GetFrankenPHPVersion(b.Path)
+		if u.Err == nil {
+			u.Release, u.Err = Newer(u.Current, releases, opts)
+		}
+		if u.Err != nil {
+			u.Current = ""
+		}
+		updates = append(updates, u)
+	}
+	return updates, nil
+}
+
+// Newer returns the newest release on the channel in opts that is newer than
+// current, or nil when there is none. Drafts, skipped tags and tags that are
+// not semantic versions are ignored.
+func Newer(current string, releases []ReleaseInfo, opts CheckOptions) (*ReleaseInfo, error) {
+	have, err := ParseVersion(current)
+	if err != nil {
+		return nil, err
+	}
+
+	var newest *ReleaseInfo
+	var newestVersion Version
+	for i := range releases {
+		r := &releases[i]
+		if r.Draft || slices.Contains(opts.Skip, r.TagName) {
+			continue
+		}
+		v, err := ParseVersion(r.TagName)
+		if err != nil {
+			continue
+		}
+		if (r.Prerelease || v.Prerelease()) && opts.Channel != ChannelPrerelease {
+			continue
+		}
+		if v.Compare(have) > 0 && (newest == nil || v.Compare(newestVersion) > 0) {
+			newest, newestVersion = r, v
+		}
+	}
+	return newest, nil
+}
@@ -0,0 +1,118 @@
+package updater
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/devmarvs/frago/internal/runner"
+)
+
+func TestCompareVersions(t *testing.T) {
+	ordered := []string{
+		"v1.0.0-alpha", "v1.0.0-alpha.1", "v1.0.0-alpha.beta", "v1.0.0-beta",
+		"v1.0.0-beta.2", "v1.0.0-beta.11", "v1.0.0-rc.1", "v1.0.0", "1.0.1", "v1.9.0", "v1.10.0",
+	}
+	for i := 0; i < len(ordered)-1; i++ {
+		a, err := ParseVersion(ordered[i])
+		if err != nil {
+			t.Fatalf("parse %s: %v", ordered[i], err)
+		}
+		b, err := ParseVersion(ordered[i+1])
+		if err != nil {
+			t.Fatalf("parse %s: %v", ordered[i+1], err)
+		}
+		if a.Compare(b) != -1 || b.Compare(a) != 1 || a.Compare(a) != 0 {
+			t.Fatalf("expected %s < %s", a, b)
+		}
+	}
+
+	v, err := ParseVersion("v1.2.3+build.5")
+	if err != nil || v.String() != "v1.2.3" {
+		t.Fatalf("build metadata not ignored: %v %v", v, err)
+	}
+	for _, bad := range []string{"", "dev", "v1.2", "v1.02.3", "v1.2.3-", "v1.2.3-rc..1"} {
+		if _, err := ParseVersion(bad); err == nil {
+			t.Fatalf("expected %q to be rejected", bad)
+		}
+	}
+}
+
+func TestNewerHonorsChannelAndSkip(t *testing.T) {
+	releases := []ReleaseInfo{
+		{TagName: "v1.11.0-rc.1", Prerelease: true},
+		{TagName: "v1.10.0"},
+		{TagName: "v1.12.0", Draft: true},
+		{TagName: "nightly"},
+		{TagName: "v1.9.1"},
+	}
+	tests := []struct {
+		current string
+		opts    CheckOptions
+		want    string
+	}{
+		{"v1.9.1", CheckOptions{Channel: ChannelStable}, "v1.10.0"},
+		{"v1.9.1", CheckOptions{Channel: ChannelPrerelease}, "v1.11.0-rc.1"},
+		{"v1.9.1", CheckOptions{Channel: ChannelStable, Skip: []string{"v1.10.0"}}, ""},
+		{"v1.10.0", CheckOptions{Channel: ChannelStable}, ""},
+		// A newer local pre-release is not an update to the stable release.
+		{"v1.11.0-rc.2", CheckOptions{Channel: ChannelPrerelease}, ""},
+		{"v1.10.0-rc.1", CheckOptions{Channel: ChannelStable}, "v1.10.0"},
+	}
+	for _, tt := range tests {
+		got, err := Newer(tt.current, releases, tt.opts)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.current, err)
+		}
+		tag := ""
+		if got != nil {
+			tag = got.TagName
+		}
+		if tag != tt.want {
+			t.Fatalf("%s %+v: expected %q, got %q", tt.current, tt.opts, tt.want, tag)
+		}
+	}
+	if _, err := Newer("unknown", releases, CheckOptions{}); err == nil {
+		t.Fatalf("expected an unparseable version to fail")
+	}
+}
+
+func TestCheckReportsEachBinary(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses shell scripts as FrankenPHP binaries")
+	}
+	dir := t.TempDir()
+	script := func(name, output string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte("#!/bin/sh\necho '"+output+"'\n"), 0755); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	old := script("old", "FrankenPHP v1.8.0 PHP 8.3.3 Caddy v2.9.1")
+	current := script("current", "FrankenPHP v1.9.0 PHP 8.4.1 Caddy v2.10.0")
+	dev := script("dev", "FrankenPHP dev PHP 8.4.1 Caddy v2.10.0")
+
+	fake := newFakeReleases(t, "v1.9.0", nil, nil)
+	builds := fake.builds(t, "linux", "amd64")
+	updates, err := builds.Client.Check(context.Background(), []runner.PHPVersion{
+		{Path: old, Label: "old"}, {Path: current, Label: "current"}, {Path: dev, Label: "dev"}, {Path: old, Label: "duplicate"},
+	}, CheckOptions{Channel: ChannelStable})
+	if err != nil {
+		t.Fatalf("check: %v", err)
+	}
+	if len(updates) != 3 {
+		t.Fatalf("expected one result per binary, got %+v", updates)
+	}
+	if u := updates[0]; u.Current != "v1.8.0" || u.Release == nil || u.Release.TagName != "v1.9.0" {
+		t.Fatalf("expected an update for the old binary, got %+v", u)
+	}
+	if u := updates[1]; u.Current != "v1.9.0" || u.Release != nil || u.Err != nil {
+		t.Fatalf("expected the current binary to be up to date, got %+v", u)
+	}
+	if u := updates[2]; u.Err == nil || u.Release != nil {
+		t.Fatalf("expected an unknown version to be reported as an error, got %+v", u)
+	}
+}
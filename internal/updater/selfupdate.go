@@ -0,0 +1,232 @@
+package updater
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// FragoAPI is the GitHub API base URL of the Frago repository.
+const FragoAPI = "https://api.github.com/repos/devmarvs/frago"
+
+// appSourceEnv overrides the source of Frago releases, in the format read by
+// ParseSource.
+const appSourceEnv = "FRAGO_APP_RELEASE_SOURCE"
+
+// ErrDevelopmentBuild indicates Frago was built without a release version,
+// so there is nothing to compare releases with.
+var ErrDevelopmentBuild = errors.New("development builds of Frago are not updated")
+
+// ErrNoBackup indicates there is no previous Frago binary to roll back to.
+var ErrNoBackup = errors.New("no previous version of Frago to roll back to")
+
+// verifyTimeout bounds the trial run of a downloaded binary.
+const verifyTimeout = 10 * time.Second
+
+// SelfUpdate replaces the running Frago executable with a release. The
+// replaced binary is kept next to it so the update can be rolled back.
+type SelfUpdate struct {
+	Client *Client
+	// Current is the running version, such as "v0.4.0".
+	Current string
+	// Executable is the binary to replace.
+	Executable string
+	// GOOS and GOARCH select the release asset; they default to the
+	// platform Frago runs on.
+	GOOS, GOARCH string
+}
+
+// NewSelfUpdate returns an updater for the running executable, reading
+// releases from FRAGO_APP_RELEASE_SOURCE or the Frago repository.
+func NewSelfUpdate(current string) (*SelfUpdate, error) {
+	src := Source{Kind: SourceGitHub, Location: FragoAPI}
+	if env := os.Getenv(appSourceEnv); env != "" {
+		var err error
+		if src, err = ParseSource(env); err != nil {
+			return nil, err
+		}
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		return nil, err
+	}
+	if resolved, err := filepath.EvalSymlinks(exe); err == nil {
+		exe = resolved
+	}
+	return &SelfUpdate{Client: NewClientFor(src), Current: current, Executable: exe}, nil
+}
+
+// Check returns the newest release on the channel in opts that is newer than
+// the running version, or nil when Frago is up to date.
+func (u *SelfUpdate) Check(ctx context.Context, opts CheckOptions) (*ReleaseInfo, error) {
+	if _, err := ParseVersion(u.Current); err != nil {
+		return nil, ErrDevelopmentBuild
+	}
+	releases, err := u.Client.Releases(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return Newer(u.Current, releases, opts)
+}
+
+// BackupPath is where the replaced binary is kept after an update.
+func (u *SelfUpdate) BackupPath() string {
+	return u.Executable + ".old"
+}
+
+// CanRollback reports whether a previous binary is available.
+func (u *SelfUpdate) CanRollback() bool {
+	st, err := os.Stat(u.BackupPath())
+	return err == nil && st.Mode().IsRegular()
+}
+
+// Apply downloads the Frago binary of release, verifies its checksum, checks
+// that it runs, and swaps it in for the executable. The previous binary
+// becomes BackupPath. The running process keeps using the old binary until
+// it restarts.
+func (u *SelfUpdate) Apply(ctx context.Context, release ReleaseInfo, progress Progress) error {
+	asset, err := fragoAsset(release, u.goos(), u.goarch())
+	if err != nil {
+		return err
+	}
+	want, err := u.Client.checksum(ctx, release, asset)
+	if err != nil {
+		return err
+	}
+
+	// Stage next to the executable so the final rename stays on one file
+	// system.
+	staging, err := os.MkdirTemp(filepath.Dir(u.Executable), ".frago-update-")
+	if err != nil {
+		return fmt.Errorf("cannot write next to %s: %w", u.Executable, err)
+	}
+	defer os.RemoveAll(staging)
+
+	download := filepath.Join(staging, "asset")
+	if err := u.Client.download(ctx, asset, download, want, progress); err != nil {
+		return err
+	}
+	binary := download
+	if strings.HasSuffix(strings.ToLower(asset.Name), ".zip") {
+		if err := extractZip(download, staging); err != nil {
+			return err
+		}
+		binary = filepath.Join(staging, fragoBinaryName(u.goos()))
+		if st, err := os.Stat(binary); err != nil || st.IsDir() {
+			return fmt.Errorf("%s does not contain %s", asset.Name, fragoBinaryName(u.goos()))
+		}
+	}
+
+	mode := os.FileMode(0755)
+	if st, err := os.Stat(u.Executable); err == nil {
+		mode = st.Mode().Perm() | 0111
+	}
+	if err := os.Chmod(binary, mode); err != nil {
+		return err
+	}
+	if u.goos() == runtime.GOOS && u.goarch() == runtime.GOARCH {
+		if err := verifyBinary(ctx, binary); err != nil {
+			return err
+		}
+	}
+	return swap(binary, u.Executable, u.BackupPath())
+}
+
+// Rollback restores the binary replaced by the last update. The running
+// process keeps using the current binary until it restarts.
+func (u *SelfUpdate) Rollback() error {
+	if !u.CanRollback() {
+		return ErrNoBackup
+	}
+	if err := os.Rename(u.BackupPath(), u.Executable); err == nil {
+		return nil
+	}
+	// Windows cannot replace a running executable, but it can move it.
+	moved := u.Executable + ".rolledback"
+	os.Remove(moved)
+	if err := os.Rename(u.Executable, moved); err != nil {
+		return err
+	}
+	if err := os.Rename(u.BackupPath(), u.Executable); err != nil {
+		os.Rename(moved, u.Executable)
+		return err
+	}
+	return nil
+}
+
+// swap replaces exe with next and keeps the old binary as backup. Where hard
+// links work, exe is replaced in one rename; otherwise it is moved to backup
+// first and moved back if next cannot take its place.
+func swap(next, exe, backup string) error {
+	if err := os.Remove(backup); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if err := os.Link(exe, backup); err == nil {
+		if err := os.Rename(next, exe); err != nil {
+			os.Remove(backup)
+			return err
+		}
+		return nil
+	}
+	if err := os.Rename(exe, backup); err != nil {
+		return err
+	}
+	if err := os.Rename(next, exe); err != nil {
+		if rollbackErr := os.Rename(backup, exe); rollbackErr != nil {
+			return fmt.Errorf("%w (restoring %s failed: %v)", err, exe, rollbackErr)
+		}
+		return err
+	}
+	return nil
+}
+
+// verifyBinary runs "frago version" with the downloaded binary so a build
+// that cannot start on this system is never swapped in.
+func verifyBinary(ctx context.Context, path string) error {
+	ctx, cancel := context.WithTimeout(ctx, verifyTimeout)
+	defer cancel()
+	if out, err := exec.CommandContext(ctx, path, "version").CombinedOutput(); err != nil {
+		return fmt.Errorf("downloaded Frago does not run: %v: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// fragoAsset returns the Frago release asset for goos and goarch, named
+// "frago-<goos>-<goarch>" with an optional ".exe" or ".zip" suffix.
+func fragoAsset(release ReleaseInfo, goos, goarch string) (Asset, error) {
+	prefix := fmt.Sprintf("frago-%s-%s", goos, goarch)
+	for _, a := range release.Assets {
+		name := strings.ToLower(a.Name)
+		if name == prefix || name == prefix+".exe" || name == prefix+".zip" {
+			return a, nil
+		}
+	}
+	return Asset{}, fmt.Errorf("no Frago build for %s/%s in %s", goos, goarch, release.TagName)
+}
+
+func fragoBinaryName(goos string) string {
+	if goos == "windows" {
+		return "frago.exe"
+	}
+	return "frago"
+}
+
+func (u *SelfUpdate) goos() string {
+	if u.GOOS != "" {
+		return u.GOOS
+	}
+	return runtime.GOOS
+}
+
+func (u *SelfUpdate) goarch() string {
+	if u.GOARCH != "" {
+		return u.GOARCH
+	}
+	return runtime.GOARCH
+}
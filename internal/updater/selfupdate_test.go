@@ -0,0 +1,85 @@
+package updater
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestSelfUpdateSwapsAndRollsBack(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses shell scripts as Frago binaries")
+	}
+	asset := fmt.Sprintf("frago-%s-%s", runtime.GOOS, runtime.GOARCH)
+	next := []byte("#!/bin/sh\necho Frago v0.2.0\n")
+	fake := newFakeReleases(t, "v0.2.0", map[string][]byte{asset: next}, map[string]string{asset: "sha256:" + sum(next)})
+
+	exe := filepath.Join(t.TempDir(), "frago")
+	if err := os.WriteFile(exe, []byte("#!/bin/sh\necho Frago v0.1.0\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	u := &SelfUpdate{Client: fake.builds(t, "", "").Client, Current: "v0.1.0", Executable: exe}
+
+	release, err := u.Check(context.Background(), CheckOptions{Channel: ChannelStable})
+	if err != nil || release == nil || release.TagName != "v0.2.0" {
+		t.Fatalf("expected v0.2.0 to be offered, got %+v %v", release, err)
+	}
+	if err := u.Apply(context.Background(), *release, nil); err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+	if data, _ := os.ReadFile(exe); string(data) != string(next) {
+		t.Fatalf("executable was not replaced: %q", data)
+	}
+	if !u.CanRollback() {
+		t.Fatalf("expected the previous binary to be kept")
+	}
+	entries, _ := os.ReadDir(filepath.Dir(exe))
+	if len(entries) != 2 {
+		t.Fatalf("expected only the binary and its backup, got %v", entries)
+	}
+
+	if err := u.Rollback(); err != nil {
+		t.Fatalf("rollback: %v", err)
+	}
+	if data, _ := os.ReadFile(exe); string(data) != "#!/bin/sh\necho Frago v0.1.0\n" {
+		t.Fatalf("rollback did not restore the previous binary: %q", data)
+	}
+	if err := u.Rollback(); !errors.Is(err, ErrNoBackup) {
+		t.Fatalf("expected ErrNoBackup, got %v", err)
+	}
+}
+
+func TestSelfUpdateKeepsBinaryOnFailure(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses shell scripts as Frago binaries")
+	}
+	asset := fmt.Sprintf("frago-%s-%s", runtime.GOOS, runtime.GOARCH)
+	broken := []byte("#!/bin/sh\nexit 1\n")
+	fake := newFakeReleases(t, "v0.2.0", map[string][]byte{asset: broken}, map[string]string{asset: "sha256:" + sum(broken)})
+
+	exe := filepath.Join(t.TempDir(), "frago")
+	original := []byte("#!/bin/sh\necho Frago v0.1.0\n")
+	if err := os.WriteFile(exe, original, 0755); err != nil {
+		t.Fatal(err)
+	}
+	u := &SelfUpdate{Client: fake.builds(t, "", "").Client, Current: "v0.1.0", Executable: exe}
+	if err := u.Apply(context.Background(), fake.release, nil); err == nil {
+		t.Fatalf("expected a binary that does not run to be rejected")
+	}
+	if data, _ := os.ReadFile(exe); string(data) != string(original) {
+		t.Fatalf("executable changed after a failed update: %q", data)
+	}
+	entries, _ := os.ReadDir(filepath.Dir(exe))
+	if len(entries) != 1 || u.CanRollback() {
+		t.Fatalf("failed update left files behind: %v", entries)
+	}
+
+	u.Current = "dev"
+	if _, err := u.Check(context.Background(), CheckOptions{}); !errors.Is(err, ErrDevelopmentBuild) {
+		t.Fatalf("expected ErrDevelopmentBuild, got %v", err)
+	}
+}
@@ -0,0 +1,118 @@
+package updater
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Version is a semantic version such as "v1.9.0" or "1.10.0-rc.1".
+type Version struct {
+	Major, Minor, Patch int
+	// Pre holds the dot-separated pre-release identifiers, if any.
+	Pre []string
+}
+
+// ParseVersion parses a semantic version with an optional "v" prefix. Build
+// metadata after "+" is ignored, as it does not affect precedence.
+func ParseVersion(s string) (Version, error) {
+	text := strings.TrimPrefix(strings.TrimSpace(s), "v")
+	text, _, _ = strings.Cut(text, "+")
+	core, pre, hasPre := strings.Cut(text, "-")
+
+	parts := strings.Split(core, ".")
+	if len(parts) != 3 {
+		return Version{}, fmt.Errorf("invalid version %q", s)
+	}
+	var nums [3]int
+	for i, p := range parts {
+		n, err := parseNumber(p)
+		if err != nil {
+			return Version{}, fmt.Errorf("invalid version %q", s)
+		}
+		nums[i] = n
+	}
+
+	v := Version{Major: nums[0], Minor: nums[1], Patch: nums[2]}
+	if hasPre {
+		v.Pre = strings.Split(pre, ".")
+		for _, id := range v.Pre {
+			if id == "" {
+				return Version{}, fmt.Errorf("invalid version %q", s)
+			}
+		}
+	}
+	return v, nil
+}
+
+// parseNumber parses a numeric identifier, which has no sign or leading zeros.
+func parseNumber(s string) (int, error) {
+	if s == "" || (len(s) > 1 && s[0] == '0') || strings.TrimLeft(s, "0123456789") != "" {
+		return 0, fmt.Errorf("invalid number %q", s)
+	}
+	return strconv.Atoi(s)
+}
+
+// Prerelease reports whether v has pre-release identifiers.
+func (v Version) Prerelease() bool {
+	return len(v.Pre) > 0
+}
+
+func (v Version) String() string {
+	s := fmt.Sprintf("v%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if v.Prerelease() {
+		s += "-" + strings.Join(v.Pre, ".")
+	}
+	return s
+}
+
+// Compare returns -1, 0 or 1 when v has lower, equal or higher precedence
+// than w, following the semantic versioning rules: a pre-release sorts
+// before its release, and identifiers compare numerically when both are
+// numbers.
+func (v Version) Compare(w Version) int {
+	for _, d := range [][2]int{{v.Major, w.Major}, {v.Minor, w.Minor}, {v.Patch, w.Patch}} {
+		if c := compareInt(d[0], d[1]); c != 0 {
+			return c
+		}
+	}
+	switch {
+	case !v.Prerelease() && !w.Prerelease():
+		return 0
+	case !v.Prerelease():
+		return 1
+	case !w.Prerelease():
+		return -1
+	}
+	for i := 0; i < len(v.Pre) && i < len(w.Pre); i++ {
+		if c := compareIdentifier(v.Pre[i], w.Pre[i]); c != 0 {
+			return c
+		}
+	}
+	return compareInt(len(v.Pre), len(w.Pre))
+}
+
+func compareIdentifier(a, b string) int {
+	an, aErr := parseNumber(a)
+	bn, bErr := parseNumber(b)
+	switch {
+	case aErr == nil && bErr == nil:
+		return compareInt(an, bn)
+	case aErr == nil:
+		// Numeric identifiers sort before alphanumeric ones.
+		return -1
+	case bErr == nil:
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+func compareInt(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,176 @@
+package updater
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// SourceKind is the kind of endpoint releases are read from.
+type SourceKind string
+
+const (
+	// SourceGitHub reads a GitHub-compatible releases API, such as a GitHub
+	// Enterprise mirror.
+	SourceGitHub SourceKind = "github"
+	// SourceFeed reads a JSON array of releases in the GitHub format from a
+	// URL. Relative asset URLs are resolved against it.
+	SourceFeed SourceKind = "feed"
+	// SourceDir reads a local directory with one subdirectory per release
+	// tag holding that release's assets.
+	SourceDir SourceKind = "dir"
+)
+
+// Source is where releases are read from.
+type Source struct {
+	Kind SourceKind
+	// Location is the API URL, feed URL or directory.
+	Location string
+}
+
+// DefaultSource is the official FrankenPHP GitHub repository.
+var DefaultSource = Source{Kind: SourceGitHub, Location: GitHubAPI}
+
+// sourceEnv overrides DefaultSource for SourceFromEnv.
+const sourceEnv = "FRAGO_RELEASE_SOURCE"
+
+// ParseSource parses a source written as "KIND:LOCATION", such as
+// "feed:https://mirror.example.com/frankenphp.json" or "dir:/srv/frankenphp".
+// An empty string or "github" alone is DefaultSource.
+func ParseSource(s string) (Source, error) {
+	s = strings.TrimSpace(s)
+	if s == "" || s == string(SourceGitHub) {
+		return DefaultSource, nil
+	}
+	kind, location, ok := strings.Cut(s, ":")
+	location = strings.TrimSpace(location)
+	if !ok || location == "" {
+		return Source{}, fmt.Errorf("invalid release source %q: expected KIND:LOCATION", s)
+	}
+	src := Source{Kind: SourceKind(kind), Location: location}
+	switch src.Kind {
+	case SourceGitHub, SourceFeed:
+		u, err := url.Parse(location)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return Source{}, fmt.Errorf("invalid release source %q: expected an http(s) URL", s)
+		}
+		src.Location = strings.TrimSuffix(location, "/")
+	case SourceDir:
+		abs, err := filepath.Abs(location)
+		if err != nil {
+			return Source{}, err
+		}
+		src.Location = abs
+	default:
+		return Source{}, fmt.Errorf("invalid release source %q: kind must be github, feed or dir", s)
+	}
+	return src, nil
+}
+
+// SourceFromEnv returns the source set in FRAGO_RELEASE_SOURCE, or
+// DefaultSource when it is unset.
+func SourceFromEnv() (Source, error) {
+	return ParseSource(os.Getenv(sourceEnv))
+}
+
+func (s Source) String() string {
+	if s == DefaultSource {
+		return string(SourceGitHub)
+	}
+	return string(s.Kind) + ":" + s.Location
+}
+
+// dirReleases lists the releases in a SourceDir directory, newest first.
+// Asset URLs use the file scheme relative to the directory, which the
+// client's file transport serves.
+func dirReleases(dir string) ([]ReleaseInfo, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read releases: %w", err)
+	}
+	var releases []ReleaseInfo
+	for _, entry := range entries {
+		tag := entry.Name()
+		if !entry.IsDir() || !validTag(tag) {
+			continue
+		}
+		files, err := os.ReadDir(filepath.Join(dir, tag))
+		if err != nil {
+			continue
+		}
+		page := filepath.ToSlash(filepath.Join(dir, tag))
+		if !strings.HasPrefix(page, "/") {
+			// Windows paths start with a drive letter.
+			page = "/" + page
+		}
+		release := ReleaseInfo{TagName: tag, HtmlUrl: (&url.URL{Scheme: "file", Path: page}).String()}
+		if v, err := ParseVersion(tag); err == nil {
+			release.Prerelease = v.Prerelease()
+		}
+		for _, f := range files {
+			info, err := f.Info()
+			if err != nil || !info.Mode().IsRegular() {
+				continue
+			}
+			release.Assets = append(release.Assets, Asset{
+				Name:        f.Name(),
+				Size:        info.Size(),
+				DownloadURL: (&url.URL{Scheme: "file", Path: "/" + tag + "/" + f.Name()}).String(),
+			})
+		}
+		releases = append(releases, release)
+	}
+	sortReleases(releases)
+	return releases, nil
+}
+
+// sortReleases orders releases newest first. Tags that are not semantic
+// versions sort last, by name.
+func sortReleases(releases []ReleaseInfo) {
+	slices.SortStableFunc(releases, func(a, b ReleaseInfo) int {
+		av, aErr := ParseVersion(a.TagName)
+		bv, bErr := ParseVersion(b.TagName)
+		switch {
+		case aErr == nil && bErr == nil:
+			return bv.Compare(av)
+		case aErr == nil:
+			return -1
+		case bErr == nil:
+			return 1
+		}
+		return strings.Compare(a.TagName, b.TagName)
+	})
+}
+
+// resolveAssets makes the asset URLs of releases read from a feed at base
+// absolute.
+func resolveAssets(releases []ReleaseInfo, base string) {
+	baseURL, err := url.Parse(base)
+	if err != nil {
+		return
+	}
+	for i := range releases {
+		for j := range releases[i].Assets {
+			a := &releases[i].Assets[j]
+			if ref, err := url.Parse(a.DownloadURL); err == nil {
+				a.DownloadURL = baseURL.ResolveReference(ref).String()
+			}
+		}
+	}
+}
+
+// newHTTPClient returns the HTTP client for src. Directory sources are
+// served through a file transport rooted at the directory. The client has no
+// overall timeout so large downloads can finish; release lists are fetched
+// with listTimeout instead.
+func newHTTPClient(src Source) *http.Client {
+	client := &http.Client{}
+	if src.Kind == SourceDir {
+		client.Transport = http.NewFileTransport(http.Dir(src.Location))
+	}
+	return client
+}
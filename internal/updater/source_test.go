@@ -0,0 +1,134 @@
+package updater
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseSource(t *testing.T) {
+	for _, s := range []string{"", "github", " github "} {
+		if src, err := ParseSource(s); err != nil || src != DefaultSource {
+			t.Fatalf("%q: expected the default source, got %+v %v", s, src, err)
+		}
+	}
+	src, err := ParseSource("feed:https://mirror.example.com/frankenphp.json")
+	if err != nil || src.Kind != SourceFeed || src.String() != "feed:https://mirror.example.com/frankenphp.json" {
+		t.Fatalf("unexpected feed source %+v: %v", src, err)
+	}
+	if src, err := ParseSource("github:https://ghe.example.com/api/v3/repos/php/frankenphp/"); err != nil || src.Location != "https://ghe.example.com/api/v3/repos/php/frankenphp" {
+		t.Fatalf("unexpected GitHub source %+v: %v", src, err)
+	}
+	if src, err := ParseSource("dir:/srv/frankenphp"); err != nil || src.Kind != SourceDir || !filepath.IsAbs(src.Location) {
+		t.Fatalf("unexpected dir source %+v: %v", src, err)
+	}
+	for _, bad := range []string{"feed:", "feed:/relative.json", "s3:bucket", "https://example.com"} {
+		if _, err := ParseSource(bad); err == nil {
+			t.Fatalf("expected %q to be rejected", bad)
+		}
+	}
+}
+
+func TestDirSourceInstall(t *testing.T) {
+	mirror := t.TempDir()
+	binary := []byte("binary")
+	for tag, files := range map[string]map[string][]byte{
+		"v1.9.0":       {"frankenphp-linux-x86_64": binary, "frankenphp-linux-x86_64.sha256": []byte(sum(binary) + "\n")},
+		"v1.10.0-rc.1": {"frankenphp-linux-x86_64": binary},
+		".partial":     {"frankenphp-linux-x86_64": binary},
+	} {
+		if err := os.MkdirAll(filepath.Join(mirror, tag), 0755); err != nil {
+			t.Fatal(err)
+		}
+		for name, data := range files {
+			if err := os.WriteFile(filepath.Join(mirror, tag, name), data, 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	client := NewClientFor(Source{Kind: SourceDir, Location: mirror})
+	releases, err := client.Releases(context.Background())
+	if err != nil {
+		t.Fatalf("releases: %v", err)
+	}
+	if len(releases) != 2 || releases[0].TagName != "v1.10.0-rc.1" || !releases[0].Prerelease {
+		t.Fatalf("unexpected releases: %+v", releases)
+	}
+	latest, err := client.Latest(context.Background())
+	if err != nil || latest.TagName != "v1.9.0" {
+		t.Fatalf("unexpected latest release %+v: %v", latest, err)
+	}
+
+	builds := &Builds{Dir: t.TempDir(), Client: client, GOOS: "linux", GOARCH: "amd64"}
+	build, err := builds.Install(context.Background(), *latest, nil)
+	if err != nil {
+		t.Fatalf("install: %v", err)
+	}
+	if data, err := os.ReadFile(build.Path); err != nil || string(data) != "binary" {
+		t.Fatalf("unexpected installed binary %q: %v", data, err)
+	}
+}
+
+func TestFeedSourceIsCachedWithETag(t *testing.T) {
+	requests, notModified := 0, 0
+	rateLimit := false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/feed/releases.json":
+			requests++
+			if rateLimit {
+				w.Header().Set("X-RateLimit-Remaining", "0")
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			if r.Header.Get("If-None-Match") == `"v1"` {
+				notModified++
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			w.Header().Set("ETag", `"v1"`)
+			json.NewEncoder(w).Encode([]ReleaseInfo{
+				{TagName: "v1.9.0", Assets: []Asset{{Name: "frankenphp-linux-x86_64", DownloadURL: "files/frankenphp-linux-x86_64"}}},
+				{TagName: "v1.10.0"},
+			})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	src, err := ParseSource("feed:" + srv.URL + "/feed/releases.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &Client{Source: src, HTTP: srv.Client(), Cache: &Cache{Dir: t.TempDir()}}
+	for i := 0; i < 2; i++ {
+		releases, err := client.Releases(context.Background())
+		if err != nil {
+			t.Fatalf("releases: %v", err)
+		}
+		if len(releases) != 2 || releases[0].TagName != "v1.10.0" {
+			t.Fatalf("expected releases newest first, got %+v", releases)
+		}
+		if url := releases[1].Assets[0].DownloadURL; url != srv.URL+"/feed/files/frankenphp-linux-x86_64" {
+			t.Fatalf("relative asset URL not resolved: %s", url)
+		}
+	}
+	if requests != 2 || notModified != 1 {
+		t.Fatalf("expected the second request to be revalidated, got %d requests and %d 304s", requests, notModified)
+	}
+
+	rateLimit = true
+	if _, err := client.Releases(context.Background()); err != nil {
+		t.Fatalf("expected the cached list while rate limited, got %v", err)
+	}
+	client.Cache = nil
+	if _, err := client.Releases(context.Background()); err == nil {
+		t.Fatalf("expected an error while rate limited without a cache")
+	}
+}
@@ -1,56 +1,209 @@
 package updater
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"time"
 
 	"github.com/devmarvs/frago/internal/runner"
 )
 
+// GitHubAPI is the GitHub API base URL of the FrankenPHP repository.
+const GitHubAPI = "https://api.github.com/repos/php/frankenphp"
+
 type ReleaseInfo struct {
-	TagName string `json:"tag_name"`
-	HtmlUrl string `json:"html_url"`
-	Body    string `json:"body"`
+	TagName    string  `json:"tag_name"`
+	Name       string  `json:"name"`
+	HtmlUrl    string  `json:"html_url"`
+	Body       string  `json:"body"`
+	Prerelease bool    `json:"prerelease"`
+	Draft      bool    `json:"draft"`
+	Assets     []Asset `json:"assets"`
+}
+
+// Asset is a file attached to a release.
+type Asset struct {
+	Name        string `json:"name"`
+	Size        int64  `json:"size"`
+	DownloadURL string `json:"browser_download_url"`
+	// Digest is "sha256:<hex>" when GitHub has computed one.
+	Digest string `json:"digest,omitempty"`
+}
+
+// listTimeout bounds fetching a release list.
+const listTimeout = 10 * time.Second
+
+// Client fetches FrankenPHP releases from a Source.
+type Client struct {
+	Source Source
+	HTTP   *http.Client
+	// Cache, when set, revalidates release lists instead of downloading
+	// them on every check.
+	Cache *Cache
 }
 
-// CheckForUpdates compares the local FrankenPHP version with the latest GitHub release.
-// Returns the release info if an update is available, or nil if up-to-date.
-func CheckForUpdates() (*ReleaseInfo, error) {
-	// 1. Get local version
-	binaryPath := runner.DefaultFrankenPHPBinary()
-	
-	// 2. Fetch latest release from GitHub
-	client := &http.Client{Timeout: 10 * time.Second}
-	resp, err := client.Get("https://api.github.com/repos/php/frankenphp/releases/latest")
+// NewClient returns a client for the source set in FRAGO_RELEASE_SOURCE,
+// the official FrankenPHP releases by default.
+func NewClient() (*Client, error) {
+	src, err := SourceFromEnv()
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch releases: %w", err)
+		return nil, err
 	}
-	defer resp.Body.Close()
+	return NewClientFor(src), nil
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("github api returned status: %s", resp.Status)
+// NewClientFor returns a client for src that caches release lists in the
+// user cache directory when there is one.
+func NewClientFor(src Source) *Client {
+	c := &Client{Source: src, HTTP: newHTTPClient(src)}
+	if src.Kind != SourceDir {
+		c.Cache, _ = NewCache()
+	}
+	return c
+}
+
+// Releases returns the most recent releases, newest first.
+func (c *Client) Releases(ctx context.Context) ([]ReleaseInfo, error) {
+	var releases []ReleaseInfo
+	switch c.Source.Kind {
+	case SourceDir:
+		return dirReleases(c.Source.Location)
+	case SourceFeed:
+		if err := c.getJSON(ctx, c.Source.Location, &releases); err != nil {
+			return nil, err
+		}
+		resolveAssets(releases, c.Source.Location)
+		sortReleases(releases)
+	default:
+		if err := c.getJSON(ctx, c.Source.Location+"/releases", &releases); err != nil {
+			return nil, err
+		}
 	}
+	return releases, nil
+}
 
-	var release ReleaseInfo
-	if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
-		return nil, fmt.Errorf("failed to decode release info: %w", err)
+// Latest returns the latest stable release.
+func (c *Client) Latest(ctx context.Context) (*ReleaseInfo, error) {
+	if c.Source.Kind == SourceGitHub {
+		var release ReleaseInfo
+		if err := c.getJSON(ctx, c.Source.Location+"/releases/latest", &release); err != nil {
+			return nil, err
+		}
+		return &release, nil
 	}
 
-	// 3. Compare versions
-	// FrankenPHP version from binary (e.g., "v1.1.0")
-	localFrankenVer, err := runner.GetFrankenPHPVersion(binaryPath)
+	releases, err := c.Releases(ctx)
 	if err != nil {
-		// Fallback or treat as update available
-		return &release, nil 
+		return nil, err
 	}
+	for i := range releases {
+		v, err := ParseVersion(releases[i].TagName)
+		if err == nil && !v.Prerelease() && !releases[i].Prerelease && !releases[i].Draft {
+			return &releases[i], nil
+		}
+	}
+	return nil, fmt.Errorf("no stable release found in %s", c.Source)
+}
 
-	if localFrankenVer != release.TagName {
-		return &release, nil
+// getJSON decodes the release list at url. With a cache the request is
+// conditional, and the cached list is used when the server answers 304 Not
+// Modified or refuses the request because of rate limiting.
+func (c *Client) getJSON(ctx context.Context, url string, v any) error {
+	ctx, cancel := context.WithTimeout(ctx, listTimeout)
+	defer cancel()
+
+	var cached *cacheEntry
+	if c.Cache != nil {
+		cached = c.Cache.load(url)
+	}
+	req, err := c.newRequest(ctx, url)
+	if err != nil {
+		return fmt.Errorf("failed to fetch releases: %w", err)
+	}
+	if cached != nil {
+		if cached.ETag != "" {
+			req.Header.Set("If-None-Match", cached.ETag)
+		}
+		if cached.LastModified != "" {
+			req.Header.Set("If-Modified-Since", cached.LastModified)
+		}
+	}
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to fetch releases: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var body []byte
+	switch {
+	case resp.StatusCode == http.StatusOK:
+		body, err = io.ReadAll(io.LimitReader(resp.Body, 32<<20))
+		if err != nil {
+			return fmt.Errorf("failed to fetch releases: %w", err)
+		}
+		if c.Cache != nil {
+			c.Cache.store(cacheEntry{
+				URL:          url,
+				ETag:         resp.Header.Get("ETag"),
+				LastModified: resp.Header.Get("Last-Modified"),
+				Body:         body,
+			})
+		}
+	case cached != nil && (resp.StatusCode == http.StatusNotModified || rateLimited(resp)):
+		body = cached.Body
+	default:
+		return fmt.Errorf("failed to fetch releases: %s returned status: %s", url, resp.Status)
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("failed to decode release info: %w", err)
+	}
+	return nil
+}
+
+// rateLimited reports whether resp is a rate limit refusal.
+func rateLimited(resp *http.Response) bool {
+	return resp.StatusCode == http.StatusTooManyRequests ||
+		(resp.StatusCode == http.StatusForbidden && resp.Header.Get("X-RateLimit-Remaining") == "0")
+}
+
+func (c *Client) newRequest(ctx context.Context, url string) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
 	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	return req, nil
+}
 
-	return nil, nil // Up to date
+// get requests url and fails on any status but 200 OK.
+func (c *Client) get(ctx context.Context, url string) (*http.Response, error) {
+	req, err := c.newRequest(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+		resp.Body.Close()
+		return nil, fmt.Errorf("%s returned status: %s", url, resp.Status)
+	}
+	return resp, nil
+}
+
+// CheckForUpdates checks each FrankenPHP binary against the releases from
+// the source set in FRAGO_RELEASE_SOURCE, on the channel in opts.
+func CheckForUpdates(binaries []runner.PHPVersion, opts CheckOptions) ([]Update, error) {
+	client, err := NewClient()
+	if err != nil {
+		return nil, err
+	}
+	return client.Check(context.Background(), binaries, opts)
 }
 
 // OpenUpdatePage opens the release page in browser.
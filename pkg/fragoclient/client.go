@@ -0,0 +1,421 @@
+// Package fragoclient is a typed client for the Frago HTTP API.
+//
+// The request and response types in this package are the ones the server
+// encodes, and the server's OpenAPI document (GET /api/openapi.json) is
+// generated from them, so the three cannot drift apart.
+package fragoclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/devmarvs/frago/internal/ipc"
+)
+
+// localBaseURL is the placeholder host used for requests over the local
+// socket; the transport ignores it and dials the socket instead.
+const localBaseURL = "http://frago.local"
+
+// discoverTimeout bounds each health check Discover makes, so a stale socket
+// or discovery file does not hang it.
+const discoverTimeout = 5 * time.Second
+
+// Error is returned for any non-2xx response.
+type Error struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *Error) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("frago api: http %d", e.StatusCode)
+	}
+	return fmt.Sprintf("frago api: %s (http %d)", e.Message, e.StatusCode)
+}
+
+// IsNotFound reports whether err is an API error with status 404.
+func IsNotFound(err error) bool {
+	var apiErr *Error
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
+
+// Client talks to a running Frago instance. Requests have no timeout of
+// their own and run until their context is done, as starting projects waits
+// up to 30 seconds for each dependency to become ready.
+type Client struct {
+	baseURL string
+	token   string
+	http    *http.Client
+}
+
+// New returns a client for the API at baseURL, for example
+// "http://127.0.0.1:8080". token may be empty when the server does not
+// require one.
+func New(baseURL, token string) *Client {
+	return &Client{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		token:   token,
+		http:    &http.Client{},
+	}
+}
+
+// NewLocal returns a client that connects over the local socket (a Unix
+// socket, or a named pipe on Windows). No token is needed.
+func NewLocal() *Client {
+	transport := &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return ipc.Dial(ctx)
+		},
+	}
+	return &Client{
+		baseURL: localBaseURL,
+		http:    &http.Client{Transport: transport},
+	}
+}
+
+// Discover connects to the running Frago instance, preferring the local
+// socket and falling back to the TCP address and token from the discovery
+// file.
+func Discover(ctx context.Context) (*Client, error) {
+	local := NewLocal()
+	if err := checkHealth(ctx, local); err == nil {
+		return local, nil
+	}
+
+	d, err := ipc.ReadDiscovery()
+	if err != nil {
+		return nil, fmt.Errorf("frago is not running: %w", err)
+	}
+	c := New(d.URL, d.Token)
+	if err := checkHealth(ctx, c); err != nil {
+		return nil, fmt.Errorf("frago is not reachable at %s: %w", d.URL, err)
+	}
+	return c, nil
+}
+
+func checkHealth(ctx context.Context, c *Client) error {
+	ctx, cancel := context.WithTimeout(ctx, discoverTimeout)
+	defer cancel()
+	_, err := c.Health(ctx)
+	return err
+}
+
+// BaseURL returns the address requests are sent to.
+func (c *Client) BaseURL() string {
+	return c.baseURL
+}
+
+// Health checks that the API is up.
+func (c *Client) Health(ctx context.Context) (HealthResponse, error) {
+	var out HealthResponse
+	err := c.do(ctx, http.MethodGet, "/health", nil, &out)
+	return out, err
+}
+
+// Status lists running and saved projects.
+func (c *Client) Status(ctx context.Context) (StatusResponse, error) {
+	var out StatusResponse
+	err := c.do(ctx, http.MethodGet, "/api/status", nil, &out)
+	return out, err
+}
+
+// Run starts FrankenPHP for a project directory.
+func (c *Client) Run(ctx context.Context, req RunRequest) (RunResponse, error) {
+	var out RunResponse
+	err := c.do(ctx, http.MethodPost, "/api/run", req, &out)
+	return out, err
+}
+
+// Stop stops the project running at path.
+func (c *Client) Stop(ctx context.Context, path string) (StopResponse, error) {
+	var out StopResponse
+	err := c.do(ctx, http.MethodPost, "/api/stop", RunRequest{ProjectPath: path}, &out)
+	return out, err
+}
+
+// Restart restarts the saved project at path with its saved settings.
+func (c *Client) Restart(ctx context.Context, path string) (RunResponse, error) {
+	var out RunResponse
+	err := c.do(ctx, http.MethodPost, "/api/restart", RunRequest{ProjectPath: path}, &out)
+	return out, err
+}
+
+// Logs returns the last lines of output for the project at path.
+func (c *Client) Logs(ctx context.Context, path string, lines int) (LogsResponse, error) {
+	return c.logs(ctx, path, "", -1, lines)
+}
+
+// SidecarLogs returns the last lines of output of the sidecar name of the
+// project at path.
+func (c *Client) SidecarLogs(ctx context.Context, path, name string, lines int) (LogsResponse, error) {
+	return c.logs(ctx, path, name, -1, lines)
+}
+
+// FollowLogs calls fn for the last lines of output of the project at path and
+// then for every new line, polling every interval until ctx is canceled.
+func (c *Client) FollowLogs(ctx context.Context, path string, lines int, interval time.Duration, fn func(line string)) error {
+	return c.followLogs(ctx, path, "", lines, interval, fn)
+}
+
+// FollowSidecarLogs is FollowLogs for the sidecar name of the project at
+// path.
+func (c *Client) FollowSidecarLogs(ctx context.Context, path, name string, lines int, interval time.Duration, fn func(line string)) error {
+	return c.followLogs(ctx, path, name, lines, interval, fn)
+}
+
+func (c *Client) followLogs(ctx context.Context, path, sidecar string, lines int, interval time.Duration, fn func(line string)) error {
+	since := int64(-1)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		resp, err := c.logs(ctx, path, sidecar, since, lines)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		for _, line := range resp.Lines {
+			fn(line)
+		}
+		since = resp.Next
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func (c *Client) logs(ctx context.Context, path, sidecar string, since int64, lines int) (LogsResponse, error) {
+	query := url.Values{"project_path": {path}}
+	if sidecar != "" {
+		query.Set("sidecar", sidecar)
+	}
+	if since >= 0 {
+		query.Set("since", strconv.FormatInt(since, 10))
+	}
+	if lines > 0 {
+		query.Set("lines", strconv.Itoa(lines))
+	}
+	var out LogsResponse
+	err := c.do(ctx, http.MethodGet, "/api/logs?"+query.Encode(), nil, &out)
+	return out, err
+}
+
+// StartAll starts every saved project that is not running.
+func (c *Client) StartAll(ctx context.Context) (BulkResponse, error) {
+	var out BulkResponse
+	err := c.do(ctx, http.MethodPost, "/api/start-all", nil, &out)
+	return out, err
+}
+
+// StopAll stops every running project.
+func (c *Client) StopAll(ctx context.Context) (BulkResponse, error) {
+	var out BulkResponse
+	err := c.do(ctx, http.MethodPost, "/api/stop-all", nil, &out)
+	return out, err
+}
+
+// ListProjects returns the saved projects, pinned first.
+func (c *Client) ListProjects(ctx context.Context) ([]Project, error) {
+	var out ProjectList
+	err := c.do(ctx, http.MethodGet, "/api/projects", nil, &out)
+	return out.Projects, err
+}
+
+// GetProject returns the saved project with the given ID.
+func (c *Client) GetProject(ctx context.Context, id string) (Project, error) {
+	var out Project
+	err := c.do(ctx, http.MethodGet, "/api/projects/"+url.PathEscape(id), nil, &out)
+	return out, err
+}
+
+// ProjectPHPIni returns the php.ini values Frago applies to a saved project.
+func (c *Client) ProjectPHPIni(ctx context.Context, id string) (PHPIniResponse, error) {
+	var out PHPIniResponse
+	err := c.do(ctx, http.MethodGet, "/api/projects/"+url.PathEscape(id)+"/php-ini", nil, &out)
+	return out, err
+}
+
+// ProjectDebug returns the Xdebug settings of a saved project.
+func (c *Client) ProjectDebug(ctx context.Context, id string) (DebugResponse, error) {
+	var out DebugResponse
+	err := c.do(ctx, http.MethodGet, "/api/projects/"+url.PathEscape(id)+"/debug", nil, &out)
+	return out, err
+}
+
+// SetProjectDebug changes the Xdebug settings of a saved project, restarting
+// it when it is running.
+func (c *Client) SetProjectDebug(ctx context.Context, id string, req DebugRequest) (DebugResponse, error) {
+	var out DebugResponse
+	err := c.do(ctx, http.MethodPost, "/api/projects/"+url.PathEscape(id)+"/debug", req, &out)
+	return out, err
+}
+
+// Sidecars lists the sidecars of a saved project.
+func (c *Client) Sidecars(ctx context.Context, id string) (SidecarsResponse, error) {
+	var out SidecarsResponse
+	err := c.do(ctx, http.MethodGet, "/api/projects/"+url.PathEscape(id)+"/sidecars", nil, &out)
+	return out, err
+}
+
+// StartSidecar starts a sidecar of a saved project.
+func (c *Client) StartSidecar(ctx context.Context, id, name string) (SidecarStatus, error) {
+	return c.sidecarAction(ctx, id, name, "start")
+}
+
+// StopSidecar asks a running sidecar of a saved project to exit.
+func (c *Client) StopSidecar(ctx context.Context, id, name string) (SidecarStatus, error) {
+	return c.sidecarAction(ctx, id, name, "stop")
+}
+
+// RestartSidecar stops a sidecar of a saved project if it is running and
+// starts it again.
+func (c *Client) RestartSidecar(ctx context.Context, id, name string) (SidecarStatus, error) {
+	return c.sidecarAction(ctx, id, name, "restart")
+}
+
+func (c *Client) sidecarAction(ctx context.Context, id, name, action string) (SidecarStatus, error) {
+	var out SidecarStatus
+	err := c.do(ctx, http.MethodPost, "/api/projects/"+url.PathEscape(id)+"/sidecars/"+url.PathEscape(name)+"/"+action, nil, &out)
+	return out, err
+}
+
+// CreateProject saves a project directory. req.Path must be absolute.
+func (c *Client) CreateProject(ctx context.Context, req ProjectRequest) (Project, error) {
+	var out Project
+	err := c.do(ctx, http.MethodPost, "/api/projects", req, &out)
+	return out, err
+}
+
+// UpdateProject changes the fields set in req on the project with the given ID.
+func (c *Client) UpdateProject(ctx context.Context, id string, req ProjectRequest) (Project, error) {
+	var out Project
+	err := c.do(ctx, http.MethodPatch, "/api/projects/"+url.PathEscape(id), req, &out)
+	return out, err
+}
+
+// DeleteProject removes a stopped project.
+func (c *Client) DeleteProject(ctx context.Context, id string) error {
+	return c.do(ctx, http.MethodDelete, "/api/projects/"+url.PathEscape(id), nil, nil)
+}
+
+// ListWorkspaces returns the workspaces in creation order.
+func (c *Client) ListWorkspaces(ctx context.Context) ([]Workspace, error) {
+	var out WorkspaceList
+	err := c.do(ctx, http.MethodGet, "/api/workspaces", nil, &out)
+	return out.Workspaces, err
+}
+
+// GetWorkspace returns the workspace with the given ID.
+func (c *Client) GetWorkspace(ctx context.Context, id string) (Workspace, error) {
+	var out Workspace
+	err := c.do(ctx, http.MethodGet, "/api/workspaces/"+url.PathEscape(id), nil, &out)
+	return out, err
+}
+
+// CreateWorkspace adds a workspace. req.Name is required.
+func (c *Client) CreateWorkspace(ctx context.Context, req WorkspaceRequest) (Workspace, error) {
+	var out Workspace
+	err := c.do(ctx, http.MethodPost, "/api/workspaces", req, &out)
+	return out, err
+}
+
+// UpdateWorkspace changes the fields set in req on the workspace with the given ID.
+func (c *Client) UpdateWorkspace(ctx context.Context, id string, req WorkspaceRequest) (Workspace, error) {
+	var out Workspace
+	err := c.do(ctx, http.MethodPatch, "/api/workspaces/"+url.PathEscape(id), req, &out)
+	return out, err
+}
+
+// DeleteWorkspace removes a workspace. Its projects stay saved.
+func (c *Client) DeleteWorkspace(ctx context.Context, id string) error {
+	return c.do(ctx, http.MethodDelete, "/api/workspaces/"+url.PathEscape(id), nil, nil)
+}
+
+// StartWorkspace starts the projects of a workspace in start order.
+func (c *Client) StartWorkspace(ctx context.Context, id string) (BulkResponse, error) {
+	var out BulkResponse
+	err := c.do(ctx, http.MethodPost, "/api/workspaces/"+url.PathEscape(id)+"/start", nil, &out)
+	return out, err
+}
+
+// StopWorkspace stops the projects of a workspace in reverse start order.
+func (c *Client) StopWorkspace(ctx context.Context, id string) (BulkResponse, error) {
+	var out BulkResponse
+	err := c.do(ctx, http.MethodPost, "/api/workspaces/"+url.PathEscape(id)+"/stop", nil, &out)
+	return out, err
+}
+
+func (c *Client) do(ctx context.Context, method, path string, body any, out any) error {
+	resp, err := c.send(ctx, method, path, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if out == nil {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decode %s %s: %w", method, path, err)
+	}
+	return nil
+}
+
+// send performs a request and returns the response when it succeeded. Non-2xx
+// responses are closed and turned into *Error.
+func (c *Client) send(ctx context.Context, method, path string, body any) (*http.Response, error) {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+
+	apiErr := &Error{StatusCode: resp.StatusCode}
+	var payload ErrorResponse
+	if data, err := io.ReadAll(io.LimitReader(resp.Body, 64<<10)); err == nil {
+		if json.Unmarshal(data, &payload) == nil && payload.Error != "" {
+			apiErr.Message = payload.Error
+		} else {
+			apiErr.Message = strings.TrimSpace(string(data))
+		}
+	}
+	return nil, apiErr
+}
@@ -0,0 +1,124 @@
+package fragoclient_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/devmarvs/frago/internal/runner"
+	"github.com/devmarvs/frago/internal/server"
+	"github.com/devmarvs/frago/internal/service"
+	"github.com/devmarvs/frago/internal/store"
+	"github.com/devmarvs/frago/pkg/fragoclient"
+)
+
+type memoryBackend struct {
+	data []byte
+}
+
+func (b *memoryBackend) Load() ([]byte, error) { return b.data, nil }
+
+func (b *memoryBackend) Save(data []byte) error {
+	b.data = append([]byte(nil), data...)
+	return nil
+}
+
+func newServer(t *testing.T, token string) *httptest.Server {
+	t.Helper()
+	svc := service.New(runner.NewManager(), store.New(&memoryBackend{}), nil)
+	ts := httptest.NewServer(server.New(svc, server.Config{Token: token}))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func TestClientProjects(t *testing.T) {
+	ts := newServer(t, "secret")
+	client := fragoclient.New(ts.URL, "secret")
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	port := 8123
+	created, err := client.CreateProject(ctx, fragoclient.ProjectRequest{Path: dir, PreferredPort: &port})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if created.Path != dir || created.PreferredPort != 8123 || created.Running {
+		t.Fatalf("unexpected project: %+v", created)
+	}
+
+	pinned := true
+	updated, err := client.UpdateProject(ctx, created.ID, fragoclient.ProjectRequest{Pinned: &pinned})
+	if err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if !updated.Pinned || updated.PreferredPort != 8123 {
+		t.Fatalf("unexpected update: %+v", updated)
+	}
+
+	list, err := client.ListProjects(ctx)
+	if err != nil || len(list) != 1 {
+		t.Fatalf("list: %v, %+v", err, list)
+	}
+
+	status, err := client.Status(ctx)
+	if err != nil {
+		t.Fatalf("status: %v", err)
+	}
+	if status.SchemaVersion != fragoclient.StatusSchemaVersion || len(status.Stopped) != 1 {
+		t.Fatalf("unexpected status: %+v", status)
+	}
+
+	if err := client.DeleteProject(ctx, created.ID); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if _, err := client.GetProject(ctx, created.ID); !fragoclient.IsNotFound(err) {
+		t.Fatalf("expected not found after delete, got %v", err)
+	}
+}
+
+func TestClientReportsAPIErrors(t *testing.T) {
+	ts := newServer(t, "secret")
+	ctx := context.Background()
+
+	_, err := fragoclient.New(ts.URL, "wrong").Status(ctx)
+	var apiErr *fragoclient.Error
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected 401 error, got %v", err)
+	}
+	if apiErr.Message != "missing or invalid API token" {
+		t.Fatalf("unexpected message %q", apiErr.Message)
+	}
+
+	_, err = fragoclient.New(ts.URL, "secret").Run(ctx, fragoclient.RunRequest{})
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400 error, got %v", err)
+	}
+}
+
+func TestClientWaitsForSlowStarts(t *testing.T) {
+	// The handler stands in for a start-all waiting on a slow dependency.
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(500 * time.Millisecond):
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"results":[{"path":"/srv/api","ok":true,"status":"started"}]}`))
+		case <-r.Context().Done():
+		}
+	}))
+	t.Cleanup(ts.Close)
+	client := fragoclient.New(ts.URL, "")
+
+	resp, err := client.StartAll(context.Background())
+	if err != nil || len(resp.Results) != 1 || !resp.Results[0].OK {
+		t.Fatalf("expected the start to be waited for, got %+v (%v)", resp, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := client.StartAll(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the request context to bound the call, got %v", err)
+	}
+}
@@ -0,0 +1,280 @@
+package fragoclient
+
+import "time"
+
+// StatusSchemaVersion is bumped whenever a field of StatusResponse is removed
+// or changes meaning. Adding fields does not change the version.
+const StatusSchemaVersion = 2
+
+// Project states reported in ProjectStatus.State.
+const (
+	StateRunning = "running"
+	StateStopped = "stopped"
+	StateFailed  = "failed"
+)
+
+// Result statuses reported in Result.Status.
+const (
+	ResultStarted = "started"
+	ResultStopped = "stopped"
+	ResultSkipped = "skipped"
+	ResultError   = "error"
+)
+
+// ErrorResponse is the body of every non-2xx API response.
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+// HealthResponse is returned by /health.
+type HealthResponse struct {
+	Status string `json:"status"`
+}
+
+// RunRequest identifies a project directory to run, stop or restart.
+// BinaryPath and Port are only used by run.
+type RunRequest struct {
+	ProjectPath string `json:"project_path"`
+	BinaryPath  string `json:"binary_path,omitempty"`
+	Port        int    `json:"port,omitempty"`
+}
+
+// RunResponse reports where a started project is listening.
+type RunResponse struct {
+	Status string `json:"status"`
+	URL    string `json:"url,omitempty"`
+	Port   int    `json:"port,omitempty"`
+}
+
+// StopResponse confirms a project was asked to stop.
+type StopResponse struct {
+	Status      string `json:"status"`
+	ProjectPath string `json:"project_path"`
+}
+
+// LogsResponse holds log lines for a project and the cursor to pass as since
+// to fetch only newer lines.
+type LogsResponse struct {
+	ProjectPath string   `json:"project_path"`
+	Lines       []string `json:"lines"`
+	Next        int64    `json:"next"`
+	Running     bool     `json:"running"`
+	// Sidecar is set when the lines are the output of a sidecar.
+	Sidecar string `json:"sidecar,omitempty"`
+}
+
+// Result is the outcome of a bulk operation for a single project.
+type Result struct {
+	ID     string `json:"id"`
+	Path   string `json:"path"`
+	OK     bool   `json:"ok"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// BulkResponse reports per-project results of start-all and stop-all.
+type BulkResponse struct {
+	Results []Result `json:"results"`
+	OK      int      `json:"ok"`
+	Failed  int      `json:"failed"`
+}
+
+// Project is a saved project along with whether it is running.
+type Project struct {
+	ID               string    `json:"id"`
+	Path             string    `json:"path"`
+	PreferredPort    int       `json:"preferred_port,omitempty"`
+	LastPort         int       `json:"last_port,omitempty"`
+	LastURL          string    `json:"last_url,omitempty"`
+	LastVersionLabel string    `json:"last_version_label,omitempty"`
+	LastBinaryPath   string    `json:"last_binary_path,omitempty"`
+	Pinned           bool      `json:"pinned"`
+	AutoStart        bool      `json:"auto_start"`
+	LastUsed         time.Time `json:"last_used,omitzero"`
+	DependsOn        []string  `json:"depends_on,omitempty"`
+	Name             string    `json:"name,omitempty"`
+	Tags             []string  `json:"tags,omitempty"`
+	// PHP is the saved PHP version constraint, such as "^8.3".
+	PHP string `json:"php,omitempty"`
+	// PHPIniPreset and PHPIni are the saved php.ini preset and overrides.
+	PHPIniPreset string            `json:"php_ini_preset,omitempty"`
+	PHPIni       map[string]string `json:"php_ini,omitempty"`
+	// Debug reports whether the Xdebug toggle is on.
+	Debug   bool `json:"debug"`
+	Running bool `json:"running"`
+}
+
+// ProjectRequest creates or updates a saved project. On update, omitted
+// fields are left unchanged and Path is ignored.
+type ProjectRequest struct {
+	Path          string  `json:"path,omitempty"`
+	PreferredPort *int    `json:"preferred_port,omitempty"`
+	BinaryPath    *string `json:"binary_path,omitempty"`
+	Pinned        *bool   `json:"pinned,omitempty"`
+	AutoStart     *bool   `json:"auto_start,omitempty"`
+	// DependsOn replaces the saved dependencies; entries are saved project
+	// paths or IDs.
+	DependsOn *[]string `json:"depends_on,omitempty"`
+	// Name is the display name; an empty string clears it.
+	Name *string `json:"name,omitempty"`
+	// Tags replaces the saved tags.
+	Tags *[]string `json:"tags,omitempty"`
+	// PHP is a version constraint such as "^8.3" that picks the binary; an
+	// empty string falls back to the manifest or composer.json.
+	PHP *string `json:"php,omitempty"`
+	// PHPIniPreset is "development", "production" or "" to use the
+	// manifest's.
+	PHPIniPreset *string `json:"php_ini_preset,omitempty"`
+	// PHPIni replaces the saved php.ini overrides.
+	PHPIni *map[string]string `json:"php_ini,omitempty"`
+}
+
+// DebugRequest changes the Xdebug settings of a project. Omitted fields are
+// left unchanged.
+type DebugRequest struct {
+	Enabled *bool `json:"enabled,omitempty"`
+	// ClientHost and ClientPort locate the IDE; "" and 0 restore Xdebug's
+	// defaults of localhost and 9003.
+	ClientHost *string `json:"client_host,omitempty"`
+	ClientPort *int    `json:"client_port,omitempty"`
+	// Trigger debugs only requests carrying XDEBUG_TRIGGER or XDEBUG_SESSION
+	// instead of every request.
+	Trigger *bool `json:"trigger,omitempty"`
+}
+
+// DebugResponse reports the Xdebug settings of a project.
+type DebugResponse struct {
+	ID         string `json:"id"`
+	Enabled    bool   `json:"enabled"`
+	ClientHost string `json:"client_host,omitempty"`
+	ClientPort int    `json:"client_port,omitempty"`
+	Trigger    bool   `json:"trigger"`
+	// Summary describes the settings, such as "On (trigger, localhost:9003)".
+	Summary string `json:"summary"`
+	// Restarted is true when the project was running and restarted to apply
+	// a change.
+	Restarted bool `json:"restarted,omitempty"`
+}
+
+// PHPIniSetting is one php.ini value Frago applies to a project.
+type PHPIniSetting struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+	// Source is the preset, "manifest" or "project settings".
+	Source string `json:"source"`
+}
+
+// PHPIniResponse lists the php.ini values Frago applies to a project.
+type PHPIniResponse struct {
+	ID       string          `json:"id"`
+	Settings []PHPIniSetting `json:"settings"`
+}
+
+// ProjectList is returned when listing saved projects.
+type ProjectList struct {
+	Projects []Project `json:"projects"`
+	Count    int       `json:"count"`
+}
+
+// DeleteResponse confirms a project was removed.
+type DeleteResponse struct {
+	Status string `json:"status"`
+	ID     string `json:"id"`
+}
+
+// Workspace is a named group of saved projects. Projects lists project paths
+// in start order.
+type Workspace struct {
+	ID       string   `json:"id"`
+	Name     string   `json:"name"`
+	Projects []string `json:"projects"`
+}
+
+// WorkspaceRequest creates or updates a workspace. Projects accepts saved
+// project paths or IDs. On update, omitted fields are left unchanged.
+type WorkspaceRequest struct {
+	Name     *string   `json:"name,omitempty"`
+	Projects *[]string `json:"projects,omitempty"`
+}
+
+// WorkspaceList is returned when listing workspaces.
+type WorkspaceList struct {
+	Workspaces []Workspace `json:"workspaces"`
+	Count      int         `json:"count"`
+}
+
+// StatusResponse is the /api/status payload.
+type StatusResponse struct {
+	SchemaVersion int `json:"schema_version"`
+	// Processes lists running projects. Count is len(Processes).
+	Processes []ProjectStatus `json:"processes"`
+	Count     int             `json:"count"`
+	// Stopped lists saved projects that are not running, with their last exit.
+	Stopped []ProjectStatus `json:"stopped"`
+}
+
+// ProjectStatus describes one running or saved project.
+type ProjectStatus struct {
+	ID            string      `json:"id"`
+	ProjectPath   string      `json:"project_path"`
+	State         string      `json:"state"`
+	URL           string      `json:"url,omitempty"`
+	Port          int         `json:"port,omitempty"`
+	PID           int         `json:"pid,omitempty"`
+	BinaryPath    string      `json:"binary_path,omitempty"`
+	VersionLabel  string      `json:"version_label,omitempty"`
+	StartedAt     time.Time   `json:"started_at,omitzero"`
+	UptimeSeconds int64       `json:"uptime_seconds,omitempty"`
+	Health        *Health     `json:"health,omitempty"`
+	Stats         *Stats      `json:"stats,omitempty"`
+	LastExit      *ExitStatus `json:"last_exit,omitempty"`
+	// Sidecars lists the sidecars declared in the project manifest, and any
+	// still running after being removed from it.
+	Sidecars []SidecarStatus `json:"sidecars,omitempty"`
+}
+
+// SidecarStatus describes a companion process of a project, such as a queue
+// worker. State is StateRunning, StateStopped or StateFailed.
+type SidecarStatus struct {
+	Name          string      `json:"name"`
+	Command       []string    `json:"command"`
+	Restart       string      `json:"restart,omitempty"`
+	AutoStart     bool        `json:"auto_start"`
+	State         string      `json:"state"`
+	PID           int         `json:"pid,omitempty"`
+	StartedAt     time.Time   `json:"started_at,omitzero"`
+	UptimeSeconds int64       `json:"uptime_seconds,omitempty"`
+	Stats         *Stats      `json:"stats,omitempty"`
+	LastExit      *ExitStatus `json:"last_exit,omitempty"`
+}
+
+// SidecarsResponse lists the sidecars of a project.
+type SidecarsResponse struct {
+	ID          string          `json:"id"`
+	ProjectPath string          `json:"project_path"`
+	Sidecars    []SidecarStatus `json:"sidecars"`
+}
+
+// Health is the result of the last HTTP health check for a running project.
+type Health struct {
+	Healthy   bool      `json:"healthy"`
+	CheckedAt time.Time `json:"checked_at"`
+	LastError string    `json:"last_error,omitempty"`
+}
+
+// Stats is the last CPU and memory sample for a running project.
+// CPUPercent is negative when the platform could not report it.
+type Stats struct {
+	CPUPercent float64   `json:"cpu_percent"`
+	RSSBytes   int64     `json:"rss_bytes"`
+	CheckedAt  time.Time `json:"checked_at"`
+	LastError  string    `json:"last_error,omitempty"`
+}
+
+// ExitStatus records how a project's last process ended. Failed is false when
+// the process was stopped on request.
+type ExitStatus struct {
+	At     time.Time `json:"at"`
+	Error  string    `json:"error,omitempty"`
+	Failed bool      `json:"failed"`
+}